@@ -1,17 +1,39 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for our application
 type Config struct {
+	App      AppConfig
 	Database DatabaseConfig
 	Server   ServerConfig
 	JWT      JWTConfig
+	Auth     AuthConfig
+	Password PasswordConfig
+	Mail     MailConfig
+	Social   SocialConfig
+	OAuth    OAuthConfig
+	Cookie   CookieConfig
+	CORS     CORSConfig
+	Tenant   TenantConfig
+	SCIM     SCIMConfig
+	LDAP     LDAPConfig
+}
+
+// AppConfig holds general application configuration
+type AppConfig struct {
+	Name string
+	URL  string
+	Key  string
 }
 
 // DatabaseConfig holds database configuration
@@ -31,7 +53,160 @@ type ServerConfig struct {
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret string
+	Secret               string
+	Algorithm            string
+	SigningKeyFile       string
+	SigningKeyID         string
+	VerificationKeyFiles []string
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+}
+
+// AuthConfig holds account security configuration
+type AuthConfig struct {
+	PasswordResetTTL                time.Duration
+	RequireEmailVerification        bool
+	EmailVerificationTTL            time.Duration
+	EmailVerificationResendInterval time.Duration
+	TwoFactorChallengeTTL           time.Duration
+	MagicLinkEnabled                bool
+	MagicLinkTTL                    time.Duration
+	ImpersonationTTL                time.Duration
+	InvitationTTL                   time.Duration
+
+	// PolicyFile is the JSON authorization policy for user updates and
+	// deletes. When empty, the built-in default policy is used.
+	PolicyFile string
+
+	// Login history. A retention of 0 keeps every attempt.
+	NewDeviceNotification bool
+	LoginHistoryRetention time.Duration
+
+	// Account lockout. A threshold of 0 disables it. Each failure past the
+	// threshold doubles the lockout, up to LockoutMaxDuration.
+	LockoutThreshold   int
+	LockoutDuration    time.Duration
+	LockoutMaxDuration time.Duration
+}
+
+// PasswordConfig holds password hashing and policy configuration. Algorithm
+// is "argon2id" or "bcrypt"; Argon2Memory is in KiB.
+type PasswordConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+
+	// Password policy. A MaxAge or HistorySize of 0 disables the rule.
+	// BreachedListDir holds SHA-1 range files named by their 5 character hash
+	// prefix (e.g. 5BAA6.txt) with one "SUFFIX:COUNT" line per password.
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	MaxAge           time.Duration
+	HistorySize      int
+	BreachedListDir  string
+}
+
+// MailConfig holds mailer configuration
+type MailConfig struct {
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	FileDir  string
+}
+
+// SocialConfig holds social login configuration
+type SocialConfig struct {
+	StateTTL  time.Duration
+	Providers []SocialProviderConfig
+}
+
+// SocialProviderConfig holds the settings of one social login provider.
+// Type is "oidc" for OpenID Connect providers (discovered from Issuer) or "github".
+type SocialProviderConfig struct {
+	Name         string
+	Type         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	APIURL       string
+}
+
+// OAuthConfig holds the built-in OAuth2 authorization server configuration.
+// AuthorizeURL is the page that signs the user in and asks for consent before
+// calling the authorize API; it is advertised as the authorization endpoint.
+type OAuthConfig struct {
+	Issuer               string
+	AuthorizeURL         string
+	AuthorizationCodeTTL time.Duration
+	RefreshTokenTTL      time.Duration
+}
+
+// CookieConfig holds cookie-based authentication settings for browser apps.
+// SameSite is "lax", "strict" or "none"; "none" requires Secure.
+type CookieConfig struct {
+	Enabled     bool
+	AccessName  string
+	RefreshName string
+	CSRFName    string
+	CSRFHeader  string
+	Domain      string
+	Secure      bool
+	SameSite    string
+}
+
+// CORSConfig holds cross-origin settings. AllowedOrigins are echoed back with
+// credentials allowed, which cookie mode needs; without any, no CORS headers are
+// sent and browsers only allow same-origin calls.
+type CORSConfig struct {
+	AllowedOrigins []string
+}
+
+// TenantConfig holds multi-tenant settings. Requests select an organization with
+// the Header (its ID or slug), a subdomain of BaseDomain or the org claim of an
+// impersonation token. When Required is set, tenant-scoped routes refuse requests
+// that select none; otherwise those requests are scoped to the user's only
+// organization. They are never left unscoped.
+type TenantConfig struct {
+	Header     string
+	BaseDomain string
+	Required   bool
+}
+
+// SCIMConfig holds SCIM provisioning settings. Identity providers authenticate
+// with Token as a bearer token and provision the members of the organization
+// OrganizationID; SCIM is disabled while either is unset.
+type SCIMConfig struct {
+	Token          string
+	OrganizationID uint
+}
+
+// LDAPConfig holds LDAP / Active Directory authentication settings. Logins are
+// checked against the directory after local accounts; LDAP is disabled while URL is empty.
+// UserFilter finds the user's entry, with %s replaced by the escaped login email.
+type LDAPConfig struct {
+	URL                string
+	BindDN             string
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string
+	EmailAttribute     string
+	NameAttribute      string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	Timeout            time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
@@ -41,7 +216,14 @@ func LoadConfig() *Config {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	appURL := strings.TrimRight(getEnv("APP_URL", "http://localhost:8080"), "/")
+
 	return &Config{
+		App: AppConfig{
+			Name: getEnv("APP_NAME", "Golang Starter Kit"),
+			URL:  appURL,
+			Key:  getEnv("APP_KEY", "your_super_secret_app_key"),
+		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "5432"),
@@ -54,9 +236,133 @@ func LoadConfig() *Config {
 			Port: getEnv("PORT", "8080"),
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "your_super_secret_jwt_key"),
+			Secret:               getEnv("JWT_SECRET", "your_super_secret_jwt_key"),
+			Algorithm:            getEnv("JWT_ALGORITHM", "HS256"),
+			SigningKeyFile:       getEnv("JWT_SIGNING_KEY_FILE", ""),
+			SigningKeyID:         getEnv("JWT_SIGNING_KEY_ID", ""),
+			VerificationKeyFiles: getEnvList("JWT_VERIFICATION_KEY_FILES"),
+			AccessTokenTTL:       getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTokenTTL:      getEnvDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+		},
+		Auth: AuthConfig{
+			PasswordResetTTL:                getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+			RequireEmailVerification:        getEnvBool("REQUIRE_EMAIL_VERIFICATION", false),
+			EmailVerificationTTL:            getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			EmailVerificationResendInterval: getEnvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+			TwoFactorChallengeTTL:           getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+			MagicLinkEnabled:                getEnvBool("MAGIC_LINK_ENABLED", false),
+			MagicLinkTTL:                    getEnvDuration("MAGIC_LINK_TTL", 15*time.Minute),
+			ImpersonationTTL:                getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+			InvitationTTL:                   getEnvDuration("INVITATION_TTL", 7*24*time.Hour),
+			PolicyFile:                      getEnv("POLICY_FILE", ""),
+			NewDeviceNotification:           getEnvBool("NEW_DEVICE_NOTIFICATION", true),
+			LoginHistoryRetention:           getEnvDuration("LOGIN_HISTORY_RETENTION", 90*24*time.Hour),
+			LockoutThreshold:                getEnvInt("LOCKOUT_THRESHOLD", 5),
+			LockoutDuration:                 getEnvDuration("LOCKOUT_DURATION", 15*time.Minute),
+			LockoutMaxDuration:              getEnvDuration("LOCKOUT_MAX_DURATION", 24*time.Hour),
+		},
+		Password: PasswordConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:        getEnvInt("BCRYPT_COST", 12),
+			Argon2Memory:      getEnvInt("ARGON2_MEMORY", 64*1024),
+			Argon2Iterations:  getEnvInt("ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvInt("ARGON2_PARALLELISM", 2),
+			MinLength:         getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:         getEnvInt("PASSWORD_MAX_LENGTH", 72),
+			RequireUppercase:  getEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase:  getEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:      getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:     getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			MaxAge:            getEnvDuration("PASSWORD_MAX_AGE", 0),
+			HistorySize:       getEnvInt("PASSWORD_HISTORY_SIZE", 5),
+			BreachedListDir:   getEnv("PASSWORD_BREACHED_LIST_DIR", ""),
+		},
+		Mail: MailConfig{
+			Driver:   getEnv("MAIL_DRIVER", "log"),
+			Host:     getEnv("MAIL_HOST", "localhost"),
+			Port:     getEnv("MAIL_PORT", "25"),
+			Username: getEnv("MAIL_USERNAME", ""),
+			Password: getEnv("MAIL_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "no-reply@example.com"),
+			FileDir:  getEnv("MAIL_FILE_DIR", "storage/mail"),
+		},
+		Social: SocialConfig{
+			StateTTL:  getEnvDuration("SOCIAL_LOGIN_STATE_TTL", 10*time.Minute),
+			Providers: loadSocialProviders(appURL),
+		},
+		OAuth: OAuthConfig{
+			Issuer:               getEnv("OAUTH_ISSUER", appURL),
+			AuthorizeURL:         getEnv("OAUTH_AUTHORIZE_URL", appURL+"/api/v1/oauth/authorize"),
+			AuthorizationCodeTTL: getEnvDuration("OAUTH_CODE_TTL", 5*time.Minute),
+			RefreshTokenTTL:      getEnvDuration("OAUTH_REFRESH_TTL", 30*24*time.Hour),
+		},
+		Cookie: CookieConfig{
+			Enabled:     getEnvBool("AUTH_COOKIE_ENABLED", false),
+			AccessName:  getEnv("AUTH_COOKIE_NAME", "access_token"),
+			RefreshName: getEnv("AUTH_REFRESH_COOKIE_NAME", "refresh_token"),
+			CSRFName:    getEnv("CSRF_COOKIE_NAME", "csrf_token"),
+			CSRFHeader:  getEnv("CSRF_HEADER_NAME", "X-CSRF-Token"),
+			Domain:      getEnv("AUTH_COOKIE_DOMAIN", ""),
+			Secure:      getEnvBool("AUTH_COOKIE_SECURE", true),
+			SameSite:    getEnv("AUTH_COOKIE_SAMESITE", "lax"),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS"),
 		},
+		Tenant: TenantConfig{
+			Header:     getEnv("TENANT_HEADER", "X-Organization"),
+			BaseDomain: getEnv("TENANT_BASE_DOMAIN", ""),
+			Required:   getEnvBool("TENANT_REQUIRED", true),
+		},
+		SCIM: SCIMConfig{
+			Token:          getEnv("SCIM_TOKEN", ""),
+			OrganizationID: uint(max(getEnvInt("SCIM_ORGANIZATION_ID", 0), 0)),
+		},
+		LDAP: LDAPConfig{
+			URL:                getEnv("LDAP_URL", ""),
+			BindDN:             getEnv("LDAP_BIND_DN", ""),
+			BindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+			BaseDN:             getEnv("LDAP_BASE_DN", ""),
+			UserFilter:         getEnv("LDAP_USER_FILTER", "(&(objectClass=person)(mail=%s))"),
+			EmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+			NameAttribute:      getEnv("LDAP_NAME_ATTRIBUTE", "cn"),
+			StartTLS:           getEnvBool("LDAP_START_TLS", false),
+			InsecureSkipVerify: getEnvBool("LDAP_INSECURE_SKIP_VERIFY", false),
+			Timeout:            getEnvDuration("LDAP_TIMEOUT", 10*time.Second),
+		},
+	}
+}
+
+// loadSocialProviders reads the providers listed in SOCIAL_LOGIN_PROVIDERS.
+// Each provider NAME is configured with SOCIAL_<NAME>_* variables.
+func loadSocialProviders(appURL string) []SocialProviderConfig {
+	var providers []SocialProviderConfig
+	for _, name := range getEnvList("SOCIAL_LOGIN_PROVIDERS") {
+		name = strings.ToLower(name)
+		prefix := "SOCIAL_" + strings.ToUpper(name) + "_"
+
+		defaultType, defaultIssuer := "oidc", ""
+		switch name {
+		case "github":
+			defaultType = "github"
+		case "google":
+			defaultIssuer = "https://accounts.google.com"
+		}
+
+		providers = append(providers, SocialProviderConfig{
+			Name:         name,
+			Type:         getEnv(prefix+"TYPE", defaultType),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", fmt.Sprintf("%s/api/v1/auth/oauth/%s/callback", appURL, name)),
+			Scopes:       getEnvList(prefix + "SCOPES"),
+			Issuer:       getEnv(prefix+"ISSUER", defaultIssuer),
+			AuthURL:      getEnv(prefix+"AUTH_URL", ""),
+			TokenURL:     getEnv(prefix+"TOKEN_URL", ""),
+			APIURL:       getEnv(prefix+"API_URL", ""),
+		})
 	}
+	return providers
 }
 
 // getEnv gets environment variable with fallback
@@ -66,3 +372,56 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvDuration gets environment variable parsed as a time.Duration with fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s: %q, using default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getEnvInt gets environment variable parsed as an int with fallback
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid int for %s: %q, using default %d", key, value, fallback)
+		return fallback
+	}
+	return i
+}
+
+// getEnvBool gets environment variable parsed as a bool with fallback
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid bool for %s: %q, using default %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvList gets environment variable split on commas, ignoring empty items
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
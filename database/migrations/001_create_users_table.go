@@ -16,13 +16,3 @@ type Users struct {
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
-
-// MigrateUp runs the migration for users table
-func MigrateUp(db *gorm.DB) error {
-	return db.AutoMigrate(&Users{})
-}
-
-// MigrateDown drops the users table
-func MigrateDown(db *gorm.DB) error {
-	return db.Migrator().DropTable(&Users{})
-}
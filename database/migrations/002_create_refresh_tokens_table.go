@@ -0,0 +1,15 @@
+package migrations
+
+import "time"
+
+// RefreshTokens migration - GORM will use this struct shape only for migration
+type RefreshTokens struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	FamilyID  string    `gorm:"index;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,13 @@
+package migrations
+
+import "time"
+
+// RevokedTokens migration - GORM will use this struct shape only for migration
+type RevokedTokens struct {
+	ID           uint   `gorm:"primaryKey"`
+	JTI          string `gorm:"column:jti;index"`
+	UserID       uint   `gorm:"index;not null"`
+	IssuedBefore *time.Time
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
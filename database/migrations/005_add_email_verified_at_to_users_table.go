@@ -0,0 +1,13 @@
+package migrations
+
+import "time"
+
+// UsersEmailVerification migration adds the email_verified_at column to the users table
+type UsersEmailVerification struct {
+	EmailVerifiedAt *time.Time
+}
+
+// TableName points the migration at the existing users table
+func (UsersEmailVerification) TableName() string {
+	return "users"
+}
@@ -0,0 +1,13 @@
+package migrations
+
+import "time"
+
+// EmailVerificationTokens migration - GORM will use this struct shape only for migration
+type EmailVerificationTokens struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
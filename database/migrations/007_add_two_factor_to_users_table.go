@@ -0,0 +1,15 @@
+package migrations
+
+import "time"
+
+// UsersTwoFactor migration adds the two-factor authentication columns to the users table
+type UsersTwoFactor struct {
+	TwoFactorSecret    string
+	TwoFactorEnabledAt *time.Time
+	TwoFactorLastStep  int64 `gorm:"not null;default:0"`
+}
+
+// TableName points the migration at the existing users table
+func (UsersTwoFactor) TableName() string {
+	return "users"
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import "time"
+
+// TwoFactorRecoveryCodes migration - GORM will use this struct shape only for migration
+type TwoFactorRecoveryCodes struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"uniqueIndex;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TwoFactorChallenges migration - GORM will use this struct shape only for migration
+type TwoFactorChallenges struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
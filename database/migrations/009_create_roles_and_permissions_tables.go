@@ -0,0 +1,33 @@
+package migrations
+
+import "time"
+
+// Roles migration - GORM will use this struct shape only for migration
+type Roles struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"uniqueIndex;not null"`
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Permissions migration - GORM will use this struct shape only for migration
+type Permissions struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"uniqueIndex;not null"`
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RolePermissions migration - join table between roles and permissions
+type RolePermissions struct {
+	RoleID       uint `gorm:"primaryKey"`
+	PermissionID uint `gorm:"primaryKey"`
+}
+
+// UserRoles migration - join table between users and roles
+type UserRoles struct {
+	UserID uint `gorm:"primaryKey"`
+	RoleID uint `gorm:"primaryKey;index"`
+}
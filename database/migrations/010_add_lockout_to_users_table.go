@@ -0,0 +1,14 @@
+package migrations
+
+import "time"
+
+// UsersLockout migration adds the failed login tracking columns to the users table
+type UsersLockout struct {
+	FailedLoginAttempts int `gorm:"not null;default:0"`
+	LockedUntil         *time.Time
+}
+
+// TableName points the migration at the existing users table
+func (UsersLockout) TableName() string {
+	return "users"
+}
@@ -0,0 +1,16 @@
+package migrations
+
+import "time"
+
+// APIKeys migration - GORM will use this struct shape only for migration
+type APIKeys struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index;not null"`
+	Name       string `gorm:"not null"`
+	Prefix     string `gorm:"not null"`
+	KeyHash    string `gorm:"uniqueIndex;not null"`
+	Scopes     string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import "time"
+
+// UserIdentities migration - GORM will use this struct shape only for migration
+type UserIdentities struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	Provider  string `gorm:"uniqueIndex:idx_user_identities_provider_subject;not null"`
+	Subject   string `gorm:"uniqueIndex:idx_user_identities_provider_subject;not null"`
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SocialLoginStates migration - GORM will use this struct shape only for migration
+type SocialLoginStates struct {
+	ID           uint      `gorm:"primaryKey"`
+	Provider     string    `gorm:"not null"`
+	StateHash    string    `gorm:"uniqueIndex;not null"`
+	CodeVerifier string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
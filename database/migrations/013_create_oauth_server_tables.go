@@ -0,0 +1,52 @@
+package migrations
+
+import "time"
+
+// OAuthClients migration - GORM will use this struct shape only for migration
+type OAuthClients struct {
+	ID           uint   `gorm:"primaryKey"`
+	ClientID     string `gorm:"uniqueIndex;not null"`
+	SecretHash   string
+	Name         string `gorm:"not null"`
+	RedirectURIs string
+	GrantTypes   string `gorm:"not null"`
+	Scopes       string
+	Public       bool `gorm:"not null;default:false"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName sets the table name for the migration
+func (OAuthClients) TableName() string {
+	return "oauth_clients"
+}
+
+// OAuthAuthorizationCodes migration - GORM will use this struct shape only for migration
+type OAuthAuthorizationCodes struct {
+	ID            uint   `gorm:"primaryKey"`
+	CodeHash      string `gorm:"uniqueIndex;not null"`
+	ClientID      string `gorm:"index;not null"`
+	UserID        uint   `gorm:"index;not null"`
+	RedirectURI   string `gorm:"not null"`
+	Scope         string
+	CodeChallenge string `gorm:"not null"`
+	Nonce         string
+	ExpiresAt     time.Time `gorm:"index;not null"`
+	CreatedAt     time.Time
+}
+
+// TableName sets the table name for the migration
+func (OAuthAuthorizationCodes) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// RefreshTokensOAuth migration adds the OAuth client columns to the refresh_tokens table
+type RefreshTokensOAuth struct {
+	ClientID string `gorm:"index"`
+	Scope    string
+}
+
+// TableName points the migration at the existing refresh_tokens table
+func (RefreshTokensOAuth) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import "time"
+
+// UsersPasswordChangedAt migration adds the password age column to the users table
+type UsersPasswordChangedAt struct {
+	PasswordChangedAt *time.Time
+}
+
+// TableName points the migration at the existing users table
+func (UsersPasswordChangedAt) TableName() string {
+	return "users"
+}
+
+// PasswordHistories migration - GORM will use this struct shape only for migration
+type PasswordHistories struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"index;not null"`
+	PasswordHash string `gorm:"not null"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import "time"
+
+// Sessions migration - GORM will use this struct shape only for migration
+type Sessions struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index;not null"`
+	FamilyID   string `gorm:"uniqueIndex;not null"`
+	UserAgent  string
+	IPAddress  string
+	LastSeenAt time.Time `gorm:"index"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
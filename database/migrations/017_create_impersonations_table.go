@@ -0,0 +1,17 @@
+package migrations
+
+import "time"
+
+// Impersonations migration - GORM will use this struct shape only for migration
+type Impersonations struct {
+	ID             uint   `gorm:"primaryKey"`
+	ImpersonatorID uint   `gorm:"index;not null"`
+	UserID         uint   `gorm:"index;not null"`
+	Reason         string `gorm:"not null"`
+	TokenID        string `gorm:"uniqueIndex;not null"`
+	IPAddress      string
+	UserAgent      string
+	StartedAt      time.Time `gorm:"index"`
+	ExpiresAt      time.Time
+	EndedAt        *time.Time
+}
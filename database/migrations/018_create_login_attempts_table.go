@@ -0,0 +1,17 @@
+package migrations
+
+import "time"
+
+// LoginAttempts migration - GORM will use this struct shape only for migration
+type LoginAttempts struct {
+	ID            uint   `gorm:"primaryKey"`
+	UserID        *uint  `gorm:"index"`
+	Email         string `gorm:"index"`
+	Method        string `gorm:"not null"`
+	Success       bool
+	FailureReason string
+	IPAddress     string
+	UserAgent     string
+	NewDevice     bool
+	CreatedAt     time.Time `gorm:"index"`
+}
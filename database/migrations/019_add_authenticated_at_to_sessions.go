@@ -0,0 +1,13 @@
+package migrations
+
+import "time"
+
+// SessionsAuthenticatedAt migration adds the re-authentication time column to the sessions table
+type SessionsAuthenticatedAt struct {
+	AuthenticatedAt *time.Time
+}
+
+// TableName points the migration at the existing sessions table
+func (SessionsAuthenticatedAt) TableName() string {
+	return "sessions"
+}
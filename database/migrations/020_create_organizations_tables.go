@@ -0,0 +1,22 @@
+package migrations
+
+import "time"
+
+// Organizations migration - GORM will use this struct shape only for migration
+type Organizations struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	Slug      string `gorm:"uniqueIndex;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrganizationMembers migration - GORM will use this struct shape only for migration
+type OrganizationMembers struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"uniqueIndex:idx_organization_members_organization_user;not null"`
+	UserID         uint   `gorm:"uniqueIndex:idx_organization_members_organization_user;index;not null"`
+	Role           string `gorm:"not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import "time"
+
+// OrganizationInvitations migration - GORM will use this struct shape only for migration
+type OrganizationInvitations struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"index;not null"`
+	Email          string `gorm:"index;not null"`
+	Role           string `gorm:"not null"`
+	TokenHash      string `gorm:"uniqueIndex;not null"`
+	InvitedByID    uint   `gorm:"not null"`
+	Status         string `gorm:"index;not null"`
+	ExpiresAt      time.Time
+	AcceptedAt     *time.Time
+	AcceptedByID   *uint
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,14 @@
+package migrations
+
+import "time"
+
+// UsersProvisioning migration adds the SCIM provisioning columns to the users table
+type UsersProvisioning struct {
+	ExternalID    string `gorm:"index"`
+	DeactivatedAt *time.Time
+}
+
+// TableName points the migration at the existing users table
+func (UsersProvisioning) TableName() string {
+	return "users"
+}
@@ -0,0 +1,11 @@
+package migrations
+
+// UsersAuthSource migration adds the column recording where a user's password is checked
+type UsersAuthSource struct {
+	AuthSource string `gorm:"not null;default:local"`
+}
+
+// TableName points the migration at the existing users table
+func (UsersAuthSource) TableName() string {
+	return "users"
+}
@@ -0,0 +1,57 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// tables lists the migration structs in the order they must be created
+func tables() []interface{} {
+	return []interface{}{
+		&Users{},
+		&RefreshTokens{},
+		&RevokedTokens{},
+		&PasswordResetTokens{},
+		&UsersEmailVerification{},
+		&EmailVerificationTokens{},
+		&UsersTwoFactor{},
+		&TwoFactorRecoveryCodes{},
+		&TwoFactorChallenges{},
+		&Roles{},
+		&Permissions{},
+		&RolePermissions{},
+		&UserRoles{},
+		&UsersLockout{},
+		&APIKeys{},
+		&UserIdentities{},
+		&SocialLoginStates{},
+		&OAuthClients{},
+		&OAuthAuthorizationCodes{},
+		&RefreshTokensOAuth{},
+		&UsersPasswordChangedAt{},
+		&PasswordHistories{},
+		&Sessions{},
+		&MagicLinkTokens{},
+		&Impersonations{},
+		&LoginAttempts{},
+		&SessionsAuthenticatedAt{},
+		&Organizations{},
+		&OrganizationMembers{},
+		&OrganizationInvitations{},
+		&UsersProvisioning{},
+		&UsersAuthSource{},
+	}
+}
+
+// MigrateUp runs all migrations
+func MigrateUp(db *gorm.DB) error {
+	return db.AutoMigrate(tables()...)
+}
+
+// MigrateDown drops all migrated tables in reverse order
+func MigrateDown(db *gorm.DB) error {
+	t := tables()
+	for i := len(t) - 1; i >= 0; i-- {
+		if err := db.Migrator().DropTable(t[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -18,12 +18,11 @@ func Seed(cfg *config.Config, db *gorm.DB) error {
 	}
 
 	// Run seeders
-	if err := seeders.SeedUsers(db); err != nil {
+	if err := SeedOnly(cfg, db); err != nil {
 		return err
 	}
 
 	log.Println("Database migrated and seeded successfully")
-	_ = utils.HashPassword // ensure utils imported when not used elsewhere
 	return nil
 }
 
@@ -37,8 +36,16 @@ func MigrateOnly(db *gorm.DB) error {
 }
 
 // SeedOnly runs seeders without running migrations
-func SeedOnly(db *gorm.DB) error {
-	if err := seeders.SeedUsers(db); err != nil {
+func SeedOnly(cfg *config.Config, db *gorm.DB) error {
+	hasher, err := utils.NewPasswordHasher(cfg.Password)
+	if err != nil {
+		return err
+	}
+
+	if err := seeders.SeedUsers(db, hasher); err != nil {
+		return err
+	}
+	if err := seeders.SeedRoles(db); err != nil {
 		return err
 	}
 	log.Println("Database seeded successfully (seed only)")
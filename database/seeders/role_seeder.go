@@ -0,0 +1,56 @@
+package seeders
+
+import (
+	"errors"
+	"log"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SeedRoles inserts the built-in roles and permissions and gives the seeded admin user the admin role.
+// It is safe to run repeatedly.
+func SeedRoles(db *gorm.DB) error {
+	permissions := []models.Permission{
+		{Name: models.PermissionUsersCreate, Description: "Create users"},
+		{Name: models.PermissionUsersRead, Description: "List and view users"},
+		{Name: models.PermissionUsersUpdate, Description: "Update users"},
+		{Name: models.PermissionUsersDelete, Description: "Delete users"},
+		{Name: models.PermissionUsersUnlock, Description: "Unlock accounts locked after failed logins"},
+		{Name: models.PermissionUsersSessions, Description: "List and revoke users' sessions"},
+		{Name: models.PermissionUsersImpersonate, Description: "Sign in as another user for support, with an audit trail"},
+		{Name: models.PermissionRolesManage, Description: "Assign and remove user roles"},
+		{Name: models.PermissionOrganizationsAdd, Description: "Add existing users to organizations without an invitation"},
+		{Name: models.PermissionOAuthClientsManage, Description: "Register and delete OAuth clients"},
+	}
+	for i := range permissions {
+		if err := db.Where(models.Permission{Name: permissions[i].Name}).
+			Attrs(models.Permission{Description: permissions[i].Description}).
+			FirstOrCreate(&permissions[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	admin := models.Role{Name: models.RoleAdmin}
+	if err := db.Where(models.Role{Name: models.RoleAdmin}).
+		Attrs(models.Role{Description: "Full access to user management"}).
+		FirstOrCreate(&admin).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&admin).Association("Permissions").Append(permissions); err != nil {
+		return err
+	}
+
+	var adminUser models.User
+	err := db.Where("email = ?", "admin@example.com").First(&adminUser).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("admin user not found, skipping admin role assignment")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&adminUser).Association("Roles").Append(&admin)
+}
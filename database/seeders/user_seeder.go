@@ -11,7 +11,7 @@ import (
 )
 
 // SeedUsers inserts initial users into the database
-func SeedUsers(db *gorm.DB) error {
+func SeedUsers(db *gorm.DB, hasher utils.PasswordHasher) error {
 	// check if users already exist
 	var count int64
 	if err := db.Model(&models.User{}).Count(&count).Error; err != nil {
@@ -22,18 +22,21 @@ func SeedUsers(db *gorm.DB) error {
 		return nil
 	}
 
-	hashed, err := utils.HashPassword("password123")
+	hashed, err := hasher.Hash("password123")
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
 	users := []models.User{
 		{
-			Name:      "Admin",
-			Email:     "admin@example.com",
-			Password:  hashed,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			Name:              "Admin",
+			Email:             "admin@example.com",
+			Password:          hashed,
+			EmailVerifiedAt:   &now,
+			PasswordChangedAt: &now,
+			CreatedAt:         now,
+			UpdatedAt:         now,
 		},
 	}
 
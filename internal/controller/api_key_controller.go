@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// APIKeyController handles API key management HTTP requests
+type APIKeyController struct {
+	apiKeyService service.APIKeyService
+	validator     *validator.Validate
+}
+
+// NewAPIKeyController creates a new API key controller
+func NewAPIKeyController(apiKeyService service.APIKeyService) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: apiKeyService,
+		validator:     validator.New(),
+	}
+}
+
+// CreateAPIKey handles POST /profile/api-keys (protected route)
+// @Summary      Create API Key
+// @Description  Create an API key for machine clients. Send it in the X-API-Key header. The key is only shown in this response.
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.CreateAPIKeyRequest true "API key details"
+// @Success      201 {object} models.CreateAPIKeyResponse
+// @Router       /profile/api-keys [post]
+func (ac *APIKeyController) CreateAPIKey(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ac.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	key, err := ac.apiKeyService.CreateAPIKey(userID, req)
+	if err != nil {
+		ac.respondError(c, "create_api_key_failed", err)
+		return
+	}
+
+	utils.Created(c, "API key created successfully", key)
+}
+
+// ListAPIKeys handles GET /profile/api-keys (protected route)
+// @Summary      List API Keys
+// @Description  List the authenticated user's API keys
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.APIKeyResponse
+// @Router       /profile/api-keys [get]
+func (ac *APIKeyController) ListAPIKeys(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	keys, err := ac.apiKeyService.ListAPIKeys(userID)
+	if err != nil {
+		ac.respondError(c, "list_api_keys_failed", err)
+		return
+	}
+
+	utils.Success(c, keys)
+}
+
+// RevokeAPIKey handles DELETE /profile/api-keys/:id (protected route)
+// @Summary      Revoke API Key
+// @Description  Revoke one of the authenticated user's API keys
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "API key ID"
+// @Success      200 {object} models.MessageResponse
+// @Router       /profile/api-keys/{id} [delete]
+func (ac *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid API key ID")
+		return
+	}
+
+	if err := ac.apiKeyService.RevokeAPIKey(userID, id); err != nil {
+		ac.respondError(c, "revoke_api_key_failed", err)
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "API key revoked successfully")
+}
+
+// respondError maps API key service errors to HTTP responses
+func (ac *APIKeyController) respondError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		utils.NotFound(c, code, err.Error())
+	case errors.Is(err, service.ErrInvalidScope),
+		errors.Is(err, service.ErrInvalidExpiry):
+		utils.BadRequest(c, code, err.Error())
+	default:
+		utils.InternalServerError(c, code, err.Error())
+	}
+}
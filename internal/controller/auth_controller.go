@@ -1,7 +1,11 @@
 package controller
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"golang-starter-kit/internal/models"
 	"golang-starter-kit/internal/service"
@@ -13,25 +17,40 @@ import (
 
 // AuthController handles authentication-related HTTP requests
 type AuthController struct {
-	userService service.UserService
-	validator   *validator.Validate
+	userService         service.UserService
+	tokenService        service.TokenService
+	passwordService     service.PasswordService
+	verificationService service.VerificationService
+	cookies             *utils.AuthCookies
+	validator           *validator.Validate
 }
 
 // NewAuthController creates a new auth controller
-func NewAuthController(userService service.UserService) *AuthController {
+func NewAuthController(
+	userService service.UserService,
+	tokenService service.TokenService,
+	passwordService service.PasswordService,
+	verificationService service.VerificationService,
+	cookies *utils.AuthCookies,
+) *AuthController {
 	return &AuthController{
-		userService: userService,
-		validator:   validator.New(),
+		userService:         userService,
+		tokenService:        tokenService,
+		passwordService:     passwordService,
+		verificationService: verificationService,
+		cookies:             cookies,
+		validator:           validator.New(),
 	}
 }
 
 // Login handles POST /auth/login
 // @Summary      User Login
-// @Description  Authenticate user with email and password
+// @Description  Authenticate user with email and password. Passwords are checked against local accounts, then the LDAP directory when one is configured; directory users get an account on their first login. With "X-Auth-Mode: cookie" the tokens are set as HttpOnly cookies and the response carries a CSRF token instead.
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
 // @Param        request body models.LoginRequest true "Login credentials"
+// @Param        X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
 // @Router       /auth/login [post]
 func (ac *AuthController) Login(c *gin.Context) {
 	var req models.LoginRequest
@@ -46,7 +65,30 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	loginResponse, err := ac.userService.Login(req)
+	loginResponse, err := ac.userService.Login(req, utils.GetClientInfo(c))
+	var lockedErr *service.AccountLockedError
+	if errors.As(err, &lockedErr) {
+		retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+		c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		utils.RespondError(c, http.StatusLocked, "account_locked", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		utils.RespondError(c, http.StatusForbidden, "email_not_verified", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrAccountDeactivated) {
+		utils.RespondError(c, http.StatusForbidden, "account_deactivated", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrPasswordExpired) {
+		utils.RespondError(c, http.StatusForbidden, "password_expired", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrDirectoryUnavailable) {
+		utils.RespondError(c, http.StatusServiceUnavailable, "directory_unavailable", err.Error())
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "login_failed",
@@ -55,19 +97,82 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	if ac.cookies.Requested(c) && !issueAuthCookies(c, ac.cookies, loginResponse) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Login successful",
 		"data":    loginResponse,
 	})
 }
 
+// Refresh handles POST /auth/refresh
+// @Summary      Refresh Tokens
+// @Description  Exchange a refresh token for a new access and refresh token pair. Each refresh token can be used once. In cookie mode the refresh token is read from the refresh cookie, the CSRF header is required and new cookies are set.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.RefreshTokenRequest false "Refresh token"
+// @Param        X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
+// @Success      200 {object} models.LoginResponse
+// @Router       /auth/refresh [post]
+func (ac *AuthController) Refresh(c *gin.Context) {
+	// The request body is optional when the refresh token comes from the cookie
+	var req models.RefreshTokenRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.InvalidRequest(c, err)
+			return
+		}
+	}
+
+	refreshToken := req.RefreshToken
+	fromCookie := false
+	if refreshToken == "" {
+		refreshToken = ac.cookies.RefreshToken(c)
+		fromCookie = refreshToken != ""
+	}
+	if refreshToken == "" {
+		utils.BadRequest(c, "invalid_request", "refresh_token is required")
+		return
+	}
+	if fromCookie && !ac.cookies.ValidCSRF(c) {
+		utils.Forbidden(c, "csrf_token_invalid", "Missing or invalid CSRF token")
+		return
+	}
+
+	loginResponse, err := ac.tokenService.Refresh(refreshToken, utils.GetClientInfo(c))
+	if err != nil {
+		if fromCookie {
+			ac.cookies.Clear(c)
+		}
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			utils.RespondError(c, http.StatusUnauthorized, "refresh_token_reused", err.Error())
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			utils.RespondError(c, http.StatusUnauthorized, "refresh_failed", err.Error())
+		default:
+			utils.InternalServerError(c, "refresh_failed", err.Error())
+		}
+		return
+	}
+
+	if (fromCookie || ac.cookies.Requested(c)) && !issueAuthCookies(c, ac.cookies, loginResponse) {
+		return
+	}
+
+	utils.SuccessMessage(c, "Token refreshed successfully", loginResponse)
+}
+
 // Register handles POST /auth/register
 // @Summary      User Registration
-// @Description  Register a new user account
+// @Description  Register a new user account and send an email verification link
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
 // @Param        request body models.UserCreateRequest true "User registration data"
+// @Failure      400 {object} models.PasswordPolicyErrorResponse
 // @Router       /auth/register [post]
 func (ac *AuthController) Register(c *gin.Context) {
 	var req models.UserCreateRequest
@@ -85,22 +190,297 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := ac.userService.CreateUser(req)
+	user, err := ac.userService.CreateUser(c.Request.Context(), req)
 	if err != nil {
+		if respondPasswordPolicyError(c, err) {
+			return
+		}
 		utils.Conflict(c, "registration_failed", err.Error())
 		return
 	}
 
-	utils.Created(c, "Registration successful", user)
+	if err := ac.verificationService.SendVerification(user.ID); err != nil {
+		utils.Created(c, "Registration successful, but the verification email could not be sent. Please request a new one.", user)
+		return
+	}
+
+	utils.Created(c, "Registration successful. Please check your email to verify your account.", user)
 }
 
 // Logout handles POST /auth/logout
 // @Summary      User Logout
-// @Description  Logout user (client-side token removal)
+// @Description  Revoke the current access token and, when provided, the refresh token issued with it. Authentication cookies are cleared.
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.LogoutRequest false "Refresh token to revoke"
+// @Success      200 {object} models.MessageResponse
 // @Router       /auth/logout [post]
 func (ac *AuthController) Logout(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// The request body is optional
+	var req models.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.InvalidRequest(c, err)
+			return
+		}
+	}
+
+	refreshToken := req.RefreshToken
+	if refreshToken == "" {
+		refreshToken = ac.cookies.RefreshToken(c)
+	}
+
+	if err := ac.tokenService.Logout(claims, refreshToken); err != nil {
+		utils.InternalServerError(c, "logout_failed", err.Error())
+		return
+	}
+
+	if ac.cookies.Enabled() {
+		ac.cookies.Clear(c)
+	}
+
 	utils.Message(c, http.StatusOK, "Logout successful")
 }
+
+// Reauthenticate handles POST /auth/reauthenticate (protected route)
+// @Summary      Re-authenticate
+// @Description  Confirm the password again before a sensitive action. Returns an access token for the same session whose auth_time is now; routes that require recent authentication answer 403 reauthentication_required until then.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.ReauthenticateRequest true "Current password"
+// @Param        X-Auth-Mode header string false "Set to cookie to receive the access token as a cookie"
+// @Success      200 {object} models.LoginResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      423 {object} models.ErrorResponse
+// @Router       /auth/reauthenticate [post]
+func (ac *AuthController) Reauthenticate(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.ReauthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ac.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	loginResponse, err := ac.userService.Reauthenticate(claims, req, utils.GetClientInfo(c))
+	if err != nil {
+		var lockedErr *service.AccountLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			utils.RespondError(c, http.StatusLocked, "account_locked", err.Error())
+		case errors.Is(err, service.ErrIncorrectPassword):
+			utils.BadRequest(c, "reauthentication_failed", err.Error())
+		case errors.Is(err, service.ErrAccountDeactivated):
+			utils.Forbidden(c, "account_deactivated", err.Error())
+		case errors.Is(err, service.ErrDirectoryUnavailable):
+			utils.RespondError(c, http.StatusServiceUnavailable, "directory_unavailable", err.Error())
+		case errors.Is(err, service.ErrSessionRevoked), errors.Is(err, service.ErrUserNotFound):
+			utils.Unauthorized(c, err.Error())
+		default:
+			utils.InternalServerError(c, "reauthentication_failed", err.Error())
+		}
+		return
+	}
+
+	if ac.cookies.Requested(c) && !issueAuthCookies(c, ac.cookies, loginResponse) {
+		return
+	}
+
+	utils.SuccessMessage(c, "Re-authentication successful", loginResponse)
+}
+
+// LogoutAll handles POST /auth/logout-all
+// @Summary      Logout Everywhere
+// @Description  Revoke every access and refresh token issued to the authenticated user
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.MessageResponse
+// @Router       /auth/logout-all [post]
+func (ac *AuthController) LogoutAll(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := ac.tokenService.LogoutAll(userID); err != nil {
+		utils.InternalServerError(c, "logout_failed", err.Error())
+		return
+	}
+
+	if ac.cookies.Enabled() {
+		ac.cookies.Clear(c)
+	}
+
+	utils.Message(c, http.StatusOK, "Logged out from all devices")
+}
+
+// ForgotPassword handles POST /auth/forgot-password
+// @Summary      Forgot Password
+// @Description  Send a password reset link. The response is the same whether or not the email is registered.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ForgotPasswordRequest true "Account email"
+// @Success      200 {object} models.MessageResponse
+// @Router       /auth/forgot-password [post]
+func (ac *AuthController) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ac.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	if err := ac.passwordService.ForgotPassword(req.Email); err != nil {
+		utils.InternalServerError(c, "forgot_password_failed", "Unable to process the request")
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "If an account exists for this email, a password reset link has been sent")
+}
+
+// ResetPassword handles POST /auth/reset-password
+// @Summary      Reset Password
+// @Description  Set a new password using a reset token. All existing sessions are signed out.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success      200 {object} models.MessageResponse
+// @Failure      400 {object} models.PasswordPolicyErrorResponse
+// @Router       /auth/reset-password [post]
+func (ac *AuthController) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ac.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	if err := ac.passwordService.ResetPassword(req); err != nil {
+		if respondPasswordPolicyError(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			utils.BadRequest(c, "invalid_reset_token", err.Error())
+			return
+		}
+		utils.InternalServerError(c, "reset_password_failed", err.Error())
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Password has been reset successfully")
+}
+
+// VerifyEmail handles POST /auth/verify-email
+// @Summary      Verify Email
+// @Description  Confirm an email address using the token from the verification email
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.VerifyEmailRequest true "Verification token"
+// @Success      200 {object} models.MessageResponse
+// @Router       /auth/verify-email [post]
+func (ac *AuthController) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ac.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	if err := ac.verificationService.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			utils.BadRequest(c, "invalid_verification_token", err.Error())
+			return
+		}
+		utils.InternalServerError(c, "verification_failed", err.Error())
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Email verified successfully")
+}
+
+// ResendVerification handles POST /auth/verify-email/resend
+// @Summary      Resend Verification Email
+// @Description  Send a new verification link. The response is the same whether or not the email is registered.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ResendVerificationRequest true "Account email"
+// @Success      200 {object} models.MessageResponse
+// @Router       /auth/verify-email/resend [post]
+func (ac *AuthController) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ac.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	if err := ac.verificationService.ResendVerification(req.Email); err != nil {
+		if errors.Is(err, service.ErrVerificationThrottled) {
+			utils.RespondError(c, http.StatusTooManyRequests, "rate_limit_exceeded", err.Error())
+			return
+		}
+		utils.InternalServerError(c, "resend_verification_failed", "Unable to process the request")
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "If the account exists and is not verified yet, a verification email has been sent")
+}
+
+// issueAuthCookies moves the tokens of a login response into cookies. It
+// reports false after responding with an error.
+func issueAuthCookies(c *gin.Context, cookies *utils.AuthCookies, response *models.LoginResponse) bool {
+	if err := cookies.Issue(c, response); err != nil {
+		utils.InternalServerError(c, "login_failed", err.Error())
+		return false
+	}
+	return true
+}
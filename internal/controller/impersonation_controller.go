@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ImpersonationController handles admin impersonation HTTP requests
+type ImpersonationController struct {
+	impersonationService service.ImpersonationService
+	validator            *validator.Validate
+}
+
+// NewImpersonationController creates a new impersonation controller
+func NewImpersonationController(impersonationService service.ImpersonationService) *ImpersonationController {
+	return &ImpersonationController{
+		impersonationService: impersonationService,
+		validator:            validator.New(),
+	}
+}
+
+// Start handles POST /users/:id/impersonate
+// @Summary      Impersonate User
+// @Description  Issue a short-lived access token that acts as the user, for support. The token names the admin in its act claim, has no refresh token and cannot change passwords or other credentials. Every impersonation is recorded with its reason.
+// @Tags         Impersonation
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        request body models.ImpersonateRequest true "Reason for the impersonation"
+// @Success      201 {object} models.ImpersonationTokenResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Router       /users/{id}/impersonate [post]
+func (ic *ImpersonationController) Start(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid user ID")
+		return
+	}
+
+	var req models.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ic.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	response, err := ic.impersonationService.Start(c.Request.Context(), claims, id, req, utils.GetClientInfo(c))
+	if err != nil {
+		ic.respondError(c, "impersonation_failed", err)
+		return
+	}
+
+	utils.Created(c, "Impersonation started", response)
+}
+
+// Stop handles POST /auth/impersonation/stop (protected route)
+// @Summary      Stop Impersonating
+// @Description  End the impersonation of the presented impersonation token. The token stops working immediately.
+// @Tags         Impersonation
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.MessageResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Router       /auth/impersonation/stop [post]
+func (ic *ImpersonationController) Stop(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := ic.impersonationService.Stop(claims); err != nil {
+		ic.respondError(c, "stop_impersonation_failed", err)
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Impersonation stopped")
+}
+
+// List handles GET /impersonations
+// @Summary      List Impersonations
+// @Description  List the most recent impersonations, newest first, optionally only those of or by one user
+// @Tags         Impersonation
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id query int false "Only impersonations of or by this user"
+// @Success      200 {array} models.ImpersonationResponse
+// @Router       /impersonations [get]
+func (ic *ImpersonationController) List(c *gin.Context) {
+	var userID uint
+	if value := c.Query("user_id"); value != "" {
+		id, err := utils.StringToUint(value)
+		if err != nil {
+			utils.BadRequest(c, "invalid_id", "Invalid user ID")
+			return
+		}
+		userID = id
+	}
+
+	impersonations, err := ic.impersonationService.List(c.Request.Context(), userID)
+	if err != nil {
+		ic.respondError(c, "list_impersonations_failed", err)
+		return
+	}
+
+	utils.Success(c, impersonations)
+}
+
+// respondError maps impersonation service errors to HTTP responses
+func (ic *ImpersonationController) respondError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFound(c, code, err.Error())
+	case errors.Is(err, service.ErrCannotImpersonateSelf),
+		errors.Is(err, service.ErrNotImpersonating):
+		utils.BadRequest(c, code, err.Error())
+	case errors.Is(err, service.ErrImpersonationNotAllowed):
+		utils.Forbidden(c, code, err.Error())
+	default:
+		utils.InternalServerError(c, code, err.Error())
+	}
+}
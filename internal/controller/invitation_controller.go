@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// InvitationController handles organization invitation HTTP requests
+type InvitationController struct {
+	invitationService service.InvitationService
+	validator         *validator.Validate
+}
+
+// NewInvitationController creates a new invitation controller
+func NewInvitationController(invitationService service.InvitationService) *InvitationController {
+	return &InvitationController{
+		invitationService: invitationService,
+		validator:         validator.New(),
+	}
+}
+
+// Create handles POST /organizations/:id/invitations
+// @Summary      Invite to Organization
+// @Description  Email an invitation to join the organization with a role. Owners and admins can invite members and admins; only owners can invite owners.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Organization ID"
+// @Param        request body models.CreateInvitationRequest true "Email and role"
+// @Success      201 {object} models.InvitationResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Router       /organizations/{id}/invitations [post]
+func (ic *InvitationController) Create(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid organization ID")
+		return
+	}
+
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ic.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	invitation, err := ic.invitationService.Create(id, userID, req)
+	if err != nil {
+		ic.respondError(c, "create_invitation_failed", err)
+		return
+	}
+
+	utils.Created(c, "Invitation sent successfully", invitation)
+}
+
+// List handles GET /organizations/:id/invitations
+// @Summary      List Organization Invitations
+// @Description  List the organization's invitations, newest first. Only owners and admins can list them.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Organization ID"
+// @Success      200 {array} models.InvitationResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Router       /organizations/{id}/invitations [get]
+func (ic *InvitationController) List(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid organization ID")
+		return
+	}
+
+	invitations, err := ic.invitationService.List(id, userID)
+	if err != nil {
+		ic.respondError(c, "list_invitations_failed", err)
+		return
+	}
+
+	utils.Success(c, invitations)
+}
+
+// Resend handles POST /organizations/:id/invitations/:invitation_id/resend
+// @Summary      Resend Invitation
+// @Description  Email a pending or expired invitation again with a new link and expiry. The previous link stops working.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Organization ID"
+// @Param        invitation_id path int true "Invitation ID"
+// @Success      200 {object} models.InvitationResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Router       /organizations/{id}/invitations/{invitation_id}/resend [post]
+func (ic *InvitationController) Resend(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, invitationID, ok := invitationParams(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := ic.invitationService.Resend(id, userID, invitationID)
+	if err != nil {
+		ic.respondError(c, "resend_invitation_failed", err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Invitation resent successfully", invitation)
+}
+
+// Revoke handles DELETE /organizations/:id/invitations/:invitation_id
+// @Summary      Revoke Invitation
+// @Description  Cancel a pending or expired invitation so its link can no longer be accepted
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Organization ID"
+// @Param        invitation_id path int true "Invitation ID"
+// @Success      200 {object} models.MessageResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Router       /organizations/{id}/invitations/{invitation_id} [delete]
+func (ic *InvitationController) Revoke(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, invitationID, ok := invitationParams(c)
+	if !ok {
+		return
+	}
+
+	if err := ic.invitationService.Revoke(id, userID, invitationID); err != nil {
+		ic.respondError(c, "revoke_invitation_failed", err)
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Invitation revoked successfully")
+}
+
+// Accept handles POST /invitations/accept
+// @Summary      Accept Invitation
+// @Description  Join an organization with the token from an invitation email. The invited email's account joins with the invited role; when no account exists, one is created with the given name and password. The email address counts as verified.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        request body models.AcceptInvitationRequest true "Invitation token, and name and password for a new account"
+// @Success      200 {object} models.AcceptInvitationResponse
+// @Failure      400 {object} models.PasswordPolicyErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Router       /invitations/accept [post]
+func (ic *InvitationController) Accept(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := ic.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	response, err := ic.invitationService.Accept(c.Request.Context(), req)
+	if err != nil {
+		if respondPasswordPolicyError(c, err) {
+			return
+		}
+		ic.respondError(c, "accept_invitation_failed", err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Invitation accepted", response)
+}
+
+// invitationParams parses the organization and invitation IDs from the path
+func invitationParams(c *gin.Context) (uint, uint, bool) {
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid organization ID")
+		return 0, 0, false
+	}
+	invitationID, err := utils.StringToUint(c.Param("invitation_id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid invitation ID")
+		return 0, 0, false
+	}
+	return id, invitationID, true
+}
+
+// respondError maps invitation service errors to HTTP responses
+func (ic *InvitationController) respondError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrganizationNotFound),
+		errors.Is(err, service.ErrInvitationNotFound):
+		utils.NotFound(c, code, err.Error())
+	case errors.Is(err, service.ErrAlreadyOrganizationMember),
+		errors.Is(err, service.ErrInvitationPending),
+		errors.Is(err, service.ErrInvitationClosed):
+		utils.Conflict(c, code, err.Error())
+	case errors.Is(err, service.ErrInvalidInvitation),
+		errors.Is(err, service.ErrInvitationAccountRequired):
+		utils.BadRequest(c, code, err.Error())
+	case errors.Is(err, service.ErrOrganizationForbidden):
+		utils.Forbidden(c, code, err.Error())
+	default:
+		utils.InternalServerError(c, code, err.Error())
+	}
+}
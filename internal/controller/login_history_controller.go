@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginHistoryController handles login history HTTP requests
+type LoginHistoryController struct {
+	loginHistoryService service.LoginHistoryService
+}
+
+// NewLoginHistoryController creates a new login history controller
+func NewLoginHistoryController(loginHistoryService service.LoginHistoryService) *LoginHistoryController {
+	return &LoginHistoryController{loginHistoryService: loginHistoryService}
+}
+
+// List handles GET /profile/login-history (protected route)
+// @Summary      Login History
+// @Description  List the most recent login attempts on the authenticated user's account, newest first, with the method, IP address, user agent and why failed attempts failed. Logins from a new device or IP address are flagged.
+// @Tags         Sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.LoginAttemptResponse
+// @Router       /profile/login-history [get]
+func (lc *LoginHistoryController) List(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	attempts, err := lc.loginHistoryService.List(userID)
+	if err != nil {
+		utils.InternalServerError(c, "list_login_history_failed", err.Error())
+		return
+	}
+
+	utils.Success(c, attempts)
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// MagicLinkController handles passwordless login HTTP requests
+type MagicLinkController struct {
+	magicLinkService service.MagicLinkService
+	cookies          *utils.AuthCookies
+	validator        *validator.Validate
+}
+
+// NewMagicLinkController creates a new magic link controller
+func NewMagicLinkController(magicLinkService service.MagicLinkService, cookies *utils.AuthCookies) *MagicLinkController {
+	return &MagicLinkController{
+		magicLinkService: magicLinkService,
+		cookies:          cookies,
+		validator:        validator.New(),
+	}
+}
+
+// SendLink handles POST /auth/magic-link
+// @Summary      Request Magic Link
+// @Description  Email a single-use login link. The response is the same whether or not the email is registered.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MagicLinkRequest true "Account email"
+// @Success      200 {object} models.MessageResponse
+// @Router       /auth/magic-link [post]
+func (mc *MagicLinkController) SendLink(c *gin.Context) {
+	var req models.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := mc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	if err := mc.magicLinkService.SendLink(req.Email); err != nil {
+		if errors.Is(err, service.ErrMagicLinkDisabled) {
+			utils.Forbidden(c, "magic_link_disabled", err.Error())
+			return
+		}
+		utils.InternalServerError(c, "magic_link_failed", "Unable to process the request")
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "If an account exists for this email, a login link has been sent")
+}
+
+// Verify handles POST /auth/magic-link/verify
+// @Summary      Verify Magic Link
+// @Description  Redeem a login link for an access and refresh token pair, or a two-factor challenge when 2FA is enabled
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MagicLinkVerifyRequest true "Login link token"
+// @Param        X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
+// @Success      200 {object} models.LoginResponse
+// @Router       /auth/magic-link/verify [post]
+func (mc *MagicLinkController) Verify(c *gin.Context) {
+	var req models.MagicLinkVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := mc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	loginResponse, err := mc.magicLinkService.Verify(req, utils.GetClientInfo(c))
+	if err != nil {
+		var lockedErr *service.AccountLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			utils.RespondError(c, http.StatusLocked, "account_locked", err.Error())
+		case errors.Is(err, service.ErrAccountDeactivated):
+			utils.Forbidden(c, "account_deactivated", err.Error())
+		case errors.Is(err, service.ErrMagicLinkDisabled):
+			utils.Forbidden(c, "magic_link_disabled", err.Error())
+		case errors.Is(err, service.ErrInvalidMagicLink):
+			utils.RespondError(c, http.StatusUnauthorized, "login_failed", err.Error())
+		default:
+			utils.InternalServerError(c, "login_failed", err.Error())
+		}
+		return
+	}
+
+	if mc.cookies.Requested(c) && !issueAuthCookies(c, mc.cookies, loginResponse) {
+		return
+	}
+
+	utils.SuccessMessage(c, "Login successful", loginResponse)
+}
@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// OAuthController handles the OAuth2 authorization server HTTP requests
+type OAuthController struct {
+	oauthService service.OAuthService
+	validator    *validator.Validate
+}
+
+// NewOAuthController creates a new OAuth controller
+func NewOAuthController(oauthService service.OAuthService) *OAuthController {
+	return &OAuthController{
+		oauthService: oauthService,
+		validator:    validator.New(),
+	}
+}
+
+// CreateClient handles POST /oauth/clients
+// @Summary      Register OAuth Client
+// @Description  Register an application that signs users in through this service. The client secret is only shown in this response.
+// @Tags         OAuth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.CreateOAuthClientRequest true "Client details"
+// @Success      201 {object} models.CreateOAuthClientResponse
+// @Router       /oauth/clients [post]
+func (oc *OAuthController) CreateClient(c *gin.Context) {
+	var req models.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := oc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	client, err := oc.oauthService.CreateClient(req)
+	if err != nil {
+		if errors.Is(err, service.ErrRedirectURIRequired) || errors.Is(err, service.ErrPublicClientCredentials) {
+			utils.BadRequest(c, "create_client_failed", err.Error())
+			return
+		}
+		utils.InternalServerError(c, "create_client_failed", err.Error())
+		return
+	}
+
+	utils.Created(c, "OAuth client registered successfully", client)
+}
+
+// ListClients handles GET /oauth/clients
+// @Summary      List OAuth Clients
+// @Description  List the registered OAuth clients
+// @Tags         OAuth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.OAuthClientResponse
+// @Router       /oauth/clients [get]
+func (oc *OAuthController) ListClients(c *gin.Context) {
+	clients, err := oc.oauthService.ListClients()
+	if err != nil {
+		utils.InternalServerError(c, "list_clients_failed", err.Error())
+		return
+	}
+
+	utils.Success(c, clients)
+}
+
+// DeleteClient handles DELETE /oauth/clients/:id
+// @Summary      Delete OAuth Client
+// @Description  Delete an OAuth client and revoke its refresh tokens
+// @Tags         OAuth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Client ID"
+// @Success      200 {object} models.MessageResponse
+// @Router       /oauth/clients/{id} [delete]
+func (oc *OAuthController) DeleteClient(c *gin.Context) {
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid client ID")
+		return
+	}
+
+	if err := oc.oauthService.DeleteClient(id); err != nil {
+		if errors.Is(err, service.ErrOAuthClientNotFound) {
+			utils.NotFound(c, "delete_client_failed", err.Error())
+			return
+		}
+		utils.InternalServerError(c, "delete_client_failed", err.Error())
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "OAuth client deleted successfully")
+}
+
+// Authorize handles POST /oauth/authorize (protected route)
+// @Summary      Authorize OAuth Client
+// @Description  Called by the sign-in page after the user consents. Returns the client redirect URL carrying the authorization code, or an OAuth error for the client.
+// @Tags         OAuth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.AuthorizeRequest true "Authorization request parameters"
+// @Success      200 {object} models.AuthorizeResponse
+// @Failure      400 {object} models.OAuthErrorResponse
+// @Router       /oauth/authorize [post]
+func (oc *OAuthController) Authorize(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.AuthorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := oc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	response, err := oc.oauthService.Authorize(userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			utils.NotFound(c, "authorize_failed", err.Error())
+			return
+		}
+		respondOAuthError(c, err)
+		return
+	}
+
+	utils.Success(c, response)
+}
+
+// Token handles POST /oauth/token
+// @Summary      OAuth Token Endpoint
+// @Description  Exchange an authorization code (with PKCE code_verifier), client credentials or refresh token for tokens. Clients authenticate with HTTP Basic or client_id/client_secret form fields.
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type formData string true "authorization_code, client_credentials or refresh_token"
+// @Param        code formData string false "Authorization code"
+// @Param        redirect_uri formData string false "Redirect URI used in the authorization request"
+// @Param        code_verifier formData string false "PKCE code verifier"
+// @Param        refresh_token formData string false "Refresh token"
+// @Param        scope formData string false "Requested scope"
+// @Param        client_id formData string false "Client ID (when not using HTTP Basic)"
+// @Param        client_secret formData string false "Client secret (when not using HTTP Basic)"
+// @Success      200 {object} models.OAuthTokenResponse
+// @Failure      400 {object} models.OAuthErrorResponse
+// @Router       /oauth/token [post]
+func (oc *OAuthController) Token(c *gin.Context) {
+	var req models.OAuthTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondOAuthError(c, &service.OAuthError{Code: "invalid_request", Description: err.Error()})
+		return
+	}
+
+	clientID, clientSecret := clientCredentialsFromRequest(c)
+	response, err := oc.oauthService.Token(clientID, clientSecret, req)
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, response)
+}
+
+// Introspect handles POST /oauth/introspect
+// @Summary      Token Introspection
+// @Description  Report whether an access or refresh token is active (RFC 7662). Requires confidential client authentication.
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token formData string true "Token to introspect"
+// @Success      200 {object} models.IntrospectionResponse
+// @Failure      401 {object} models.OAuthErrorResponse
+// @Router       /oauth/introspect [post]
+func (oc *OAuthController) Introspect(c *gin.Context) {
+	clientID, clientSecret := clientCredentialsFromRequest(c)
+	response, err := oc.oauthService.Introspect(clientID, clientSecret, c.PostForm("token"))
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke handles POST /oauth/revoke
+// @Summary      Token Revocation
+// @Description  Revoke an access or refresh token issued to the client (RFC 7009). Unknown tokens are ignored.
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token formData string true "Token to revoke"
+// @Success      200
+// @Failure      401 {object} models.OAuthErrorResponse
+// @Router       /oauth/revoke [post]
+func (oc *OAuthController) Revoke(c *gin.Context) {
+	clientID, clientSecret := clientCredentialsFromRequest(c)
+	if err := oc.oauthService.Revoke(clientID, clientSecret, c.PostForm("token")); err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// UserInfo handles GET /oauth/userinfo (protected route)
+// @Summary      OpenID Connect UserInfo
+// @Description  Get the claims of the user the access token was issued for, limited to its openid, profile and email scopes
+// @Tags         OAuth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.UserInfoResponse
+// @Router       /oauth/userinfo [get]
+func (oc *OAuthController) UserInfo(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	info, err := oc.oauthService.UserInfo(claims)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInsufficientScope):
+			utils.Forbidden(c, "insufficient_scope", err.Error())
+		case errors.Is(err, service.ErrUserNotFound):
+			utils.NotFound(c, "userinfo_failed", err.Error())
+		default:
+			utils.InternalServerError(c, "userinfo_failed", err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// clientCredentialsFromRequest reads the client credentials from HTTP Basic
+// authentication or, failing that, from the client_id and client_secret form fields
+func clientCredentialsFromRequest(c *gin.Context) (string, string) {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		// Basic credentials are form-urlencoded first (RFC 6749 section 2.3.1)
+		if unescaped, err := url.QueryUnescape(id); err == nil {
+			id = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(secret); err == nil {
+			secret = unescaped
+		}
+		return id, secret
+	}
+	return c.PostForm("client_id"), c.PostForm("client_secret")
+}
+
+// respondOAuthError writes an OAuth error response (RFC 6749 section 5.2)
+func respondOAuthError(c *gin.Context, err error) {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, models.OAuthErrorResponse{
+			Error:            "server_error",
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+		if _, _, ok := c.Request.BasicAuth(); ok {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+	c.JSON(status, models.OAuthErrorResponse{
+		Error:            oauthErr.Code,
+		ErrorDescription: oauthErr.Description,
+	})
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// OrganizationController handles organization and membership HTTP requests
+type OrganizationController struct {
+	organizationService service.OrganizationService
+	validator           *validator.Validate
+}
+
+// NewOrganizationController creates a new organization controller
+func NewOrganizationController(organizationService service.OrganizationService) *OrganizationController {
+	return &OrganizationController{
+		organizationService: organizationService,
+		validator:           validator.New(),
+	}
+}
+
+// Create handles POST /organizations
+// @Summary      Create Organization
+// @Description  Create an organization. The current user becomes its owner.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.OrganizationCreateRequest true "Organization name and slug"
+// @Success      201 {object} models.OrganizationResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Router       /organizations [post]
+func (oc *OrganizationController) Create(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.OrganizationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := oc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	organization, err := oc.organizationService.Create(userID, req)
+	if err != nil {
+		oc.respondError(c, "create_organization_failed", err)
+		return
+	}
+
+	utils.Created(c, "Organization created successfully", organization)
+}
+
+// List handles GET /organizations
+// @Summary      List Organizations
+// @Description  List the organizations the current user belongs to, with their role in each
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.OrganizationResponse
+// @Router       /organizations [get]
+func (oc *OrganizationController) List(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	organizations, err := oc.organizationService.ListForUser(userID)
+	if err != nil {
+		oc.respondError(c, "list_organizations_failed", err)
+		return
+	}
+
+	utils.Success(c, organizations)
+}
+
+// ListMembers handles GET /organizations/:id/members
+// @Summary      List Organization Members
+// @Description  List the members of an organization the current user belongs to
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Organization ID"
+// @Success      200 {array} models.OrganizationMemberResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Router       /organizations/{id}/members [get]
+func (oc *OrganizationController) ListMembers(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid organization ID")
+		return
+	}
+
+	members, err := oc.organizationService.ListMembers(id, userID)
+	if err != nil {
+		oc.respondError(c, "list_members_failed", err)
+		return
+	}
+
+	utils.Success(c, members)
+}
+
+// AddMember handles POST /organizations/:id/members
+// @Summary      Add Organization Member
+// @Description  Add an existing user to the organization without an invitation. Requires organizations:add_members in addition to being an owner or admin of the organization; only owners can add owners. Other users join by accepting an invitation.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Organization ID"
+// @Param        request body models.AddOrganizationMemberRequest true "User email and role"
+// @Success      201 {object} models.OrganizationMemberResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Router       /organizations/{id}/members [post]
+func (oc *OrganizationController) AddMember(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid organization ID")
+		return
+	}
+
+	var req models.AddOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := oc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	member, err := oc.organizationService.AddMember(id, userID, req)
+	if err != nil {
+		oc.respondError(c, "add_member_failed", err)
+		return
+	}
+
+	utils.Created(c, "Member added successfully", member)
+}
+
+// RemoveMember handles DELETE /organizations/:id/members/:user_id
+// @Summary      Remove Organization Member
+// @Description  Remove a user from the organization. Members can remove themselves; owners and admins can remove others, and only owners can remove owners. The last owner cannot be removed.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Organization ID"
+// @Param        user_id path int true "User ID"
+// @Success      200 {object} models.MessageResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Router       /organizations/{id}/members/{user_id} [delete]
+func (oc *OrganizationController) RemoveMember(c *gin.Context) {
+	actorID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid organization ID")
+		return
+	}
+	userID, err := utils.StringToUint(c.Param("user_id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid user ID")
+		return
+	}
+
+	if err := oc.organizationService.RemoveMember(id, actorID, userID); err != nil {
+		oc.respondError(c, "remove_member_failed", err)
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Member removed successfully")
+}
+
+// respondError maps organization service errors to HTTP responses
+func (oc *OrganizationController) respondError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrganizationNotFound),
+		errors.Is(err, service.ErrOrganizationMemberNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		utils.NotFound(c, code, err.Error())
+	case errors.Is(err, service.ErrOrganizationSlugTaken),
+		errors.Is(err, service.ErrAlreadyOrganizationMember):
+		utils.Conflict(c, code, err.Error())
+	case errors.Is(err, service.ErrInvalidOrganizationSlug),
+		errors.Is(err, service.ErrLastOrganizationOwner):
+		utils.BadRequest(c, code, err.Error())
+	case errors.Is(err, service.ErrOrganizationForbidden):
+		utils.Forbidden(c, code, err.Error())
+	default:
+		utils.InternalServerError(c, code, err.Error())
+	}
+}
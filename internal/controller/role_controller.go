@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"errors"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// RoleController handles role management HTTP requests
+type RoleController struct {
+	roleService service.RoleService
+	validator   *validator.Validate
+}
+
+// NewRoleController creates a new role controller
+func NewRoleController(roleService service.RoleService) *RoleController {
+	return &RoleController{
+		roleService: roleService,
+		validator:   validator.New(),
+	}
+}
+
+// ListRoles handles GET /roles
+// @Summary      List Roles
+// @Description  List every role with its permissions
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.RoleResponse
+// @Router       /roles [get]
+func (rc *RoleController) ListRoles(c *gin.Context) {
+	roles, err := rc.roleService.ListRoles()
+	if err != nil {
+		utils.InternalServerError(c, "list_roles_failed", err.Error())
+		return
+	}
+
+	utils.Success(c, roles)
+}
+
+// AssignRole handles POST /users/:id/roles
+// @Summary      Assign Role
+// @Description  Give a user a role. The authorization policy decides who may assign which role (roles:assign); by default it takes roles:manage. The user's current access tokens are revoked so the change applies on their next refresh.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        request body models.AssignRoleRequest true "Role to assign"
+// @Success      200 {object} models.UserResponse
+// @Failure      403 {object} models.PolicyDeniedResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Router       /users/{id}/roles [post]
+func (rc *RoleController) AssignRole(c *gin.Context) {
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid user ID")
+		return
+	}
+
+	var req models.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := rc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	user, err := rc.roleService.AssignRole(c.Request.Context(), claims, id, req.Role)
+	if err != nil {
+		rc.respondError(c, "assign_role_failed", err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Role assigned successfully", user)
+}
+
+// RemoveRole handles DELETE /users/:id/roles/:role
+// @Summary      Remove Role
+// @Description  Take a role away from a user. The authorization policy decides who may remove which role (roles:remove); by default it takes roles:manage, and the last admin keeps the admin role. The user's current access tokens are revoked so the change applies on their next refresh.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        role path string true "Role name"
+// @Success      200 {object} models.UserResponse
+// @Failure      403 {object} models.PolicyDeniedResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Router       /users/{id}/roles/{role} [delete]
+func (rc *RoleController) RemoveRole(c *gin.Context) {
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid user ID")
+		return
+	}
+
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	user, err := rc.roleService.RemoveRole(c.Request.Context(), claims, id, c.Param("role"))
+	if err != nil {
+		rc.respondError(c, "remove_role_failed", err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Role removed successfully", user)
+}
+
+// respondError maps role service errors to HTTP responses
+func (rc *RoleController) respondError(c *gin.Context, code string, err error) {
+	if respondPolicyDenied(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrRoleNotFound) || errors.Is(err, service.ErrUserNotFound) {
+		utils.NotFound(c, code, err.Error())
+		return
+	}
+	utils.InternalServerError(c, code, err.Error())
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// SCIMController serves the SCIM 2.0 provisioning API under /scim/v2.
+// Responses use the SCIM media type and error format (RFC 7644).
+type SCIMController struct {
+	scimService service.SCIMService
+	validator   *validator.Validate
+}
+
+// NewSCIMController creates a new SCIM controller
+func NewSCIMController(scimService service.SCIMService) *SCIMController {
+	return &SCIMController{
+		scimService: scimService,
+		validator:   validator.New(),
+	}
+}
+
+// ListUsers handles GET /scim/v2/Users
+// It supports the filter (userName or emails with eq, co or sw), startIndex and count parameters.
+func (sc *SCIMController) ListUsers(c *gin.Context) {
+	var req models.SCIMListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondSCIM(c, http.StatusBadRequest, scimErrorBody(http.StatusBadRequest, "invalidValue", err.Error()))
+		return
+	}
+
+	users, err := sc.scimService.ListUsers(c.Request.Context(), req)
+	if err != nil {
+		sc.respondError(c, err)
+		return
+	}
+
+	respondSCIM(c, http.StatusOK, users)
+}
+
+// GetUser handles GET /scim/v2/Users/:id
+func (sc *SCIMController) GetUser(c *gin.Context) {
+	id, ok := sc.userID(c)
+	if !ok {
+		return
+	}
+
+	user, err := sc.scimService.GetUser(c.Request.Context(), id)
+	if err != nil {
+		sc.respondError(c, err)
+		return
+	}
+
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// CreateUser handles POST /scim/v2/Users
+func (sc *SCIMController) CreateUser(c *gin.Context) {
+	var req models.SCIMUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIM(c, http.StatusBadRequest, scimErrorBody(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	user, err := sc.scimService.CreateUser(c.Request.Context(), req)
+	if err != nil {
+		sc.respondError(c, err)
+		return
+	}
+
+	c.Header("Location", user.Meta.Location)
+	respondSCIM(c, http.StatusCreated, user)
+}
+
+// ReplaceUser handles PUT /scim/v2/Users/:id
+func (sc *SCIMController) ReplaceUser(c *gin.Context) {
+	id, ok := sc.userID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SCIMUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIM(c, http.StatusBadRequest, scimErrorBody(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	user, err := sc.scimService.ReplaceUser(c.Request.Context(), id, req)
+	if err != nil {
+		sc.respondError(c, err)
+		return
+	}
+
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// PatchUser handles PATCH /scim/v2/Users/:id
+func (sc *SCIMController) PatchUser(c *gin.Context) {
+	id, ok := sc.userID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIM(c, http.StatusBadRequest, scimErrorBody(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	// Validate request
+	if err := sc.validator.Struct(req); err != nil {
+		respondSCIM(c, http.StatusBadRequest, scimErrorBody(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	user, err := sc.scimService.PatchUser(c.Request.Context(), id, req)
+	if err != nil {
+		sc.respondError(c, err)
+		return
+	}
+
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// DeleteUser handles DELETE /scim/v2/Users/:id
+func (sc *SCIMController) DeleteUser(c *gin.Context) {
+	id, ok := sc.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := sc.scimService.DeleteUser(c.Request.Context(), id); err != nil {
+		sc.respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig
+func (sc *SCIMController) ServiceProviderConfig(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, sc.scimService.ServiceProviderConfig())
+}
+
+// ResourceTypes handles GET /scim/v2/ResourceTypes
+func (sc *SCIMController) ResourceTypes(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, sc.scimService.ResourceTypes())
+}
+
+// Schemas handles GET /scim/v2/Schemas
+func (sc *SCIMController) Schemas(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, sc.scimService.Schemas())
+}
+
+// userID parses the user ID from the path. Unknown IDs are reported as not found.
+func (sc *SCIMController) userID(c *gin.Context) (uint, bool) {
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		respondSCIM(c, http.StatusNotFound, scimErrorBody(http.StatusNotFound, "", service.ErrUserNotFound.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps SCIM service errors to SCIM error responses
+func (sc *SCIMController) respondError(c *gin.Context, err error) {
+	var scimErr *service.SCIMError
+	if errors.As(err, &scimErr) {
+		respondSCIM(c, scimErr.Status, scimErrorBody(scimErr.Status, scimErr.Type, scimErr.Detail))
+		return
+	}
+	respondSCIM(c, http.StatusInternalServerError, scimErrorBody(http.StatusInternalServerError, "", err.Error()))
+}
+
+// respondSCIM writes the body with the SCIM media type
+func respondSCIM(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", models.SCIMContentType)
+	c.JSON(status, body)
+}
+
+// scimErrorBody builds a SCIM error response body
+func scimErrorBody(status int, scimType, detail string) models.SCIMErrorResponse {
+	return models.SCIMErrorResponse{
+		Schemas:  []string{models.SCIMMessageError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionController handles session and device management HTTP requests
+type SessionController struct {
+	sessionService service.SessionService
+}
+
+// NewSessionController creates a new session controller
+func NewSessionController(sessionService service.SessionService) *SessionController {
+	return &SessionController{sessionService: sessionService}
+}
+
+// ListSessions handles GET /profile/sessions (protected route)
+// @Summary      List Sessions
+// @Description  List the devices the authenticated user is signed in on. The session of the current token is marked as current.
+// @Tags         Sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.SessionResponse
+// @Router       /profile/sessions [get]
+func (sc *SessionController) ListSessions(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	sessions, err := sc.sessionService.ListSessions(c.Request.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		sc.respondError(c, "list_sessions_failed", err)
+		return
+	}
+
+	utils.Success(c, sessions)
+}
+
+// RevokeSession handles DELETE /profile/sessions/:id (protected route)
+// @Summary      Revoke Session
+// @Description  Sign the authenticated user out on one device. Its access and refresh tokens stop working immediately.
+// @Tags         Sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Session ID"
+// @Success      200 {object} models.MessageResponse
+// @Router       /profile/sessions/{id} [delete]
+func (sc *SessionController) RevokeSession(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid session ID")
+		return
+	}
+
+	if err := sc.sessionService.RevokeSession(c.Request.Context(), userID, id); err != nil {
+		sc.respondError(c, "revoke_session_failed", err)
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Session revoked successfully")
+}
+
+// ListUserSessions handles GET /users/:id/sessions
+// @Summary      List User Sessions
+// @Description  List the devices a user is signed in on
+// @Tags         Sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Success      200 {array} models.SessionResponse
+// @Router       /users/{id}/sessions [get]
+func (sc *SessionController) ListUserSessions(c *gin.Context) {
+	userID, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid user ID")
+		return
+	}
+
+	sessions, err := sc.sessionService.ListSessions(c.Request.Context(), userID, 0)
+	if err != nil {
+		sc.respondError(c, "list_sessions_failed", err)
+		return
+	}
+
+	utils.Success(c, sessions)
+}
+
+// RevokeUserSession handles DELETE /users/:id/sessions/:session_id
+// @Summary      Revoke User Session
+// @Description  Sign a user out on one device
+// @Tags         Sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        session_id path int true "Session ID"
+// @Success      200 {object} models.MessageResponse
+// @Router       /users/{id}/sessions/{session_id} [delete]
+func (sc *SessionController) RevokeUserSession(c *gin.Context) {
+	userID, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid user ID")
+		return
+	}
+	sessionID, err := utils.StringToUint(c.Param("session_id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid session ID")
+		return
+	}
+
+	if err := sc.sessionService.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		sc.respondError(c, "revoke_session_failed", err)
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Session revoked successfully")
+}
+
+// respondError maps session service errors to HTTP responses
+func (sc *SessionController) respondError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		utils.NotFound(c, code, err.Error())
+	default:
+		utils.InternalServerError(c, code, err.Error())
+	}
+}
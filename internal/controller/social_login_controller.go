@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// socialStateCookie binds a social login to the browser that started it, preventing login CSRF
+const socialStateCookie = "social_login_state"
+
+// SocialLoginController handles social login HTTP requests
+type SocialLoginController struct {
+	socialLoginService service.SocialLoginService
+}
+
+// NewSocialLoginController creates a new social login controller
+func NewSocialLoginController(socialLoginService service.SocialLoginService) *SocialLoginController {
+	return &SocialLoginController{
+		socialLoginService: socialLoginService,
+	}
+}
+
+// Providers handles GET /auth/oauth/providers
+// @Summary      List Social Login Providers
+// @Description  List the enabled social login providers
+// @Tags         Social Login
+// @Produce      json
+// @Success      200 {object} models.SocialProvidersResponse
+// @Router       /auth/oauth/providers [get]
+func (sc *SocialLoginController) Providers(c *gin.Context) {
+	utils.Success(c, models.SocialProvidersResponse{Providers: sc.socialLoginService.Providers()})
+}
+
+// Redirect handles GET /auth/oauth/:provider
+// @Summary      Start Social Login
+// @Description  Redirect to the provider's sign-in page using the authorization code flow with PKCE
+// @Tags         Social Login
+// @Param        provider path string true "Provider name"
+// @Success      302
+// @Router       /auth/oauth/{provider} [get]
+func (sc *SocialLoginController) Redirect(c *gin.Context) {
+	authURL, state, err := sc.socialLoginService.AuthCodeURL(c.Param("provider"))
+	if err != nil {
+		sc.respondError(c, "social_login_failed", err)
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(socialStateCookie, state, 0, "/api/v1/auth/oauth", "", isSecureRequest(c), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /auth/oauth/:provider/callback
+// @Summary      Complete Social Login
+// @Description  Exchange the provider's authorization code for tokens. Accounts are linked by email only when the provider reports it as verified.
+// @Tags         Social Login
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Param        code query string true "Authorization code"
+// @Param        state query string true "State from the authorization request"
+// @Success      200 {object} models.LoginResponse
+// @Router       /auth/oauth/{provider}/callback [get]
+func (sc *SocialLoginController) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		utils.BadRequest(c, "social_login_denied", c.DefaultQuery("error_description", providerError))
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		utils.BadRequest(c, "invalid_request", "code and state are required")
+		return
+	}
+
+	cookie, err := c.Cookie(socialStateCookie)
+	c.SetCookie(socialStateCookie, "", -1, "/api/v1/auth/oauth", "", isSecureRequest(c), true)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		sc.respondError(c, "social_login_failed", service.ErrInvalidSocialLoginState)
+		return
+	}
+
+	loginResponse, err := sc.socialLoginService.Callback(c.Param("provider"), code, state, utils.GetClientInfo(c))
+	if err != nil {
+		sc.respondError(c, "social_login_failed", err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Login successful", loginResponse)
+}
+
+// respondError maps social login service errors to HTTP responses
+func (sc *SocialLoginController) respondError(c *gin.Context, code string, err error) {
+	var lockedErr *service.AccountLockedError
+	switch {
+	case errors.Is(err, service.ErrUnknownSocialProvider):
+		utils.NotFound(c, code, err.Error())
+	case errors.Is(err, service.ErrInvalidSocialLoginState),
+		errors.Is(err, service.ErrSocialLoginFailed),
+		errors.Is(err, service.ErrUserNotFound):
+		utils.RespondError(c, http.StatusUnauthorized, code, err.Error())
+	case errors.Is(err, service.ErrSocialEmailNotVerified):
+		utils.Forbidden(c, "email_not_verified", err.Error())
+	case errors.Is(err, service.ErrSocialDirectoryAccount):
+		utils.Forbidden(c, "directory_account", err.Error())
+	case errors.As(err, &lockedErr):
+		utils.RespondError(c, http.StatusLocked, "account_locked", err.Error())
+	case errors.Is(err, service.ErrAccountDeactivated):
+		utils.Forbidden(c, "account_deactivated", err.Error())
+	default:
+		utils.InternalServerError(c, code, err.Error())
+	}
+}
+
+// isSecureRequest reports whether the request reached us over HTTPS, directly or through a proxy
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
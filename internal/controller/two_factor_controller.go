@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// TwoFactorController handles two-factor authentication HTTP requests
+type TwoFactorController struct {
+	twoFactorService service.TwoFactorService
+	cookies          *utils.AuthCookies
+	validator        *validator.Validate
+}
+
+// NewTwoFactorController creates a new two-factor controller
+func NewTwoFactorController(twoFactorService service.TwoFactorService, cookies *utils.AuthCookies) *TwoFactorController {
+	return &TwoFactorController{
+		twoFactorService: twoFactorService,
+		cookies:          cookies,
+		validator:        validator.New(),
+	}
+}
+
+// Enroll handles POST /profile/2fa/enroll (protected route)
+// @Summary      Start 2FA Enrollment
+// @Description  Generate a TOTP secret and return it as an otpauth:// URI and QR code PNG. 2FA is enabled after confirmation.
+// @Tags         Two-Factor Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.TwoFactorEnrollResponse
+// @Router       /profile/2fa/enroll [post]
+func (tc *TwoFactorController) Enroll(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	response, err := tc.twoFactorService.Enroll(userID)
+	if err != nil {
+		tc.respondError(c, "enroll_failed", err)
+		return
+	}
+
+	utils.Success(c, response)
+}
+
+// Confirm handles POST /profile/2fa/confirm (protected route)
+// @Summary      Confirm 2FA Enrollment
+// @Description  Enable 2FA with a code from the authenticator app. Returns one-time recovery codes that are only shown once.
+// @Tags         Two-Factor Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.TwoFactorCodeRequest true "TOTP code"
+// @Success      200 {object} models.RecoveryCodesResponse
+// @Router       /profile/2fa/confirm [post]
+func (tc *TwoFactorController) Confirm(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := tc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	response, err := tc.twoFactorService.Confirm(userID, req.Code)
+	if err != nil {
+		tc.respondError(c, "confirm_failed", err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Two-factor authentication enabled", response)
+}
+
+// Disable handles POST /profile/2fa/disable (protected route)
+// @Summary      Disable 2FA
+// @Description  Disable 2FA with the account password and a TOTP or recovery code
+// @Tags         Two-Factor Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.TwoFactorDisableRequest true "Password and code"
+// @Success      200 {object} models.MessageResponse
+// @Router       /profile/2fa/disable [post]
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := tc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	if err := tc.twoFactorService.Disable(userID, req); err != nil {
+		tc.respondError(c, "disable_failed", err)
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "Two-factor authentication disabled")
+}
+
+// RegenerateRecoveryCodes handles POST /profile/2fa/recovery-codes (protected route)
+// @Summary      Regenerate Recovery Codes
+// @Description  Replace all recovery codes after checking a TOTP code
+// @Tags         Two-Factor Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.TwoFactorCodeRequest true "TOTP code"
+// @Success      200 {object} models.RecoveryCodesResponse
+// @Router       /profile/2fa/recovery-codes [post]
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := tc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	response, err := tc.twoFactorService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		tc.respondError(c, "regenerate_failed", err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Recovery codes regenerated", response)
+}
+
+// Verify handles POST /auth/2fa/verify
+// @Summary      Complete 2FA Login
+// @Description  Exchange the challenge token returned by login and a TOTP or recovery code for access and refresh tokens
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TwoFactorVerifyRequest true "Challenge token and code"
+// @Param        X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
+// @Success      200 {object} models.LoginResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      423 {object} models.ErrorResponse
+// @Router       /auth/2fa/verify [post]
+func (tc *TwoFactorController) Verify(c *gin.Context) {
+	var req models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := tc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	loginResponse, err := tc.twoFactorService.VerifyChallenge(req, utils.GetClientInfo(c))
+	if err != nil {
+		if respondAccountLocked(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidChallenge), errors.Is(err, service.ErrInvalidTwoFactorCode):
+			utils.RespondError(c, http.StatusUnauthorized, "login_failed", err.Error())
+		case errors.Is(err, service.ErrAccountDeactivated):
+			utils.Forbidden(c, "account_deactivated", err.Error())
+		default:
+			utils.InternalServerError(c, "login_failed", err.Error())
+		}
+		return
+	}
+
+	if tc.cookies.Requested(c) && !issueAuthCookies(c, tc.cookies, loginResponse) {
+		return
+	}
+
+	utils.SuccessMessage(c, "Login successful", loginResponse)
+}
+
+// respondError maps two-factor service errors to HTTP responses
+func (tc *TwoFactorController) respondError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled):
+		utils.Conflict(c, code, err.Error())
+	case errors.Is(err, service.ErrInvalidTwoFactorCode),
+		errors.Is(err, service.ErrIncorrectPassword):
+		utils.BadRequest(c, code, err.Error())
+	case errors.Is(err, service.ErrDirectoryUnavailable):
+		utils.RespondError(c, http.StatusServiceUnavailable, code, err.Error())
+	default:
+		utils.InternalServerError(c, code, err.Error())
+	}
+}
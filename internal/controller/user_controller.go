@@ -1,9 +1,14 @@
 package controller
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/policy"
 	"golang-starter-kit/internal/service"
 	"golang-starter-kit/utils"
 
@@ -13,15 +18,19 @@ import (
 
 // UserController handles user-related HTTP requests
 type UserController struct {
-	userService service.UserService
-	validator   *validator.Validate
+	userService          service.UserService
+	passwordService      service.PasswordService
+	authorizationService service.AuthorizationService
+	validator            *validator.Validate
 }
 
 // NewUserController creates a new user controller
-func NewUserController(userService service.UserService) *UserController {
+func NewUserController(userService service.UserService, passwordService service.PasswordService, authorizationService service.AuthorizationService) *UserController {
 	return &UserController{
-		userService: userService,
-		validator:   validator.New(),
+		userService:          userService,
+		passwordService:      passwordService,
+		authorizationService: authorizationService,
+		validator:            validator.New(),
 	}
 }
 
@@ -31,6 +40,8 @@ func NewUserController(userService service.UserService) *UserController {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        request body models.UserListRequest true "Pagination and filter parameters"
 // @Success      200 {object} models.UsersListResponse
 // @Router       /users/pagination [post]
@@ -57,7 +68,7 @@ func (uc *UserController) GetUsersWithPagination(c *gin.Context) {
 		req.Limit = 10
 	}
 
-	response, err := uc.userService.GetAllUsersWithFilter(req)
+	response, err := uc.userService.GetAllUsersWithFilter(c.Request.Context(), req)
 	if err != nil {
 		utils.InternalServerError(c, "search_failed", err.Error())
 		return
@@ -72,8 +83,11 @@ func (uc *UserController) GetUsersWithPagination(c *gin.Context) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        request body models.UserCreateRequest true "User data"
 // @Success      201 {object} models.UserResponse
+// @Failure      400 {object} models.PasswordPolicyErrorResponse
 // @Router       /users [post]
 func (uc *UserController) CreateUser(c *gin.Context) {
 	var req models.UserCreateRequest
@@ -88,8 +102,11 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.CreateUser(req)
+	user, err := uc.userService.CreateUser(c.Request.Context(), req)
 	if err != nil {
+		if respondPasswordPolicyError(c, err) {
+			return
+		}
 		utils.Conflict(c, "creation_failed", err.Error())
 		return
 	}
@@ -103,6 +120,8 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        id path int true "User ID"
 // @Success      200 {object} models.UserResponse
 // @Router       /users/{id} [get]
@@ -114,7 +133,7 @@ func (uc *UserController) GetUser(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.GetUserByID(id)
+	user, err := uc.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
 		utils.NotFound(c, "user_not_found", err.Error())
 		return
@@ -125,13 +144,17 @@ func (uc *UserController) GetUser(c *gin.Context) {
 
 // UpdateUser handles PUT /users/:id
 // @Summary      Update User
-// @Description  Update an existing user by ID
+// @Description  Update an existing user by ID. The authorization policy decides who may update whom; by default users can update themselves, holders of users:update anyone, and organization admins the names of members of their organization who hold no roles. Changing the email is checked as users:update_email, which organization admins are not allowed.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        id path int true "User ID"
 // @Param        request body models.UserUpdateRequest true "User update data"
 // @Success      200 {object} models.UserResponse
+// @Failure      403 {object} models.PolicyDeniedResponse
+// @Failure      404 {object} models.ErrorResponse
 // @Router       /users/{id} [put]
 func (uc *UserController) UpdateUser(c *gin.Context) {
 	idParam := c.Param("id")
@@ -156,7 +179,14 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.UpdateUser(id, req)
+	if !uc.authorize(c, models.PermissionUsersUpdate, id) {
+		return
+	}
+	if req.Email != "" && !uc.authorize(c, models.ActionUsersUpdateEmail, id) {
+		return
+	}
+
+	user, err := uc.userService.UpdateUser(c.Request.Context(), id, req)
 	if err != nil {
 		utils.BadRequest(c, "update_failed", err.Error())
 		return
@@ -167,12 +197,16 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 
 // DeleteUser handles DELETE /users/:id
 // @Summary      Delete User
-// @Description  Delete a user by ID
+// @Description  Delete a user by ID. The authorization policy decides who may delete whom; by default it takes users:delete, and the last admin cannot be deleted.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        id path int true "User ID"
 // @Success      200 {object} models.MessageResponse
+// @Failure      403 {object} models.PolicyDeniedResponse
+// @Failure      404 {object} models.ErrorResponse
 // @Router       /users/{id} [delete]
 func (uc *UserController) DeleteUser(c *gin.Context) {
 	idParam := c.Param("id")
@@ -182,7 +216,11 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := uc.userService.DeleteUser(id); err != nil {
+	if !uc.authorize(c, models.PermissionUsersDelete, id) {
+		return
+	}
+
+	if err := uc.userService.DeleteUser(c.Request.Context(), id); err != nil {
 		utils.NotFound(c, "delete_failed", err.Error())
 		return
 	}
@@ -190,6 +228,36 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 	utils.Message(c, http.StatusOK, "User deleted successfully")
 }
 
+// UnlockUser handles POST /users/:id/unlock
+// @Summary      Unlock User
+// @Description  Reset a user's failed login attempts and lift any account lockout
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id path int true "User ID"
+// @Success      200 {object} models.MessageResponse
+// @Router       /users/{id}/unlock [post]
+func (uc *UserController) UnlockUser(c *gin.Context) {
+	id, err := utils.StringToUint(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "invalid_id", "Invalid user ID")
+		return
+	}
+
+	if err := uc.userService.UnlockUser(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			utils.NotFound(c, "unlock_failed", err.Error())
+			return
+		}
+		utils.InternalServerError(c, "unlock_failed", err.Error())
+		return
+	}
+
+	utils.Message(c, http.StatusOK, "User unlocked successfully")
+}
+
 // GetProfile handles GET /profile (protected route)
 // @Summary      Get User Profile
 // @Description  Get the authenticated user's profile
@@ -197,6 +265,7 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Success      200 {object} models.UserResponse
 // @Router       /profile [get]
 func (uc *UserController) GetProfile(c *gin.Context) {
@@ -206,7 +275,7 @@ func (uc *UserController) GetProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.GetUserByID(userID)
+	user, err := uc.userService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
 		utils.NotFound(c, "user_not_found", err.Error())
 		return
@@ -244,7 +313,7 @@ func (uc *UserController) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.UpdateUser(userID, req)
+	user, err := uc.userService.UpdateUser(c.Request.Context(), userID, req)
 	if err != nil {
 		utils.BadRequest(c, "update_failed", err.Error())
 		return
@@ -252,3 +321,124 @@ func (uc *UserController) UpdateProfile(c *gin.Context) {
 
 	utils.SuccessMessage(c, "Profile updated successfully", user)
 }
+
+// ChangePassword handles PUT /profile/password (protected route)
+// @Summary      Change Password
+// @Description  Change the authenticated user's password. Optionally revokes every other session and returns new tokens for the caller. Wrong current passwords count towards the account lockout like failed logins. Users authenticated by an LDAP directory change their password there.
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.ChangePasswordRequest true "Current and new password"
+// @Success      200 {object} models.ChangePasswordResponse
+// @Failure      400 {object} models.PasswordPolicyErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      423 {object} models.ErrorResponse
+// @Router       /profile/password [put]
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	userID, exists := utils.GetUserIDFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidRequest(c, err)
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	response, err := uc.passwordService.ChangePassword(userID, req, utils.GetClientInfo(c))
+	if err != nil {
+		if respondPasswordPolicyError(c, err) || respondAccountLocked(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrIncorrectPassword):
+			utils.BadRequest(c, "invalid_current_password", err.Error())
+		case errors.Is(err, service.ErrPasswordUnchanged):
+			utils.BadRequest(c, "password_unchanged", err.Error())
+		case errors.Is(err, service.ErrDirectoryPassword):
+			utils.Forbidden(c, "password_managed_by_directory", err.Error())
+		default:
+			utils.InternalServerError(c, "change_password_failed", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessMessage(c, "Password changed successfully", response)
+}
+
+// respondPasswordPolicyError writes the policy violations when err rejects a
+// new password and reports whether it did
+func respondPasswordPolicyError(c *gin.Context, err error) bool {
+	var policyErr *service.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, models.PasswordPolicyErrorResponse{
+		Error:      "password_policy_violation",
+		Message:    service.ErrPasswordPolicy.Error(),
+		Violations: policyErr.Violations,
+	})
+	return true
+}
+
+// respondAccountLocked writes the 423 response with a Retry-After header when err
+// is an account lockout and reports whether it did
+func respondAccountLocked(c *gin.Context, err error) bool {
+	var lockedErr *service.AccountLockedError
+	if !errors.As(err, &lockedErr) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	utils.RespondError(c, http.StatusLocked, "account_locked", err.Error())
+	return true
+}
+
+// authorize checks the authorization policy for the action on the user and
+// writes the response when it may not proceed. It reports whether it may.
+func (uc *UserController) authorize(c *gin.Context, action string, userID uint) bool {
+	claims, exists := utils.GetClaimsFromContext(c)
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return false
+	}
+
+	err := uc.authorizationService.AuthorizeUser(c.Request.Context(), claims, action, userID)
+	switch {
+	case err == nil:
+		return true
+	case respondPolicyDenied(c, err):
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFound(c, "user_not_found", err.Error())
+	default:
+		utils.InternalServerError(c, "authorization_failed", err.Error())
+	}
+	return false
+}
+
+// respondPolicyDenied writes the 403 response when err is a policy denial and reports whether it did
+func respondPolicyDenied(c *gin.Context, err error) bool {
+	var denied *policy.DeniedError
+	if !errors.As(err, &denied) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, models.PolicyDeniedResponse{
+		Error:   "policy_denied",
+		Message: denied.Error(),
+		Action:  denied.Action,
+		Rule:    denied.Rule,
+	})
+	return true
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"net/http"
+
+	"golang-starter-kit/internal/service"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WellKnownController serves discovery documents under /.well-known
+type WellKnownController struct {
+	keys         *utils.KeySet
+	oauthService service.OAuthService
+}
+
+// NewWellKnownController creates a new well-known controller
+func NewWellKnownController(keys *utils.KeySet, oauthService service.OAuthService) *WellKnownController {
+	return &WellKnownController{
+		keys:         keys,
+		oauthService: oauthService,
+	}
+}
+
+// JWKS handles GET /.well-known/jwks.json
+// It publishes the public keys for verifying access tokens, matched by the kid
+// token header. The set is empty when tokens are signed with HS256.
+func (wc *WellKnownController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, wc.keys.JWKS())
+}
+
+// OpenIDConfiguration handles GET /.well-known/openid-configuration
+// It publishes the OpenID Provider metadata of the built-in authorization server.
+func (wc *WellKnownController) OpenIDConfiguration(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, wc.oauthService.Discovery())
+}
@@ -0,0 +1,290 @@
+// Package directorytest provides an in-process LDAP server for testing directory logins
+package directorytest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// entry is a directory entry with its password and attributes
+type entry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// Server is an LDAP server answering simple binds and searches from an in-memory
+// directory. Searches need an authenticated bind, and filters support and, or,
+// not, equality and presence; other filters match nothing.
+type Server struct {
+	// URL is the ldap:// URL of the server
+	URL string
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	conns   map[net.Conn]struct{}
+	closed  bool
+}
+
+// NewServer starts a server on a local port. Callers must Close it when done.
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("directorytest: failed to listen on a port: %v", err))
+	}
+
+	s := &Server{
+		URL:      "ldap://" + listener.Addr().String(),
+		listener: listener,
+		entries:  map[string]*entry{},
+		conns:    map[net.Conn]struct{}{},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// AddEntry adds or replaces the entry with the DN. Attribute names are case-insensitive.
+func (s *Server) AddEntry(dn, password string, attributes map[string][]string) {
+	normalized := make(map[string][]string, len(attributes))
+	for name, values := range attributes {
+		normalized[strings.ToLower(name)] = values
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[strings.ToLower(dn)] = &entry{dn: dn, password: password, attributes: normalized}
+}
+
+// RemoveEntry deletes the entry with the DN
+func (s *Server) RemoveEntry(dn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, strings.ToLower(dn))
+}
+
+// Close stops the server and closes open connections
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+// handle answers the requests of one connection until the client unbinds or disconnects
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	bound := false
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+
+		request := packet.Children[1]
+		var responses []*ber.Packet
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			var code uint16
+			code, bound = s.bind(request)
+			responses = append(responses, result(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			if !bound {
+				responses = append(responses, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights))
+				break
+			}
+			responses = s.search(request)
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			responses = append(responses, result(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError))
+		}
+
+		for _, response := range responses {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+			envelope.AppendChild(response)
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// bind checks a simple bind request: version, name, [0] password. Like many real
+// servers it accepts an empty password for any DN as an unauthenticated bind.
+func (s *Server) bind(request *ber.Packet) (code uint16, authenticated bool) {
+	if len(request.Children) < 3 {
+		return ldap.LDAPResultProtocolError, false
+	}
+	dn := request.Children[1].Data.String()
+	password := request.Children[2].Data.String()
+	if password == "" {
+		return ldap.LDAPResultSuccess, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[strings.ToLower(dn)]
+	if !ok || e.password != password {
+		return ldap.LDAPResultInvalidCredentials, false
+	}
+	return ldap.LDAPResultSuccess, true
+}
+
+// search answers a search request: base, scope, deref, size limit, time limit,
+// types only, filter, attributes. Every entry below the base is searched.
+func (s *Server) search(request *ber.Packet) []*ber.Packet {
+	if len(request.Children) < 8 {
+		return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError)}
+	}
+	base := strings.ToLower(request.Children[0].Data.String())
+	sizeLimit, _ := request.Children[3].Value.(int64)
+	filter := request.Children[6]
+	var requested []string
+	for _, attribute := range request.Children[7].Children {
+		requested = append(requested, strings.ToLower(attribute.Data.String()))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var responses []*ber.Packet
+	for key, e := range s.entries {
+		if key != base && !strings.HasSuffix(key, ","+base) {
+			continue
+		}
+		if !matches(filter, e.attributes) {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(responses)) == sizeLimit {
+			return append(responses, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded))
+		}
+		responses = append(responses, searchEntry(e.dn, e.attributes, requested))
+	}
+	return append(responses, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+}
+
+// matches evaluates a search filter against an entry's attributes. Values compare case-insensitively.
+func matches(filter *ber.Packet, attributes map[string][]string) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(child, attributes) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(child, attributes) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(filter.Children) == 1 && !matches(filter.Children[0], attributes)
+	case ldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		name := strings.ToLower(filter.Children[0].Data.String())
+		value := filter.Children[1].Data.String()
+		for _, v := range attributes[name] {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(attributes[strings.ToLower(filter.Data.String())]) > 0
+	}
+	return false
+}
+
+// searchEntry encodes an entry with the requested attributes, or all of them when none are requested
+func searchEntry(dn string, attributes map[string][]string, requested []string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "Object Name"))
+
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range attributes {
+		if len(requested) > 0 && !contains(requested, name) {
+			continue
+		}
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		list.AppendChild(attribute)
+	}
+	packet.AppendChild(list)
+	return packet
+}
+
+// result encodes an LDAPResult: result code, matched DN and diagnostic message
+func result(application ber.Tag, code uint16) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, application, nil, ldap.ApplicationMap[uint8(application)])
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, ldap.LDAPResultCodeMap[code], "Diagnostic Message"))
+	return packet
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
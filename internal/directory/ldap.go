@@ -0,0 +1,152 @@
+// Package directory authenticates users against an LDAP or Active Directory server
+package directory
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang-starter-kit/config"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	// ErrUserNotFound is returned when no directory entry matches the login
+	ErrUserNotFound = errors.New("directory user not found")
+	// ErrInvalidCredentials is returned when the directory rejects the user's password
+	ErrInvalidCredentials = errors.New("invalid directory credentials")
+)
+
+// Entry is the directory account of an authenticated user
+type Entry struct {
+	DN    string
+	Email string
+	Name  string
+}
+
+// LDAP authenticates users with a search-and-bind against an LDAP server
+type LDAP struct {
+	cfg       config.LDAPConfig
+	tlsConfig *tls.Config
+}
+
+// NewLDAP validates the configuration and creates an LDAP authenticator.
+// No connection is made until the first login.
+func NewLDAP(cfg config.LDAPConfig) (*LDAP, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("LDAP URL must use ldap:// or ldaps://, got %q", u.Scheme)
+	}
+	if cfg.BaseDN == "" {
+		return nil, errors.New("LDAP base DN is required")
+	}
+	if strings.Count(cfg.UserFilter, "%s") != 1 {
+		return nil, errors.New("LDAP user filter must contain %s exactly once")
+	}
+
+	return &LDAP{
+		cfg: cfg,
+		tlsConfig: &tls.Config{
+			ServerName:         u.Hostname(),
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+			MinVersion:         tls.VersionTLS12,
+		},
+	}, nil
+}
+
+// Authenticate binds with the service account, searches the entry matching the
+// login and checks the password by binding as that entry
+func (l *LDAP) Authenticate(login, password string) (*Entry, error) {
+	// An empty password would be an unauthenticated bind, which servers accept for any DN
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := l.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(l.cfg.BindDN, l.cfg.BindPassword); err != nil {
+		return nil, fmt.Errorf("LDAP service account bind failed: %w", err)
+	}
+
+	entry, err := l.search(conn, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP user bind failed: %w", err)
+	}
+	return entry, nil
+}
+
+// dial connects to the server, upgrading the connection with StartTLS when configured
+func (l *LDAP) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(l.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: l.cfg.Timeout}),
+		ldap.DialWithTLSConfig(l.tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(l.cfg.Timeout)
+
+	if l.cfg.StartTLS {
+		if err := conn.StartTLS(l.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP StartTLS failed: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// search finds the single entry matching the login
+func (l *LDAP) search(conn *ldap.Conn, login string) (*Entry, error) {
+	request := ldap.NewSearchRequest(
+		l.cfg.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2, // more than one match is ambiguous
+		int(l.cfg.Timeout.Seconds()),
+		false,
+		fmt.Sprintf(l.cfg.UserFilter, ldap.EscapeFilter(login)),
+		[]string{l.cfg.EmailAttribute, l.cfg.NameAttribute},
+		nil,
+	)
+
+	result, err := conn.Search(request)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, fmt.Errorf("LDAP user filter matches more than one entry for %q", login)
+		}
+		return nil, fmt.Errorf("LDAP search failed: %w", err)
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrUserNotFound
+	case 1:
+	default:
+		return nil, fmt.Errorf("LDAP user filter matches more than one entry for %q", login)
+	}
+
+	entry := result.Entries[0]
+	return &Entry{
+		DN:    entry.DN,
+		Email: entry.GetAttributeValue(l.cfg.EmailAttribute),
+		Name:  entry.GetAttributeValue(l.cfg.NameAttribute),
+	}, nil
+}
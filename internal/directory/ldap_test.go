@@ -0,0 +1,171 @@
+package directory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/directory/directorytest"
+)
+
+const (
+	testBindDN       = "cn=service,ou=apps,dc=example,dc=com"
+	testBindPassword = "service secret"
+	testJaneDN       = "uid=jane,ou=people,dc=example,dc=com"
+)
+
+// testConfig is the default configuration pointed at the test server
+func testConfig(url string) config.LDAPConfig {
+	return config.LDAPConfig{
+		URL:            url,
+		BindDN:         testBindDN,
+		BindPassword:   testBindPassword,
+		BaseDN:         "ou=people,dc=example,dc=com",
+		UserFilter:     "(&(objectClass=person)(mail=%s))",
+		EmailAttribute: "mail",
+		NameAttribute:  "cn",
+		Timeout:        5 * time.Second,
+	}
+}
+
+// newTestDirectory starts a server holding the service account and Jane
+func newTestDirectory(t *testing.T) (*directorytest.Server, *LDAP) {
+	t.Helper()
+
+	server := directorytest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddEntry(testBindDN, testBindPassword, map[string][]string{"objectClass": {"applicationProcess"}})
+	server.AddEntry(testJaneDN, "jane secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"Jane@Example.com"},
+		"cn":          {"Jane Doe"},
+	})
+
+	l, err := NewLDAP(testConfig(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, l
+}
+
+func TestNewLDAPValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*config.LDAPConfig)
+	}{
+		{"invalid URL", func(c *config.LDAPConfig) { c.URL = "ldap://[::1" }},
+		{"unsupported scheme", func(c *config.LDAPConfig) { c.URL = "http://ldap.example.com" }},
+		{"no base DN", func(c *config.LDAPConfig) { c.BaseDN = "" }},
+		{"filter without placeholder", func(c *config.LDAPConfig) { c.UserFilter = "(objectClass=person)" }},
+		{"filter with two placeholders", func(c *config.LDAPConfig) { c.UserFilter = "(|(mail=%s)(uid=%s))" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig("ldaps://ldap.example.com")
+			tt.modify(&cfg)
+			if _, err := NewLDAP(cfg); err == nil {
+				t.Fatal("NewLDAP accepted an invalid configuration")
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	_, l := newTestDirectory(t)
+
+	entry, err := l.Authenticate("jane@example.com", "jane secret")
+	if err != nil {
+		t.Fatalf("Authenticate returned error: %v", err)
+	}
+	want := Entry{DN: testJaneDN, Email: "Jane@Example.com", Name: "Jane Doe"}
+	if *entry != want {
+		t.Errorf("Authenticate = %+v, want %+v", *entry, want)
+	}
+}
+
+func TestAuthenticateRejectsWrongPassword(t *testing.T) {
+	_, l := newTestDirectory(t)
+
+	if _, err := l.Authenticate("jane@example.com", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestAuthenticateRejectsEmptyPassword(t *testing.T) {
+	// The server accepts an empty password as an unauthenticated bind for any DN
+	_, l := newTestDirectory(t)
+
+	if _, err := l.Authenticate("jane@example.com", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestAuthenticateUnknownUser(t *testing.T) {
+	_, l := newTestDirectory(t)
+
+	if _, err := l.Authenticate("john@example.com", "secret"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Authenticate = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestAuthenticateEscapesLogin(t *testing.T) {
+	// Unescaped, the login would turn the filter into (mail=*) and match Jane
+	_, l := newTestDirectory(t)
+
+	if _, err := l.Authenticate("*", "jane secret"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Authenticate = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestAuthenticateRejectsAmbiguousFilter(t *testing.T) {
+	server, l := newTestDirectory(t)
+	server.AddEntry("uid=jane2,ou=people,dc=example,dc=com", "jane secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"jane@example.com"},
+	})
+
+	_, err := l.Authenticate("jane@example.com", "jane secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Authenticate = %v, want a configuration error", err)
+	}
+}
+
+func TestAuthenticateSearchesBelowBaseDN(t *testing.T) {
+	server, l := newTestDirectory(t)
+	server.AddEntry("uid=john,ou=contractors,dc=example,dc=com", "john secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"john@example.com"},
+	})
+
+	if _, err := l.Authenticate("john@example.com", "john secret"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Authenticate = %v, want ErrUserNotFound for an entry outside the base DN", err)
+	}
+}
+
+func TestAuthenticateServiceAccountFailure(t *testing.T) {
+	server, _ := newTestDirectory(t)
+	cfg := testConfig(server.URL)
+	cfg.BindPassword = "wrong"
+	l, err := NewLDAP(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A broken service account is an outage, not a wrong user password
+	_, err = l.Authenticate("jane@example.com", "jane secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate = %v, want a service account error", err)
+	}
+}
+
+func TestAuthenticateServerDown(t *testing.T) {
+	server, l := newTestDirectory(t)
+	server.Close()
+
+	_, err := l.Authenticate("jane@example.com", "jane secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Authenticate = %v, want a connection error", err)
+	}
+}
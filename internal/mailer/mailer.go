@@ -0,0 +1,114 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+)
+
+// Message represents an email message
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer interface defines how emails are delivered
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewMailer creates a mailer for the configured driver
+func NewMailer(cfg config.MailConfig) Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "file":
+		return NewFileMailer(cfg)
+	default:
+		return NewLogMailer()
+	}
+}
+
+// logMailer writes emails to the application log, useful for local development
+type logMailer struct{}
+
+// NewLogMailer creates a mailer that logs messages instead of sending them
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+// Send logs the message
+func (m *logMailer) Send(msg Message) error {
+	log.Printf("[mailer] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// fileMailer writes each email to its own file, useful for local development
+// when messages should be opened rather than read from the log
+type fileMailer struct {
+	cfg config.MailConfig
+}
+
+// NewFileMailer creates a mailer that writes messages to files in cfg.FileDir
+func NewFileMailer(cfg config.MailConfig) Mailer {
+	return &fileMailer{cfg: cfg}
+}
+
+// Send writes the message to a new .eml file
+func (m *fileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.cfg.FileDir, 0o700); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(m.cfg.FileDir, time.Now().Format("20060102-150405")+"-*.eml")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(formatMessage(m.cfg.From, msg)); err != nil {
+		return err
+	}
+	log.Printf("[mailer] to=%s subject=%q written to %s", msg.To, msg.Subject, file.Name())
+	return nil
+}
+
+// smtpMailer delivers emails through an SMTP server
+type smtpMailer struct {
+	cfg config.MailConfig
+}
+
+// NewSMTPMailer creates a mailer that sends messages through SMTP
+func NewSMTPMailer(cfg config.MailConfig) Mailer {
+	return &smtpMailer{cfg: cfg}
+}
+
+// Send sends the message through the configured SMTP server
+func (m *smtpMailer) Send(msg Message) error {
+	addr := fmt.Sprintf("%s:%s", m.cfg.Host, m.cfg.Port)
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	return smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, []byte(formatMessage(m.cfg.From, msg)))
+}
+
+// formatMessage renders the message as a plain text email with headers
+func formatMessage(from string, msg Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return b.String()
+}
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"golang-starter-kit/internal/models"
 	"golang-starter-kit/utils"
@@ -10,12 +12,46 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware creates a middleware function for JWT authentication
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// TokenValidator validates access tokens, including token and session revocation checks
+type TokenValidator interface {
+	ValidateAccessToken(token string) (*utils.JWTClaims, error)
+}
+
+// APIKeyValidator resolves API keys to the claims of their owner
+type APIKeyValidator interface {
+	ValidateAPIKey(key string) (*utils.JWTClaims, error)
+}
+
+// AuthMiddleware creates a middleware function for JWT authentication.
+// Requests may instead carry an API key in the X-API-Key header or, in cookie
+// mode, the access token cookie. Cookie-authenticated requests that change
+// state must pass the CSRF check.
+func AuthMiddleware(tokenValidator TokenValidator, apiKeyValidator APIKeyValidator, cookies *utils.AuthCookies) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			claims, err := apiKeyValidator.ValidateAPIKey(apiKey)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Error:   "unauthorized",
+					Message: "Invalid or expired API key",
+				})
+				c.Abort()
+				return
+			}
+
+			setClaims(c, claims)
+			c.Next()
+			return
+		}
+
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
+			if token := cookies.AccessToken(c); token != "" {
+				authenticateCookie(c, tokenValidator, cookies, token)
+				return
+			}
+
 			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "unauthorized",
 				Message: "Authorization header is required",
@@ -45,9 +81,9 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		// Validate the token
-		claims, err := utils.ValidateToken(tokenString, jwtSecret)
-		if err != nil {
+		// Validate the token. Client credentials tokens do not belong to a user.
+		claims, err := tokenValidator.ValidateAccessToken(tokenString)
+		if err != nil || claims.UserID == 0 {
 			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "unauthorized",
 				Message: "Invalid or expired token",
@@ -56,10 +92,96 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// RequireRecentAuth creates a middleware for sensitive actions that only lets
+// through tokens whose user entered their credentials within maxAge (the
+// auth_time claim). Other requests get 403 reauthentication_required so the
+// client can ask for the password at /auth/reauthenticate and retry with the
+// new token. It must run after AuthMiddleware.
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := utils.GetClaimsFromContext(c)
+		if !exists {
+			utils.Unauthorized(c, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		if !claims.AuthenticatedWithin(maxAge) {
+			utils.Forbidden(c, "reauthentication_required", "Please confirm your password to continue")
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
+
+// authenticateCookie authenticates a request with the access token cookie
+func authenticateCookie(c *gin.Context, tokenValidator TokenValidator, cookies *utils.AuthCookies, token string) {
+	claims, err := tokenValidator.ValidateAccessToken(token)
+	if err != nil || claims.UserID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "Invalid or expired token",
+		})
+		c.Abort()
+		return
+	}
+
+	// Browsers attach cookies to cross-site requests; only our pages can read the CSRF cookie
+	if !utils.IsSafeMethod(c.Request.Method) && !cookies.ValidCSRF(c) {
+		utils.Forbidden(c, "csrf_token_invalid", "Missing or invalid CSRF token")
+		c.Abort()
+		return
+	}
+
+	setClaims(c, claims)
+	c.Next()
+}
+
+// RequireUserToken creates a middleware that rejects API keys and tokens issued to
+// OAuth clients. Use it on routes that manage credentials. It must run after AuthMiddleware.
+func RequireUserToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, exists := utils.GetClaimsFromContext(c); exists && claims.IsScoped() {
+			utils.Forbidden(c, "user_token_required", "This action requires a user access token")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DenyImpersonation creates a middleware that rejects impersonation tokens. Use it
+// on sensitive routes such as password and credential changes. It must run after AuthMiddleware.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, exists := utils.GetClaimsFromContext(c); exists && claims.IsImpersonated() {
+			utils.Forbidden(c, "impersonation_forbidden", "This action is not allowed while impersonating a user")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setClaims sets user information in context. Requests made with an
+// impersonation token also carry the impersonator and are logged.
+func setClaims(c *gin.Context, claims *utils.JWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("token_claims", claims)
+
+	if claims.IsImpersonated() {
+		c.Set("impersonator_id", claims.Actor.UserID)
+		log.Printf("[impersonation] user %d acting as user %d: %s %s",
+			claims.Actor.UserID, claims.UserID, c.Request.Method, c.Request.URL.Path)
+	}
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeTokenValidator accepts the "valid" token as user 1 and the
+// "impersonated" token as user 1 acted on by user 2
+type fakeTokenValidator struct{}
+
+func (fakeTokenValidator) ValidateAccessToken(token string) (*utils.JWTClaims, error) {
+	switch token {
+	case "valid":
+		return &utils.JWTClaims{UserID: 1}, nil
+	case "impersonated":
+		return &utils.JWTClaims{UserID: 1, Actor: &utils.ActorClaims{Subject: "2", UserID: 2}}, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+// fakeAPIKeyValidator accepts no API key
+type fakeAPIKeyValidator struct{}
+
+func (fakeAPIKeyValidator) ValidateAPIKey(key string) (*utils.JWTClaims, error) {
+	return nil, errors.New("invalid API key")
+}
+
+// authRequest is a request through AuthMiddleware in cookie mode
+type authRequest struct {
+	method        string
+	authorization string
+	accessCookie  string
+	csrfCookie    string
+	csrfHeader    string
+}
+
+// serve runs the request and reports the status and error code
+func (r authRequest) serve(t *testing.T) (int, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cookies := utils.NewAuthCookies(config.CookieConfig{
+		Enabled:    true,
+		AccessName: "access_token",
+		CSRFName:   "csrf_token",
+		CSRFHeader: "X-CSRF-Token",
+	})
+	router := gin.New()
+	router.Handle(r.method, "/users/me", AuthMiddleware(fakeTokenValidator{}, fakeAPIKeyValidator{}, cookies), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(r.method, "/users/me", nil)
+	if r.authorization != "" {
+		req.Header.Set("Authorization", r.authorization)
+	}
+	if r.accessCookie != "" {
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: r.accessCookie})
+	}
+	if r.csrfCookie != "" {
+		req.AddCookie(&http.Cookie{Name: "csrf_token", Value: r.csrfCookie})
+	}
+	if r.csrfHeader != "" {
+		req.Header.Set("X-CSRF-Token", r.csrfHeader)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body models.ErrorResponse
+	if w.Code != http.StatusOK {
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+	}
+	return w.Code, body.Error
+}
+
+func TestAuthMiddlewareCSRF(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        authRequest
+		wantStatus int
+		wantCode   string
+	}{
+		{"cookie with matching token", authRequest{method: http.MethodPost, accessCookie: "valid", csrfCookie: "csrf", csrfHeader: "csrf"}, http.StatusOK, ""},
+		{"cookie without token", authRequest{method: http.MethodPost, accessCookie: "valid", csrfCookie: "csrf"}, http.StatusForbidden, "csrf_token_invalid"},
+		{"cookie without CSRF cookie", authRequest{method: http.MethodDelete, accessCookie: "valid", csrfHeader: "csrf"}, http.StatusForbidden, "csrf_token_invalid"},
+		{"cookie with mismatched token", authRequest{method: http.MethodPatch, accessCookie: "valid", csrfCookie: "csrf", csrfHeader: "forged"}, http.StatusForbidden, "csrf_token_invalid"},
+		{"cookie on a safe method", authRequest{method: http.MethodGet, accessCookie: "valid"}, http.StatusOK, ""},
+		{"invalid cookie", authRequest{method: http.MethodPost, accessCookie: "expired", csrfCookie: "csrf", csrfHeader: "csrf"}, http.StatusUnauthorized, "unauthorized"},
+		{"bearer header skips the check", authRequest{method: http.MethodPost, authorization: "Bearer valid"}, http.StatusOK, ""},
+		{"bearer header next to a cookie", authRequest{method: http.MethodPut, authorization: "Bearer valid", accessCookie: "valid", csrfCookie: "csrf"}, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := tt.req.serve(t)
+			if status != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", status, code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRequireRecentAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticatedAgo := func(d time.Duration) *utils.JWTClaims {
+		return &utils.JWTClaims{UserID: 1, AuthTime: jwt.NewNumericDate(time.Now().Add(-d))}
+	}
+
+	tests := []struct {
+		name       string
+		claims     *utils.JWTClaims
+		wantStatus int
+		wantCode   string
+	}{
+		{"recent login", authenticatedAgo(time.Minute), http.StatusOK, ""},
+		{"login too long ago", authenticatedAgo(time.Hour), http.StatusForbidden, "reauthentication_required"},
+		{"token without auth_time", &utils.JWTClaims{UserID: 1}, http.StatusForbidden, "reauthentication_required"},
+		{"not authenticated", nil, http.StatusUnauthorized, "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.DELETE("/users/2", func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set("token_claims", tt.claims)
+				}
+			}, RequireRecentAuth(15*time.Minute), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/2", nil))
+
+			var body models.ErrorResponse
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			if w.Code != tt.wantStatus || body.Error != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", w.Code, body.Error, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDenyImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		token            string
+		wantStatus       int
+		wantCode         string
+		wantImpersonator any
+	}{
+		{"ordinary token", "valid", http.StatusOK, "", nil},
+		{"impersonation token", "impersonated", http.StatusForbidden, "impersonation_forbidden", uint(2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var impersonator any
+			router := gin.New()
+			router.PUT("/users/me/password", AuthMiddleware(fakeTokenValidator{}, fakeAPIKeyValidator{}, nil), func(c *gin.Context) {
+				impersonator, _ = c.Get("impersonator_id")
+			}, DenyImpersonation(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/users/me/password", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var body models.ErrorResponse
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			if w.Code != tt.wantStatus || body.Error != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", w.Code, body.Error, tt.wantStatus, tt.wantCode)
+			}
+			if impersonator != tt.wantImpersonator {
+				t.Errorf("impersonator_id = %v, want %v", impersonator, tt.wantImpersonator)
+			}
+		})
+	}
+}
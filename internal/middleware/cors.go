@@ -2,18 +2,28 @@ package middleware
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
+	"golang-starter-kit/config"
+
 	"github.com/gin-gonic/gin"
 )
 
-// CORS middleware for handling Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
+// CORS middleware for handling Cross-Origin Resource Sharing.
+// Allowed origins are echoed back with credentials allowed, since browsers refuse
+// credentialed responses for "*". Without allowed origins no CORS headers are
+// sent, so browsers only let pages of the API's own origin read its responses.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		origin := c.GetHeader("Origin")
+		c.Writer.Header().Add("Vary", "Origin")
+		if origin != "" && slices.Contains(cfg.AllowedOrigins, origin) {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+			c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Auth-Mode, X-Organization, Authorization, accept, origin, Cache-Control, X-Requested-With")
+			c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
+		}
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
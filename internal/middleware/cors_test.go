@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	allowList := config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}
+
+	tests := []struct {
+		name       string
+		cfg        config.CORSConfig
+		origin     string
+		wantOrigin string
+	}{
+		{"allowed origin", allowList, "https://app.example.com", "https://app.example.com"},
+		{"other origin", allowList, "https://evil.example.com", ""},
+		{"no origin", allowList, "", ""},
+		{"no allow-list", config.CORSConfig{}, "https://app.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CORS(tt.cfg))
+			router.PATCH("/users/1", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			for _, method := range []string{http.MethodOptions, http.MethodPatch} {
+				req := httptest.NewRequest(method, "/users/1", nil)
+				if tt.origin != "" {
+					req.Header.Set("Origin", tt.origin)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				header := w.Header()
+				if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+					t.Errorf("%s: Access-Control-Allow-Origin = %q, want %q", method, got, tt.wantOrigin)
+				}
+				if header.Get("Vary") != "Origin" {
+					t.Errorf("%s: Vary = %q, want Origin", method, header.Get("Vary"))
+				}
+
+				allowed := tt.wantOrigin != ""
+				if got := header.Get("Access-Control-Allow-Credentials") == "true"; got != allowed {
+					t.Errorf("%s: credentials allowed = %v, want %v", method, got, allowed)
+				}
+				if got := strings.Contains(header.Get("Access-Control-Allow-Methods"), http.MethodPatch); got != allowed {
+					t.Errorf("%s: PATCH allowed = %v, want %v", method, got, allowed)
+				}
+			}
+		})
+	}
+}
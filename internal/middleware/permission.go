@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker resolves whether a set of roles grants a permission
+type PermissionChecker interface {
+	HasPermission(roles []string, permission string) (bool, error)
+}
+
+// RequirePermission creates a middleware that only lets through users whose
+// roles grant the permission. API keys and OAuth client tokens must also carry
+// the permission as a scope.
+// It must run after AuthMiddleware.
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := utils.GetClaimsFromContext(c)
+		if !exists {
+			utils.Unauthorized(c, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		if claims.IsScoped() && !claims.HasScope(permission) {
+			utils.Forbidden(c, "insufficient_scope", "The credentials do not have the required scope")
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.HasPermission(claims.Roles, permission)
+		if err != nil {
+			utils.InternalServerError(c, "permission_check_failed", err.Error())
+			c.Abort()
+			return
+		}
+		if !allowed {
+			utils.Forbidden(c, "forbidden", "You do not have permission to perform this action")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -16,7 +16,7 @@ type RateLimitData struct {
 	LastReset time.Time
 }
 
-// RateLimiter manages rate limiting for login attempts
+// RateLimiter manages rate limiting for sensitive endpoints such as login
 type RateLimiter struct {
 	mu          sync.Mutex
 	limits      map[string]*RateLimitData
@@ -58,7 +58,7 @@ func (rl *RateLimiter) IsAllowed(ip string) bool {
 	return true
 }
 
-// RateLimitMiddleware creates a rate limiting middleware for login
+// RateLimitMiddleware creates a per-IP rate limiting middleware
 func RateLimitMiddleware(maxAttempts int, window time.Duration) gin.HandlerFunc {
 	limiter := NewRateLimiter(maxAttempts, window)
 
@@ -68,7 +68,7 @@ func RateLimitMiddleware(maxAttempts int, window time.Duration) gin.HandlerFunc
 		if !limiter.IsAllowed(ip) {
 			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
 				Error:   "rate_limit_exceeded",
-				Message: "Too many login attempts. Please try again later.",
+				Message: "Too many attempts. Please try again later.",
 			})
 			c.Abort()
 			return
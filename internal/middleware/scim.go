@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SCIMAuth creates a middleware that authenticates SCIM provisioning requests
+// with the configured bearer token and scopes them to the token's organization.
+// User tokens and API keys are not accepted, and every request is refused while
+// no token or organization is configured.
+func SCIMAuth(cfg config.SCIMConfig) gin.HandlerFunc {
+	expected := sha256.Sum256([]byte(cfg.Token))
+
+	return func(c *gin.Context) {
+		provided, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		actual := sha256.Sum256([]byte(provided))
+		if cfg.Token == "" || cfg.OrganizationID == 0 || !found || subtle.ConstantTimeCompare(expected[:], actual[:]) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="SCIM"`)
+			c.Header("Content-Type", models.SCIMContentType)
+			c.JSON(http.StatusUnauthorized, models.SCIMErrorResponse{
+				Schemas: []string{models.SCIMMessageError},
+				Status:  strconv.Itoa(http.StatusUnauthorized),
+				Detail:  "Invalid or missing SCIM bearer token",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("organization_id", cfg.OrganizationID)
+		c.Request = c.Request.WithContext(tenant.WithOrganization(c.Request.Context(), cfg.OrganizationID))
+		c.Next()
+	}
+}
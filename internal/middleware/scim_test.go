@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSCIMAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configured := config.SCIMConfig{Token: "scim secret", OrganizationID: 7}
+
+	tests := []struct {
+		name          string
+		cfg           config.SCIMConfig
+		authorization string
+		wantStatus    int
+	}{
+		{"valid token", configured, "Bearer scim secret", http.StatusOK},
+		{"wrong token", configured, "Bearer other secret", http.StatusUnauthorized},
+		{"no token", configured, "", http.StatusUnauthorized},
+		{"not a bearer token", configured, "scim secret", http.StatusUnauthorized},
+		{"SCIM disabled", config.SCIMConfig{OrganizationID: 7}, "Bearer ", http.StatusUnauthorized},
+		{"no organization configured", config.SCIMConfig{Token: "scim secret"}, "Bearer scim secret", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/scim/v2/Users", SCIMAuth(tt.cfg), func(c *gin.Context) {
+				if id, ok := tenant.OrganizationID(c.Request.Context()); !ok || id != tt.cfg.OrganizationID {
+					t.Errorf("request scoped to organization %d, want %d", id, tt.cfg.OrganizationID)
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/tenant"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantResolver resolves an organization ID or slug and checks the user's membership
+type TenantResolver interface {
+	ResolveTenant(ref string, userID uint) (organizationID uint, member bool, err error)
+	MemberOrganizations(userID uint) ([]uint, error)
+}
+
+// TenantMiddleware creates a middleware that resolves the organization a request
+// selects, from the tenant header, a subdomain of the base domain or the token's
+// org claim, in that order. Only impersonation tokens started within an
+// organization carry the claim; logins and refreshes never set it. The user must
+// be a member of the organization. Database queries made with the request context
+// are then scoped to it. Requests that select none are refused when a tenant is
+// required; otherwise they are scoped to the user's only organization, and refused
+// when the user belongs to several or none. No request is let through unscoped.
+// It must run after AuthMiddleware.
+func TenantMiddleware(resolver TenantResolver, cfg config.TenantConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := utils.GetClaimsFromContext(c)
+		if !exists {
+			utils.Unauthorized(c, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		ref := c.GetHeader(cfg.Header)
+		if ref == "" {
+			ref = subdomain(c.Request.Host, cfg.BaseDomain)
+		}
+		if ref == "" && claims.OrganizationID != 0 {
+			ref = strconv.FormatUint(uint64(claims.OrganizationID), 10)
+		}
+		var organizationID uint
+		if ref == "" {
+			if cfg.Required {
+				utils.RespondError(c, http.StatusBadRequest, "organization_required", "Select an organization with the "+cfg.Header+" header")
+				c.Abort()
+				return
+			}
+
+			organizations, err := resolver.MemberOrganizations(claims.UserID)
+			if err != nil {
+				utils.InternalServerError(c, "tenant_resolution_failed", err.Error())
+				c.Abort()
+				return
+			}
+			switch len(organizations) {
+			case 0:
+				utils.Forbidden(c, "organization_forbidden", "You are not a member of any organization")
+				c.Abort()
+				return
+			case 1:
+				organizationID = organizations[0]
+			default:
+				utils.RespondError(c, http.StatusBadRequest, "organization_required", "You belong to several organizations; select one with the "+cfg.Header+" header")
+				c.Abort()
+				return
+			}
+		} else {
+			id, member, err := resolver.ResolveTenant(ref, claims.UserID)
+			if err != nil {
+				utils.InternalServerError(c, "tenant_resolution_failed", err.Error())
+				c.Abort()
+				return
+			}
+			// Tokens bound to an organization cannot be used in another one
+			if !member || (claims.OrganizationID != 0 && claims.OrganizationID != id) {
+				utils.Forbidden(c, "organization_forbidden", "You are not a member of this organization")
+				c.Abort()
+				return
+			}
+			organizationID = id
+		}
+
+		c.Set("organization_id", organizationID)
+		c.Request = c.Request.WithContext(tenant.WithOrganization(c.Request.Context(), organizationID))
+		c.Next()
+	}
+}
+
+// subdomain returns the first label of host when host is a direct subdomain of baseDomain
+func subdomain(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !found || label == "" || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/tenant"
+	"golang-starter-kit/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeTenantResolver knows organizations by ID and slug and which users belong to them
+type fakeTenantResolver struct {
+	refs    map[string]uint
+	members map[uint][]uint
+}
+
+func (r fakeTenantResolver) ResolveTenant(ref string, userID uint) (uint, bool, error) {
+	id, ok := r.refs[ref]
+	if !ok {
+		return 0, false, nil
+	}
+	for _, organizationID := range r.members[userID] {
+		if organizationID == id {
+			return id, true, nil
+		}
+	}
+	return id, false, nil
+}
+
+func (r fakeTenantResolver) MemberOrganizations(userID uint) ([]uint, error) {
+	return r.members[userID], nil
+}
+
+// tenantRequest runs a request of the user through the tenant middleware and
+// reports the status, error code and the organization the request was scoped to
+func tenantRequest(t *testing.T, cfg config.TenantConfig, claims *utils.JWTClaims, header string) (int, string, uint) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	resolver := fakeTenantResolver{
+		refs:    map[string]uint{"1": 1, "acme": 1, "2": 2, "globex": 2},
+		members: map[uint][]uint{1: {1}, 2: {1, 2}},
+	}
+
+	var scoped uint
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("token_claims", claims)
+	})
+	router.GET("/users", TenantMiddleware(resolver, cfg), func(c *gin.Context) {
+		id, ok := tenant.OrganizationID(c.Request.Context())
+		if !ok {
+			t.Error("the request context was not scoped")
+		}
+		if fromGin, _ := utils.GetOrganizationIDFromContext(c); fromGin != id {
+			t.Errorf("organization_id = %d, request context has %d", fromGin, id)
+		}
+		scoped = id
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	if header != "" {
+		req.Header.Set(cfg.Header, header)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body models.ErrorResponse
+	if w.Code != http.StatusOK {
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+	}
+	return w.Code, body.Error, scoped
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	required := config.TenantConfig{Header: "X-Organization", Required: true}
+	optional := config.TenantConfig{Header: "X-Organization"}
+
+	tests := []struct {
+		name       string
+		cfg        config.TenantConfig
+		claims     *utils.JWTClaims
+		header     string
+		wantStatus int
+		wantCode   string
+		wantOrg    uint
+	}{
+		{"member selects by slug", required, &utils.JWTClaims{UserID: 2}, "globex", http.StatusOK, "", 2},
+		{"member selects by ID", required, &utils.JWTClaims{UserID: 2}, "1", http.StatusOK, "", 1},
+		{"non-member", required, &utils.JWTClaims{UserID: 1}, "globex", http.StatusForbidden, "organization_forbidden", 0},
+		{"unknown organization", required, &utils.JWTClaims{UserID: 1}, "initech", http.StatusForbidden, "organization_forbidden", 0},
+		{"org claim", required, &utils.JWTClaims{UserID: 2, OrganizationID: 2}, "", http.StatusOK, "", 2},
+		{"org claim selecting another organization", required, &utils.JWTClaims{UserID: 2, OrganizationID: 2}, "acme", http.StatusForbidden, "organization_forbidden", 0},
+		{"none selected when required", required, &utils.JWTClaims{UserID: 1}, "", http.StatusBadRequest, "organization_required", 0},
+		{"none selected, one membership", optional, &utils.JWTClaims{UserID: 1}, "", http.StatusOK, "", 1},
+		{"none selected, several memberships", optional, &utils.JWTClaims{UserID: 2}, "", http.StatusBadRequest, "organization_required", 0},
+		{"none selected, no membership", optional, &utils.JWTClaims{UserID: 3}, "", http.StatusForbidden, "organization_forbidden", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code, org := tenantRequest(t, tt.cfg, tt.claims, tt.header)
+			if status != tt.wantStatus || code != tt.wantCode {
+				t.Fatalf("got %d %q, want %d %q", status, code, tt.wantStatus, tt.wantCode)
+			}
+			if org != tt.wantOrg {
+				t.Errorf("request scoped to organization %d, want %d", org, tt.wantOrg)
+			}
+		})
+	}
+}
+
+func TestTenantMiddlewareSubdomain(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"acme.example.com", "acme"},
+		{"ACME.Example.com:8080", "acme"},
+		{"example.com", ""},
+		{"a.acme.example.com", ""},
+		{"acme.example.org", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := subdomain(tt.host, "example.com"); got != tt.want {
+				t.Errorf("subdomain(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// APIKey represents a long-lived credential for machine clients.
+// Only the hash of the key is stored; the prefix is kept so users can tell keys apart.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes     string     `json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the permissions the key is limited to
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// IsExpired reports whether the key has expired at the given time
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(now)
+}
+
+// TenantCondition limits API keys to those of members of the organization
+func (APIKey) TenantCondition(organizationID uint) clause.Expression {
+	return clause.Expr{
+		SQL: "? IN (SELECT user_id FROM organization_members WHERE organization_id = ?)",
+		Vars: []interface{}{
+			clause.Column{Table: clause.CurrentTable, Name: "user_id"},
+			organizationID,
+		},
+	}
+}
+
+// CreateAPIKeyRequest represents the request payload for creating an API key.
+// Scopes are permission names the caller holds; an API key without scopes can only
+// reach routes that do not require a permission.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=100" example:"Nightly export"`
+	Scopes    []string   `json:"scopes" validate:"omitempty,dive,required" example:"users:read"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2030-01-01T00:00:00Z"`
+}
+
+// APIKeyResponse represents the response payload for an API key (without the key itself)
+type APIKeyResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	Name       string     `json:"name" example:"Nightly export"`
+	Prefix     string     `json:"prefix" example:"sk_3q2-7wEA"`
+	Scopes     []string   `json:"scopes" example:"users:read"`
+	ExpiresAt  *time.Time `json:"expires_at" example:"2030-01-01T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at" example:"2023-01-01T00:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// CreateAPIKeyResponse represents the response payload for a new API key.
+// The plaintext key is only returned here.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"sk_3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+}
+
+// ToResponse converts APIKey model to APIKeyResponse
+func (k *APIKey) ToResponse() APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
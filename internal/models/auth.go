@@ -1,15 +1,46 @@
 package models
 
+import "time"
+
 // LoginRequest represents the request payload for user login
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email" example:"user@example.com"`
 	Password string `json:"password" validate:"required" example:"password123"`
 }
 
-// LoginResponse represents the response payload for successful login
+// LoginResponse represents the response payload for successful login.
+// When the account has two-factor authentication enabled, only the challenge
+// fields are set and the challenge token must be exchanged at /auth/2fa/verify.
 type LoginResponse struct {
-	Token string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User  UserResponse `json:"user"`
+	Token            string       `json:"token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType        string       `json:"token_type,omitempty" example:"Bearer"`
+	ExpiresAt        time.Time    `json:"expires_at,omitzero" example:"2023-01-01T00:15:00Z"`
+	RefreshToken     string       `json:"refresh_token,omitempty" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at,omitzero" example:"2023-01-31T00:00:00Z"`
+	User             UserResponse `json:"user,omitzero"`
+
+	// CSRFToken is set instead of the tokens when they were issued as cookies
+	CSRFToken string `json:"csrf_token,omitempty" example:"Xq2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+
+	TwoFactorRequired  bool      `json:"two_factor_required,omitempty" example:"false"`
+	ChallengeToken     string    `json:"challenge_token,omitempty" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at,omitzero" example:"2023-01-01T00:05:00Z"`
+}
+
+// RefreshTokenRequest represents the request payload for refreshing tokens.
+// The refresh token may instead come from the refresh cookie in cookie mode.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+}
+
+// ReauthenticateRequest represents the request payload for re-entering the password before a sensitive action
+type ReauthenticateRequest struct {
+	Password string `json:"password" validate:"required" example:"password123"`
+}
+
+// LogoutRequest represents the optional request payload for logout
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
 }
 
 // MessageResponse represents a simple message response
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// EmailVerificationToken represents a stored (hashed) email verification token
+type EmailVerificationToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// VerifyEmailRequest represents the request payload for verifying an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+}
+
+// ResendVerificationRequest represents the request payload for resending a verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email" example:"user@example.com"`
+}
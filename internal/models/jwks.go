@@ -0,0 +1,19 @@
+package models
+
+// JWK represents a public JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty" example:"RSA"`
+	Kid string `json:"kid" example:"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"RS256"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet represents a JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Login methods recorded in the login history
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodTwoFactor = "two_factor"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodSocial    = "social"
+
+	LoginMethodReauthenticate = "reauthenticate"
+)
+
+// Failure reasons recorded in the login history
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureAccountLocked      = "account_locked"
+	LoginFailureAccountDeactivated = "account_deactivated"
+	LoginFailureEmailNotVerified   = "email_not_verified"
+	LoginFailurePasswordExpired    = "password_expired"
+	LoginFailureInvalidTwoFactor   = "invalid_two_factor_code"
+	LoginFailureTwoFactorPending   = "two_factor_pending"
+	LoginFailureError              = "error"
+)
+
+// LoginAttempt records a login attempt, successful or not. UserID is nil when
+// the email did not belong to an account. NewDevice is set on successful
+// logins from a user agent or IP address the user had not logged in from before.
+type LoginAttempt struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        *uint     `json:"user_id" gorm:"index"`
+	Email         string    `json:"email" gorm:"index"`
+	Method        string    `json:"method" gorm:"not null"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason"`
+	IPAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	NewDevice     bool      `json:"new_device"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
+
+// TenantCondition limits the login history to attempts on accounts of members of the organization
+func (LoginAttempt) TenantCondition(organizationID uint) clause.Expression {
+	return clause.Expr{
+		SQL: "? IN (SELECT user_id FROM organization_members WHERE organization_id = ?)",
+		Vars: []interface{}{
+			clause.Column{Table: clause.CurrentTable, Name: "user_id"},
+			organizationID,
+		},
+	}
+}
+
+// LoginAttemptResponse represents the response payload for a login history entry
+type LoginAttemptResponse struct {
+	ID            uint      `json:"id" example:"1"`
+	Method        string    `json:"method" example:"password"`
+	Success       bool      `json:"success" example:"false"`
+	FailureReason string    `json:"failure_reason,omitempty" example:"invalid_credentials"`
+	IPAddress     string    `json:"ip_address" example:"203.0.113.7"`
+	UserAgent     string    `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)"`
+	NewDevice     bool      `json:"new_device" example:"false"`
+	CreatedAt     time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ToResponse converts LoginAttempt model to LoginAttemptResponse
+func (a *LoginAttempt) ToResponse() LoginAttemptResponse {
+	return LoginAttemptResponse{
+		ID:            a.ID,
+		Method:        a.Method,
+		Success:       a.Success,
+		FailureReason: a.FailureReason,
+		IPAddress:     a.IPAddress,
+		UserAgent:     a.UserAgent,
+		NewDevice:     a.NewDevice,
+		CreatedAt:     a.CreatedAt,
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// MagicLinkToken represents a stored (hashed) passwordless login token
+type MagicLinkToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MagicLinkRequest represents the request payload for requesting a magic login link
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email" example:"user@example.com"`
+}
+
+// MagicLinkVerifyRequest represents the request payload for redeeming a magic login link
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" validate:"required" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+}
@@ -0,0 +1,216 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// OAuth grant types supported by the authorization server
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// OAuthClient represents an application registered with the authorization server.
+// Public clients (e.g. single page or mobile apps) have no secret and must use PKCE.
+type OAuthClient struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ClientID     string    `json:"client_id" gorm:"uniqueIndex;not null"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"name" gorm:"not null"`
+	RedirectURIs string    `json:"-"`
+	GrantTypes   string    `json:"-" gorm:"not null"`
+	Scopes       string    `json:"-"`
+	Public       bool      `json:"public" gorm:"not null;default:false"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName sets the table name for the OAuthClient model
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// AllowsRedirectURI reports whether uri exactly matches a registered redirect URI
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	for _, registered := range strings.Fields(c.RedirectURIs) {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsGrant reports whether the client may use the grant type
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	for _, g := range strings.Fields(c.GrantTypes) {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScopes reports whether every requested scope is registered for the client
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	allowed := map[string]bool{}
+	for _, s := range strings.Fields(c.Scopes) {
+		allowed[s] = true
+	}
+	for _, s := range scopes {
+		if !allowed[s] {
+			return false
+		}
+	}
+	return true
+}
+
+// OAuthAuthorizationCode represents a stored (hashed) single-use authorization code
+type OAuthAuthorizationCode struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CodeHash      string    `json:"-" gorm:"uniqueIndex;not null"`
+	ClientID      string    `json:"client_id" gorm:"index;not null"`
+	UserID        uint      `json:"user_id" gorm:"index;not null"`
+	RedirectURI   string    `json:"redirect_uri" gorm:"not null"`
+	Scope         string    `json:"scope"`
+	CodeChallenge string    `json:"-" gorm:"not null"`
+	Nonce         string    `json:"-"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"index;not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName sets the table name for the OAuthAuthorizationCode model
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// CreateOAuthClientRequest represents the request payload for registering an OAuth client
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,min=2,max=100" example:"Internal Dashboard"`
+	RedirectURIs []string `json:"redirect_uris" validate:"omitempty,dive,url" example:"https://dashboard.example.com/callback"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code client_credentials refresh_token" example:"authorization_code,refresh_token"`
+	Scopes       []string `json:"scopes" validate:"omitempty,dive,required" example:"openid,profile,email"`
+	Public       bool     `json:"public" example:"false"`
+}
+
+// OAuthClientResponse represents the response payload for an OAuth client (without its secret)
+type OAuthClientResponse struct {
+	ID           uint      `json:"id" example:"1"`
+	ClientID     string    `json:"client_id" example:"3q2-7wEAAAB0b2tlbl9l"`
+	Name         string    `json:"name" example:"Internal Dashboard"`
+	RedirectURIs []string  `json:"redirect_uris" example:"https://dashboard.example.com/callback"`
+	GrantTypes   []string  `json:"grant_types" example:"authorization_code,refresh_token"`
+	Scopes       []string  `json:"scopes" example:"openid,profile,email"`
+	Public       bool      `json:"public" example:"false"`
+	CreatedAt    time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// CreateOAuthClientResponse represents the response payload for a new OAuth client.
+// The client secret is only returned here.
+type CreateOAuthClientResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"client_secret,omitempty" example:"dG9rZW5fZXhhbXBsZV9zZWNyZXQ..."`
+}
+
+// ToResponse converts OAuthClient model to OAuthClientResponse
+func (c *OAuthClient) ToResponse() OAuthClientResponse {
+	return OAuthClientResponse{
+		ID:           c.ID,
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		RedirectURIs: strings.Fields(c.RedirectURIs),
+		GrantTypes:   strings.Fields(c.GrantTypes),
+		Scopes:       strings.Fields(c.Scopes),
+		Public:       c.Public,
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+// AuthorizeRequest represents the authorization request parameters (RFC 6749 section 4.1.1)
+// sent by the sign-in page once the user has consented
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type" validate:"required" example:"code"`
+	ClientID            string `json:"client_id" validate:"required" example:"3q2-7wEAAAB0b2tlbl9l"`
+	RedirectURI         string `json:"redirect_uri" example:"https://dashboard.example.com/callback"`
+	Scope               string `json:"scope" example:"openid profile email"`
+	State               string `json:"state" example:"af0ifjsldkj"`
+	CodeChallenge       string `json:"code_challenge" example:"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"`
+	CodeChallengeMethod string `json:"code_challenge_method" example:"S256"`
+	Nonce               string `json:"nonce" example:"n-0S6_WzA2Mj"`
+}
+
+// AuthorizeResponse represents the response payload of the authorize API.
+// RedirectTo carries either the authorization code or an OAuth error for the client.
+type AuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to" example:"https://dashboard.example.com/callback?code=SplxlOBeZQQYbYS6WxSbIA&state=af0ifjsldkj"`
+}
+
+// OAuthTokenRequest represents the form parameters of the token endpoint
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+}
+
+// OAuthTokenResponse represents a successful token endpoint response (RFC 6749 section 5.1)
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+	RefreshToken string `json:"refresh_token,omitempty" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+	Scope        string `json:"scope,omitempty" example:"openid profile email"`
+	IDToken      string `json:"id_token,omitempty" example:"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// OAuthErrorResponse represents an OAuth error response (RFC 6749 section 5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_grant"`
+	ErrorDescription string `json:"error_description,omitempty" example:"authorization code is invalid or expired"`
+}
+
+// IntrospectionResponse represents a token introspection response (RFC 7662)
+type IntrospectionResponse struct {
+	Active    bool   `json:"active" example:"true"`
+	Scope     string `json:"scope,omitempty" example:"openid profile"`
+	ClientID  string `json:"client_id,omitempty" example:"3q2-7wEAAAB0b2tlbl9l"`
+	Username  string `json:"username,omitempty" example:"john@example.com"`
+	TokenType string `json:"token_type,omitempty" example:"access_token"`
+	Exp       int64  `json:"exp,omitempty" example:"1700000000"`
+	Iat       int64  `json:"iat,omitempty" example:"1699999100"`
+	Sub       string `json:"sub,omitempty" example:"1"`
+	Iss       string `json:"iss,omitempty" example:"golang-starter-kit"`
+	Jti       string `json:"jti,omitempty" example:"3q2-7wEAAAB0b2tlbl9l"`
+}
+
+// UserInfoResponse represents the OpenID Connect userinfo response, built from UserResponse
+type UserInfoResponse struct {
+	Sub           string   `json:"sub" example:"1"`
+	Name          string   `json:"name,omitempty" example:"John Doe"`
+	Email         string   `json:"email,omitempty" example:"john@example.com"`
+	EmailVerified *bool    `json:"email_verified,omitempty" example:"true"`
+	Roles         []string `json:"roles,omitempty" example:"admin"`
+	UpdatedAt     int64    `json:"updated_at,omitempty" example:"1672531200"`
+}
+
+// OpenIDConfiguration represents the OpenID Provider metadata document
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
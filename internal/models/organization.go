@@ -0,0 +1,96 @@
+package models
+
+import "time"
+
+// Organization represents a tenant. Users belong to organizations through memberships.
+type Organization struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMember represents a user's membership of an organization.
+// A user may belong to several organizations.
+type OrganizationMember struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"uniqueIndex:idx_organization_members_organization_user;not null"`
+	UserID         uint      `json:"user_id" gorm:"uniqueIndex:idx_organization_members_organization_user;index;not null"`
+	Role           string    `json:"role" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	User         User         `json:"-"`
+	Organization Organization `json:"-"`
+}
+
+// Organization member roles. Owners and admins manage the members; only owners
+// can add or remove other owners.
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// CanManageMembers reports whether the member may add and remove members
+func (m *OrganizationMember) CanManageMembers() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
+
+// CanAssignRole reports whether the member may add, invite or remove members with the role
+func (m *OrganizationMember) CanAssignRole(role string) bool {
+	return m.CanManageMembers() && (role != OrganizationRoleOwner || m.Role == OrganizationRoleOwner)
+}
+
+// OrganizationCreateRequest represents the request payload for creating an organization
+type OrganizationCreateRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100" example:"Acme Inc."`
+	Slug string `json:"slug" validate:"required,min=2,max=63,hostname_rfc1123,excludes=.,lowercase" example:"acme"`
+}
+
+// AddOrganizationMemberRequest represents the request payload for adding a user to an organization
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email" validate:"required,email" example:"jane@example.com"`
+	Role  string `json:"role" validate:"required,oneof=owner admin member" example:"member"`
+}
+
+// OrganizationResponse represents the response payload for an organization and the user's role in it
+type OrganizationResponse struct {
+	ID        uint      `json:"id" example:"1"`
+	Name      string    `json:"name" example:"Acme Inc."`
+	Slug      string    `json:"slug" example:"acme"`
+	Role      string    `json:"role,omitempty" example:"owner"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// OrganizationMemberResponse represents the response payload for an organization member
+type OrganizationMemberResponse struct {
+	UserID    uint      `json:"user_id" example:"42"`
+	Name      string    `json:"name" example:"Jane Doe"`
+	Email     string    `json:"email" example:"jane@example.com"`
+	Role      string    `json:"role" example:"member"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ToResponse converts Organization model to OrganizationResponse
+func (o *Organization) ToResponse() OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Name:      o.Name,
+		Slug:      o.Slug,
+		CreatedAt: o.CreatedAt,
+	}
+}
+
+// ToResponse converts OrganizationMember model to OrganizationMemberResponse.
+// The user must be loaded.
+func (m *OrganizationMember) ToResponse() OrganizationMemberResponse {
+	return OrganizationMemberResponse{
+		UserID:    m.UserID,
+		Name:      m.User.Name,
+		Email:     m.User.Email,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationInvitation invites an email address to join an organization with
+// a role. Only the hash of the invitation token is stored.
+type OrganizationInvitation struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID uint       `json:"organization_id" gorm:"index;not null"`
+	Email          string     `json:"email" gorm:"index;not null"`
+	Role           string     `json:"role" gorm:"not null"`
+	TokenHash      string     `json:"-" gorm:"uniqueIndex;not null"`
+	InvitedByID    uint       `json:"invited_by_id" gorm:"not null"`
+	Status         string     `json:"status" gorm:"index;not null"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	AcceptedByID   *uint      `json:"accepted_by_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Organization Organization `json:"-"`
+}
+
+// TenantCondition limits invitations to those of the organization
+func (OrganizationInvitation) TenantCondition(organizationID uint) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "organization_id"}, Value: organizationID}
+}
+
+// Invitation statuses. Pending invitations become expired once they pass their expiry.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// StatusAt returns the invitation's status at the given time, reporting pending
+// invitations past their expiry as expired
+func (i *OrganizationInvitation) StatusAt(now time.Time) string {
+	if i.Status == InvitationStatusPending && now.After(i.ExpiresAt) {
+		return InvitationStatusExpired
+	}
+	return i.Status
+}
+
+// CreateInvitationRequest represents the request payload for inviting someone to an organization
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email" example:"jane@example.com"`
+	Role  string `json:"role" validate:"required,oneof=owner admin member" example:"member"`
+}
+
+// AcceptInvitationRequest represents the request payload for accepting an invitation.
+// Name and password are only used, and then required, when no account exists for the invited email.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required" example:"k3VfY1m0cJ9n2Qx7TzB8aLw4pR6sD5hE"`
+	Name     string `json:"name" validate:"omitempty,min=2,max=100" example:"Jane Doe"`
+	Password string `json:"password" example:"password123"`
+}
+
+// InvitationResponse represents the response payload for an invitation
+type InvitationResponse struct {
+	ID             uint       `json:"id" example:"1"`
+	OrganizationID uint       `json:"organization_id" example:"1"`
+	Email          string     `json:"email" example:"jane@example.com"`
+	Role           string     `json:"role" example:"member"`
+	InvitedByID    uint       `json:"invited_by_id" example:"1"`
+	Status         string     `json:"status" example:"pending"`
+	ExpiresAt      time.Time  `json:"expires_at" example:"2023-01-08T00:00:00Z"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty" example:"2023-01-02T00:00:00Z"`
+	CreatedAt      time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// AcceptInvitationResponse represents the response payload for an accepted invitation
+type AcceptInvitationResponse struct {
+	Organization OrganizationResponse `json:"organization"`
+	User         UserResponse         `json:"user"`
+}
+
+// ToResponse converts OrganizationInvitation model to InvitationResponse
+func (i *OrganizationInvitation) ToResponse() InvitationResponse {
+	return InvitationResponse{
+		ID:             i.ID,
+		OrganizationID: i.OrganizationID,
+		Email:          i.Email,
+		Role:           i.Role,
+		InvitedByID:    i.InvitedByID,
+		Status:         i.StatusAt(time.Now()),
+		ExpiresAt:      i.ExpiresAt,
+		AcceptedAt:     i.AcceptedAt,
+		CreatedAt:      i.CreatedAt,
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// PasswordHistory represents a hash of a password the user had before, kept
+// to stop the last passwords from being reused
+type PasswordHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"index;not null"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Password policy violation codes
+const (
+	PasswordTooShort         = "too_short"
+	PasswordTooLong          = "too_long"
+	PasswordMissingUppercase = "missing_uppercase"
+	PasswordMissingLowercase = "missing_lowercase"
+	PasswordMissingDigit     = "missing_digit"
+	PasswordMissingSymbol    = "missing_symbol"
+	PasswordReused           = "reused"
+	PasswordBreached         = "breached"
+)
+
+// PasswordViolation describes one password policy rule a password breaks
+type PasswordViolation struct {
+	Code    string `json:"code" example:"too_short"`
+	Message string `json:"message" example:"Password must be at least 8 characters long"`
+}
+
+// PasswordPolicyErrorResponse represents the error response for a password rejected by the policy
+type PasswordPolicyErrorResponse struct {
+	Error      string              `json:"error" example:"password_policy_violation"`
+	Message    string              `json:"message" example:"Password does not meet the password policy"`
+	Violations []PasswordViolation `json:"violations"`
+}
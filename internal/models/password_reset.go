@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// PasswordResetToken represents a stored (hashed) password reset token
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ForgotPasswordRequest represents the request payload for requesting a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest represents the request payload for resetting a password
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+	Password string `json:"password" validate:"required" example:"newpassword123"`
+}
+
+// ChangePasswordRequest represents the request payload for changing the authenticated user's password
+type ChangePasswordRequest struct {
+	CurrentPassword     string `json:"current_password" validate:"required" example:"password123"`
+	Password            string `json:"password" validate:"required" example:"newpassword123"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions" example:"true"`
+}
+
+// ChangePasswordResponse represents the response payload for a password change.
+// Tokens is set when other sessions were revoked and replaces the caller's current tokens.
+type ChangePasswordResponse struct {
+	Tokens *LoginResponse `json:"tokens,omitempty"`
+}
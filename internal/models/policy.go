@@ -0,0 +1,10 @@
+package models
+
+// PolicyDeniedResponse is returned with 403 when the authorization policy denies an action
+type PolicyDeniedResponse struct {
+	Error   string `json:"error" example:"policy_denied"`
+	Message string `json:"message" example:"The last admin cannot be deleted"`
+	Action  string `json:"action" example:"users:delete"`
+	// Rule is the deny rule that matched, or empty when no rule allowed the action
+	Rule string `json:"rule,omitempty" example:"protect-last-admin"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RefreshToken represents a stored (hashed) refresh token.
+// Tokens issued from the same login share a FamilyID so the whole chain
+// can be revoked when a rotated token is presented again. Tokens issued to OAuth
+// clients carry the ClientID and granted Scope.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	FamilyID  string     `json:"family_id" gorm:"index;not null"`
+	ClientID  string     `json:"client_id" gorm:"index"`
+	Scope     string     `json:"scope"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the token can still be exchanged
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
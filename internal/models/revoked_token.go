@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RevokedToken represents a revoked access token.
+// A row either revokes a single token by JTI, or, when IssuedBefore is set,
+// every token of UserID issued before that time, compared with the iat claim at
+// millisecond precision. Rows can be deleted once ExpiresAt has passed because
+// the tokens they cover are expired by then.
+type RevokedToken struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	JTI          string     `json:"jti" gorm:"column:jti;index"`
+	UserID       uint       `json:"user_id" gorm:"index;not null"`
+	IssuedBefore *time.Time `json:"issued_before"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"index;not null"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Role represents a named set of permissions that can be assigned to users
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;not null"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Permission represents a single action such as "users:delete"
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Permission names used by the application
+const (
+	PermissionUsersCreate      = "users:create"
+	PermissionUsersRead        = "users:read"
+	PermissionUsersUpdate      = "users:update"
+	PermissionUsersDelete      = "users:delete"
+	PermissionUsersUnlock      = "users:unlock"
+	PermissionUsersSessions    = "users:sessions"
+	PermissionUsersImpersonate = "users:impersonate"
+	PermissionRolesManage      = "roles:manage"
+	PermissionOrganizationsAdd = "organizations:add_members"
+
+	PermissionOAuthClientsManage = "oauth_clients:manage"
+)
+
+// Policy actions of changing a user's roles. The role's name is the
+// resource.role attribute of the user.
+const (
+	ActionRolesAssign = "roles:assign"
+	ActionRolesRemove = "roles:remove"
+)
+
+// RoleAdmin is the built-in role holding every permission
+const RoleAdmin = "admin"
+
+// RoleResponse represents the response payload for a role
+type RoleResponse struct {
+	ID          uint     `json:"id" example:"1"`
+	Name        string   `json:"name" example:"admin"`
+	Description string   `json:"description" example:"Full access to user management"`
+	Permissions []string `json:"permissions" example:"users:read,users:delete"`
+}
+
+// AssignRoleRequest represents the request payload for assigning a role to a user
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required" example:"admin"`
+}
+
+// ToResponse converts Role model to RoleResponse
+func (r *Role) ToResponse() RoleResponse {
+	permissions := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+	return RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions,
+	}
+}
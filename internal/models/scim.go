@@ -0,0 +1,201 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SCIM 2.0 schema and message URNs (RFC 7643, RFC 7644)
+const (
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMSchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SCIMSchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+	SCIMMessageListResponse         = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMMessagePatchOp              = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMMessageError                = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// SCIMContentType is the media type of SCIM requests and responses
+const SCIMContentType = "application/scim+json"
+
+// SCIMUser is a SCIM User resource. The userName is the user's email address.
+// Password is write-only and never returned.
+type SCIMUser struct {
+	Schemas     []string    `json:"schemas" example:"urn:ietf:params:scim:schemas:core:2.0:User"`
+	ID          string      `json:"id,omitempty" example:"42"`
+	ExternalID  string      `json:"externalId,omitempty" example:"00u1a2b3c4"`
+	UserName    string      `json:"userName" example:"jane@example.com"`
+	Name        *SCIMName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty" example:"Jane Doe"`
+	Emails      []SCIMEmail `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty" example:"true"`
+	Password    string      `json:"password,omitempty"`
+	Meta        *SCIMMeta   `json:"meta,omitempty"`
+}
+
+// SCIMName is the name of a SCIM User
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty" example:"Jane Doe"`
+	GivenName  string `json:"givenName,omitempty" example:"Jane"`
+	FamilyName string `json:"familyName,omitempty" example:"Doe"`
+}
+
+// SCIMEmail is an email address of a SCIM User
+type SCIMEmail struct {
+	Value   string `json:"value" example:"jane@example.com"`
+	Type    string `json:"type,omitempty" example:"work"`
+	Primary bool   `json:"primary,omitempty" example:"true"`
+}
+
+// SCIMMeta holds the resource metadata of a SCIM resource
+type SCIMMeta struct {
+	ResourceType string     `json:"resourceType" example:"User"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty" example:"http://localhost:8080/scim/v2/Users/42"`
+}
+
+// SCIMListResponse is a page of SCIM resources
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults" example:"1"`
+	StartIndex   int         `json:"startIndex" example:"1"`
+	ItemsPerPage int         `json:"itemsPerPage" example:"1"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMListRequest holds the query parameters of a SCIM list request.
+// StartIndex is 1-based.
+type SCIMListRequest struct {
+	Filter     string `form:"filter"`
+	StartIndex int    `form:"startIndex"`
+	Count      *int   `form:"count"`
+}
+
+// SCIMPatchRequest is a SCIM PATCH request
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations" validate:"required,min=1,dive"`
+}
+
+// SCIMPatchOperation is one operation of a SCIM PATCH request
+type SCIMPatchOperation struct {
+	Op    string          `json:"op" validate:"required" example:"replace"`
+	Path  string          `json:"path,omitempty" example:"active"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+// SCIMErrorResponse is the body of SCIM error responses
+type SCIMErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status" example:"409"`
+	ScimType string   `json:"scimType,omitempty" example:"uniqueness"`
+	Detail   string   `json:"detail" example:"a user with this userName already exists"`
+}
+
+// SCIMServiceProviderConfig describes the SCIM features the service supports
+type SCIMServiceProviderConfig struct {
+	Schemas               []string                 `json:"schemas"`
+	DocumentationURI      string                   `json:"documentationUri,omitempty"`
+	Patch                 SCIMSupported            `json:"patch"`
+	Bulk                  SCIMBulkSupport          `json:"bulk"`
+	Filter                SCIMFilterSupport        `json:"filter"`
+	ChangePassword        SCIMSupported            `json:"changePassword"`
+	Sort                  SCIMSupported            `json:"sort"`
+	ETag                  SCIMSupported            `json:"etag"`
+	AuthenticationSchemes []SCIMAuthenticationType `json:"authenticationSchemes"`
+	Meta                  *SCIMMeta                `json:"meta,omitempty"`
+}
+
+// SCIMSupported reports whether a SCIM feature is supported
+type SCIMSupported struct {
+	Supported bool `json:"supported"`
+}
+
+// SCIMBulkSupport describes bulk operation support
+type SCIMBulkSupport struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+// SCIMFilterSupport describes filter support
+type SCIMFilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+// SCIMAuthenticationType describes an authentication scheme of the SCIM service
+type SCIMAuthenticationType struct {
+	Type        string `json:"type" example:"oauthbearertoken"`
+	Name        string `json:"name" example:"Bearer Token"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+// SCIMResourceType describes a resource type served by the SCIM service
+type SCIMResourceType struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id" example:"User"`
+	Name        string    `json:"name" example:"User"`
+	Endpoint    string    `json:"endpoint" example:"/Users"`
+	Description string    `json:"description"`
+	Schema      string    `json:"schema" example:"urn:ietf:params:scim:schemas:core:2.0:User"`
+	Meta        *SCIMMeta `json:"meta,omitempty"`
+}
+
+// SCIMSchema describes the attributes of a SCIM resource
+type SCIMSchema struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id" example:"urn:ietf:params:scim:schemas:core:2.0:User"`
+	Name        string          `json:"name" example:"User"`
+	Description string          `json:"description"`
+	Attributes  []SCIMAttribute `json:"attributes"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+// SCIMAttribute describes an attribute of a SCIM schema
+type SCIMAttribute struct {
+	Name          string          `json:"name" example:"userName"`
+	Type          string          `json:"type" example:"string"`
+	MultiValued   bool            `json:"multiValued"`
+	Description   string          `json:"description,omitempty"`
+	Required      bool            `json:"required"`
+	CaseExact     bool            `json:"caseExact"`
+	Mutability    string          `json:"mutability" example:"readWrite"`
+	Returned      string          `json:"returned" example:"default"`
+	Uniqueness    string          `json:"uniqueness" example:"server"`
+	SubAttributes []SCIMAttribute `json:"subAttributes,omitempty"`
+}
+
+// ToSCIM converts the user to a SCIM User resource located under baseURL
+func (u *User) ToSCIM(baseURL string) SCIMUser {
+	id := strconv.FormatUint(uint64(u.ID), 10)
+	active := !u.IsDeactivated()
+	givenName, familyName, _ := strings.Cut(u.Name, " ")
+	created, lastModified := u.CreatedAt, u.UpdatedAt
+
+	return SCIMUser{
+		Schemas:    []string{SCIMSchemaUser},
+		ID:         id,
+		ExternalID: u.ExternalID,
+		UserName:   u.Email,
+		Name: &SCIMName{
+			Formatted:  u.Name,
+			GivenName:  givenName,
+			FamilyName: familyName,
+		},
+		DisplayName: u.Name,
+		Emails:      []SCIMEmail{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &SCIMMeta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &lastModified,
+			Location:     baseURL + "/Users/" + id,
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Session represents a device the user signed in on. It is created at login
+// and shares its FamilyID with the refresh tokens issued to the device.
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	FamilyID   string     `json:"-" gorm:"uniqueIndex;not null"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// AuthenticatedAt is when the user last entered credentials on the session. It
+	// becomes the auth_time claim of its access tokens; nil for sessions created for
+	// refresh tokens issued before sessions were tracked.
+	AuthenticatedAt *time.Time `json:"-"`
+}
+
+// TenantCondition limits sessions to those of members of the organization
+func (Session) TenantCondition(organizationID uint) clause.Expression {
+	return clause.Expr{
+		SQL: "? IN (SELECT user_id FROM organization_members WHERE organization_id = ?)",
+		Vars: []interface{}{
+			clause.Column{Table: clause.CurrentTable, Name: "user_id"},
+			organizationID,
+		},
+	}
+}
+
+// ClientInfo describes the client a request came from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// SessionResponse represents the response payload for a session
+type SessionResponse struct {
+	ID         uint      `json:"id" example:"1"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)"`
+	IPAddress  string    `json:"ip_address" example:"203.0.113.7"`
+	Current    bool      `json:"current" example:"true"`
+	CreatedAt  time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	LastSeenAt time.Time `json:"last_seen_at" example:"2023-01-01T01:00:00Z"`
+}
+
+// ToResponse converts Session model to SessionResponse. currentID is the
+// session of the request, if any.
+func (s *Session) ToResponse(currentID uint) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		Current:    currentID != 0 && s.ID == currentID,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+	}
+}
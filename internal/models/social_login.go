@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	Provider  string    `json:"provider" gorm:"uniqueIndex:idx_user_identities_provider_subject;not null"`
+	Subject   string    `json:"subject" gorm:"uniqueIndex:idx_user_identities_provider_subject;not null"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SocialLoginState represents a pending social login.
+// It is created when the user is sent to the provider and consumed by the callback.
+type SocialLoginState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Provider     string    `json:"provider" gorm:"not null"`
+	StateHash    string    `json:"-" gorm:"uniqueIndex;not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index;not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SocialProvidersResponse represents the response payload listing the enabled social login providers
+type SocialProvidersResponse struct {
+	Providers []string `json:"providers" example:"google,github"`
+}
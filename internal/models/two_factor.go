@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// TwoFactorRecoveryCode represents a stored (hashed) one-time recovery code
+type TwoFactorRecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	CodeHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorChallenge represents a pending second login step.
+// It is created after a correct password and exchanged for tokens with a valid code.
+type TwoFactorChallenge struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex;not null"`
+	Attempts  int       `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TwoFactorEnrollResponse represents the response payload for starting 2FA enrollment
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURL string `json:"otpauth_url" example:"otpauth://totp/Golang%20Starter%20Kit:user@example.com?secret=JBSWY3DPEHPK3PXP"`
+	QRCode     string `json:"qr_code" example:"data:image/png;base64,iVBORw0KGgo..."`
+}
+
+// TwoFactorCodeRequest represents a request payload carrying a TOTP code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required" example:"123456"`
+}
+
+// TwoFactorDisableRequest represents the request payload for disabling 2FA
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required" example:"password123"`
+	Code     string `json:"code" validate:"required" example:"123456"`
+}
+
+// TwoFactorVerifyRequest represents the request payload for completing a 2FA login.
+// Code accepts either a TOTP code or a recovery code.
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required" example:"3q2-7wEAAAB0b2tlbl9leGFtcGxl..."`
+	Code           string `json:"code" validate:"required" example:"123456"`
+}
+
+// RecoveryCodesResponse represents the response payload holding plaintext recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"ABCD-EFGH-IJKL-MNOP"`
+}
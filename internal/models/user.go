@@ -1,27 +1,135 @@
 package models
 
 import (
+	"slices"
+	"strings"
 	"time"
 
+	"golang-starter-kit/internal/policy"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // User represents a user in the system
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Name      string         `json:"name" gorm:"not null" validate:"required,min=2,max=100"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null" validate:"required,email"`
-	Password  string         `json:"-" gorm:"not null" validate:"required,min=6"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"not null" validate:"required,min=2,max=100"`
+	Email           string         `json:"email" gorm:"uniqueIndex;not null" validate:"required,email"`
+	Password        string         `json:"-" gorm:"not null" validate:"required,min=6"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Two-factor authentication. The secret is stored encrypted and is only
+	// active once TwoFactorEnabledAt is set.
+	TwoFactorSecret    string     `json:"-"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	TwoFactorLastStep  int64      `json:"-"`
+
+	// Failed login tracking for account lockout
+	FailedLoginAttempts int        `json:"-" gorm:"not null;default:0"`
+	LockedUntil         *time.Time `json:"locked_until"`
+
+	// When the password was last set, for the maximum password age
+	PasswordChangedAt *time.Time `json:"password_changed_at"`
+
+	// Provisioning. ExternalID is the identity provider's ID of a user created
+	// through SCIM; deactivated users cannot log in.
+	ExternalID    string     `json:"external_id,omitempty" gorm:"index"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+
+	// Where the password is checked: the local hash or an LDAP directory
+	AuthSource string `json:"auth_source" gorm:"not null;default:local"`
+
+	Roles []Role `json:"roles,omitempty" gorm:"many2many:user_roles"`
+}
+
+// ResourceTypeUser is the policy resource type of users
+const ResourceTypeUser = "user"
+
+// ActionUsersUpdateEmail is the policy action of changing a user's email address.
+// It is checked in addition to users:update since the email identifies the account everywhere.
+const ActionUsersUpdateEmail = "users:update_email"
+
+// Authentication sources of users
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+)
+
+// RoleNames returns the names of the user's loaded roles
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// PolicyResource describes the user to authorization policies as a "user" resource
+func (u *User) PolicyResource() policy.Resource {
+	return policy.Resource{
+		Type: ResourceTypeUser,
+		Attributes: map[string]interface{}{
+			"id":             u.ID,
+			"email":          u.Email,
+			"roles":          u.RoleNames(),
+			"has_roles":      len(u.Roles) > 0,
+			"admin":          slices.Contains(u.RoleNames(), RoleAdmin),
+			"email_verified": u.IsEmailVerified(),
+		},
+	}
+}
+
+// IsLocked reports whether the account is locked out at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(now)
+}
+
+// IsTwoFactorEnabled reports whether the user has confirmed two-factor authentication
+func (u *User) IsTwoFactorEnabled() bool {
+	return u.TwoFactorEnabledAt != nil
+}
+
+// NormalizeEmail returns the form emails are stored and looked up in: trimmed
+// and lower-cased, so addresses differing only in case name the same account
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// IsDeactivated reports whether the account has been deactivated
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// IsLocal reports whether the user's password is checked against the local hash
+func (u *User) IsLocal() bool {
+	return u.AuthSource == "" || u.AuthSource == AuthSourceLocal
+}
+
+// IsEmailVerified reports whether the user has confirmed their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// TenantCondition limits users to the members of the organization
+func (User) TenantCondition(organizationID uint) clause.Expression {
+	return clause.Expr{
+		SQL: "? IN (SELECT user_id FROM organization_members WHERE organization_id = ?)",
+		Vars: []interface{}{
+			clause.Column{Table: clause.CurrentTable, Name: "id"},
+			organizationID,
+		},
+	}
 }
 
 // UserCreateRequest represents the request payload for creating a user
 type UserCreateRequest struct {
 	Name     string `json:"name" validate:"required,min=2,max=100" example:"John Doe"`
 	Email    string `json:"email" validate:"required,email" example:"john@example.com"`
-	Password string `json:"password" validate:"required,min=6" example:"password123"`
+	Password string `json:"password" validate:"required" example:"password123"`
 }
 
 // UserUpdateRequest represents the request payload for updating a user
@@ -32,11 +140,17 @@ type UserUpdateRequest struct {
 
 // UserResponse represents the response payload for user data (without password)
 type UserResponse struct {
-	ID        uint      `json:"id" example:"1"`
-	Name      string    `json:"name" example:"John Doe"`
-	Email     string    `json:"email" example:"john@example.com"`
-	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	ID               uint       `json:"id" example:"1"`
+	Name             string     `json:"name" example:"John Doe"`
+	Email            string     `json:"email" example:"john@example.com"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at" example:"2023-01-01T00:00:00Z"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" example:"false"`
+	Roles            []string   `json:"roles" example:"admin"`
+	LockedUntil      *time.Time `json:"locked_until,omitempty" example:"2023-01-01T00:15:00Z"`
+	DeactivatedAt    *time.Time `json:"deactivated_at,omitempty" example:"2023-01-01T00:00:00Z"`
+	AuthSource       string     `json:"auth_source" example:"local"`
+	CreatedAt        time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt        time.Time  `json:"updated_at" example:"2023-01-01T00:00:00Z"`
 }
 
 // UsersListResponse represents the response payload for users list with pagination
@@ -75,11 +189,22 @@ type UserListRequest struct {
 
 // ToResponse converts User model to UserResponse
 func (u *User) ToResponse() UserResponse {
+	var lockedUntil *time.Time
+	if u.IsLocked(time.Now()) {
+		lockedUntil = u.LockedUntil
+	}
+
 	return UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:               u.ID,
+		Name:             u.Name,
+		Email:            u.Email,
+		EmailVerifiedAt:  u.EmailVerifiedAt,
+		TwoFactorEnabled: u.IsTwoFactorEnabled(),
+		Roles:            u.RoleNames(),
+		LockedUntil:      lockedUntil,
+		DeactivatedAt:    u.DeactivatedAt,
+		AuthSource:       u.AuthSource,
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
 	}
 }
@@ -0,0 +1,330 @@
+// Package policy implements attribute-based authorization. A policy decides
+// whether an actor may perform an action on a resource from their attributes,
+// using rules loaded from a declarative JSON file.
+package policy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+//go:embed default_policy.json
+var defaultPolicy []byte
+
+// ErrDenied is returned by Authorize when the policy denies the action
+var ErrDenied = errors.New("you are not allowed to perform this action")
+
+// DeniedError wraps ErrDenied with the action and the rule that denied it.
+// Rule is empty when no rule allowed the action.
+type DeniedError struct {
+	Action  string
+	Rule    string
+	Message string
+}
+
+func (e *DeniedError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return ErrDenied.Error()
+}
+
+// Unwrap allows errors.Is(err, ErrDenied)
+func (e *DeniedError) Unwrap() error {
+	return ErrDenied
+}
+
+// Actor describes who performs an action. Attributes are available to rules as actor.<name>.
+type Actor struct {
+	ID               uint
+	Roles            []string
+	Permissions      []string
+	OrganizationID   uint
+	OrganizationRole string
+	Impersonated     bool
+	// Scoped is set for API keys and OAuth client tokens, whose permissions are limited to their scopes
+	Scoped bool
+	// SCIM is set for identity providers provisioning users through SCIM
+	SCIM bool
+}
+
+// Attributes returns the actor's attributes by name
+func (a Actor) Attributes() map[string]interface{} {
+	return map[string]interface{}{
+		"id":           a.ID,
+		"roles":        a.Roles,
+		"permissions":  a.Permissions,
+		"org_id":       a.OrganizationID,
+		"org_role":     a.OrganizationRole,
+		"impersonated": a.Impersonated,
+		"scoped":       a.Scoped,
+		"scim":         a.SCIM,
+	}
+}
+
+// Resource describes what an action is performed on. Attributes are available to rules as resource.<name>.
+type Resource struct {
+	Type       string
+	Attributes map[string]interface{}
+}
+
+// Decision is the outcome of a policy check and the rule that decided it
+type Decision struct {
+	Allowed bool
+	Rule    string
+	Message string
+}
+
+// Policy decides whether an actor may perform an action on a resource
+type Policy interface {
+	Can(actor Actor, action string, resource Resource) Decision
+}
+
+// Authorize checks the action against the policy and returns a *DeniedError when it is denied
+func Authorize(p Policy, actor Actor, action string, resource Resource) error {
+	decision := p.Can(actor, action, resource)
+	if decision.Allowed {
+		return nil
+	}
+	return &DeniedError{Action: action, Rule: decision.Rule, Message: decision.Message}
+}
+
+// Rule allows or denies actions on a resource type when all its conditions hold.
+// "*" matches any action or resource type.
+type Rule struct {
+	Name       string      `json:"name"`
+	Effect     string      `json:"effect"`
+	Resource   string      `json:"resource"`
+	Actions    []string    `json:"actions"`
+	Conditions []Condition `json:"conditions"`
+	Message    string      `json:"message"`
+}
+
+// Condition compares an attribute with a literal value or with another attribute (ref).
+// Operators: eq, ne, in (attribute is one of the values), not_in, contains (list attribute holds the value)
+// and not_contains.
+type Condition struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value"`
+	Ref       string      `json:"ref"`
+}
+
+// Rule effects
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// RuleSet is a Policy made of rules. Deny rules take precedence over allow rules,
+// and actions no rule allows are denied.
+type RuleSet struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Load reads a rule set from a JSON file, or the built-in default policy when path is empty
+func Load(path string) (*RuleSet, error) {
+	data := defaultPolicy
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read policy file: %w", err)
+		}
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a JSON rule set
+func Parse(data []byte) (*RuleSet, error) {
+	var rules RuleSet
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	for i, rule := range rules.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid policy rule %d (%s): %w", i, rule.Name, err)
+		}
+	}
+	return &rules, nil
+}
+
+// Can decides the action. A matching deny rule wins over any allow rule.
+func (s *RuleSet) Can(actor Actor, action string, resource Resource) Decision {
+	attributes := map[string]map[string]interface{}{
+		"actor":    actor.Attributes(),
+		"resource": resource.Attributes,
+	}
+
+	var allowed *Rule
+	for i := range s.Rules {
+		rule := &s.Rules[i]
+		if !rule.matches(action, resource.Type, attributes) {
+			continue
+		}
+		if rule.Effect == EffectDeny {
+			return Decision{Allowed: false, Rule: rule.Name, Message: rule.Message}
+		}
+		if allowed == nil {
+			allowed = rule
+		}
+	}
+
+	if allowed == nil {
+		return Decision{Allowed: false}
+	}
+	return Decision{Allowed: true, Rule: allowed.Name}
+}
+
+// validate checks the rule's effect, actions and conditions
+func (r *Rule) validate() error {
+	if r.Effect != EffectAllow && r.Effect != EffectDeny {
+		return fmt.Errorf("effect must be %q or %q", EffectAllow, EffectDeny)
+	}
+	if r.Resource == "" {
+		return errors.New("resource is required")
+	}
+	if len(r.Actions) == 0 {
+		return errors.New("at least one action is required")
+	}
+	for _, condition := range r.Conditions {
+		if err := condition.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to the action and resource type and all its conditions hold
+func (r *Rule) matches(action, resourceType string, attributes map[string]map[string]interface{}) bool {
+	if r.Resource != "*" && r.Resource != resourceType {
+		return false
+	}
+	if !slices.Contains(r.Actions, "*") && !slices.Contains(r.Actions, action) {
+		return false
+	}
+	for _, condition := range r.Conditions {
+		if !condition.holds(attributes) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks the condition's attribute paths and operator
+func (c *Condition) validate() error {
+	if !validPath(c.Attribute) {
+		return fmt.Errorf("attribute %q must start with actor. or resource.", c.Attribute)
+	}
+	if c.Ref != "" && !validPath(c.Ref) {
+		return fmt.Errorf("ref %q must start with actor. or resource.", c.Ref)
+	}
+	switch c.Operator {
+	case "eq", "ne", "contains", "not_contains":
+	case "in", "not_in":
+		if _, ok := c.Value.([]interface{}); !ok && c.Ref == "" {
+			return fmt.Errorf("operator %q needs a list value", c.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	return nil
+}
+
+// holds evaluates the condition. Missing attributes compare as nil.
+func (c *Condition) holds(attributes map[string]map[string]interface{}) bool {
+	actual := lookup(attributes, c.Attribute)
+	expected := c.Value
+	if c.Ref != "" {
+		expected = lookup(attributes, c.Ref)
+	}
+
+	switch c.Operator {
+	case "eq":
+		return equal(actual, expected)
+	case "ne":
+		return !equal(actual, expected)
+	case "in":
+		return containsValue(expected, actual)
+	case "not_in":
+		return !containsValue(expected, actual)
+	case "contains":
+		return containsValue(actual, expected)
+	case "not_contains":
+		return !containsValue(actual, expected)
+	}
+	return false
+}
+
+// validPath reports whether path names an actor or resource attribute
+func validPath(path string) bool {
+	scope, name, found := strings.Cut(path, ".")
+	return found && name != "" && (scope == "actor" || scope == "resource")
+}
+
+// lookup resolves an actor.<name> or resource.<name> path
+func lookup(attributes map[string]map[string]interface{}, path string) interface{} {
+	scope, name, _ := strings.Cut(path, ".")
+	return attributes[scope][name]
+}
+
+// containsValue reports whether list, a slice, holds value
+func containsValue(list, value interface{}) bool {
+	switch items := list.(type) {
+	case []interface{}:
+		for _, item := range items {
+			if equal(item, value) {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range items {
+			if equal(item, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equal compares attribute values. Numbers are compared by value since JSON
+// literals decode as float64 while attributes are usually integers.
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case nil:
+		return b == nil
+	}
+	return false
+}
+
+// number converts numeric values to float64
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,228 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{"invalid JSON", `{"rules": [`, "invalid policy"},
+		{"unknown effect", `{"rules": [{"name": "r", "effect": "permit", "resource": "user", "actions": ["a"]}]}`, "effect"},
+		{"no resource", `{"rules": [{"name": "r", "effect": "allow", "actions": ["a"]}]}`, "resource"},
+		{"no actions", `{"rules": [{"name": "r", "effect": "allow", "resource": "user"}]}`, "action"},
+		{"unknown operator", `{"rules": [{"name": "r", "effect": "allow", "resource": "user", "actions": ["a"],
+			"conditions": [{"attribute": "actor.id", "operator": "gt", "value": 1}]}]}`, "operator"},
+		{"unscoped attribute", `{"rules": [{"name": "r", "effect": "allow", "resource": "user", "actions": ["a"],
+			"conditions": [{"attribute": "id", "operator": "eq", "value": 1}]}]}`, "actor."},
+		{"unscoped ref", `{"rules": [{"name": "r", "effect": "allow", "resource": "user", "actions": ["a"],
+			"conditions": [{"attribute": "actor.id", "operator": "eq", "ref": "subject.id"}]}]}`, "ref"},
+		{"in without a list", `{"rules": [{"name": "r", "effect": "allow", "resource": "user", "actions": ["a"],
+			"conditions": [{"attribute": "actor.org_role", "operator": "in", "value": "owner"}]}]}`, "list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.rules))
+			if err == nil {
+				t.Fatal("Parse accepted an invalid policy")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionOperators(t *testing.T) {
+	actor := Actor{ID: 7, Roles: []string{"editor"}, Permissions: []string{"posts:write"}, OrganizationRole: "admin"}
+	resource := Resource{Type: "post", Attributes: map[string]interface{}{"owner_id": uint(7), "tags": []interface{}{"draft"}}}
+
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{`{"attribute": "actor.id", "operator": "eq", "value": 7}`, true},
+		{`{"attribute": "actor.id", "operator": "eq", "ref": "resource.owner_id"}`, true},
+		{`{"attribute": "actor.id", "operator": "ne", "ref": "resource.owner_id"}`, false},
+		{`{"attribute": "actor.id", "operator": "eq", "value": "7"}`, false},
+		{`{"attribute": "actor.org_role", "operator": "in", "value": ["owner", "admin"]}`, true},
+		{`{"attribute": "actor.org_role", "operator": "not_in", "value": ["owner", "admin"]}`, false},
+		{`{"attribute": "actor.permissions", "operator": "contains", "value": "posts:write"}`, true},
+		{`{"attribute": "actor.permissions", "operator": "not_contains", "value": "posts:write"}`, false},
+		{`{"attribute": "actor.roles", "operator": "not_contains", "value": "admin"}`, true},
+		{`{"attribute": "resource.tags", "operator": "contains", "value": "draft"}`, true},
+		{`{"attribute": "actor.impersonated", "operator": "eq", "value": false}`, true},
+		// Missing attributes compare as nil
+		{`{"attribute": "resource.missing", "operator": "eq", "value": null}`, true},
+		{`{"attribute": "resource.missing", "operator": "eq", "value": false}`, false},
+		{`{"attribute": "resource.missing", "operator": "not_contains", "value": "x"}`, true},
+	}
+
+	for _, tt := range tests {
+		rules, err := Parse([]byte(`{"rules": [{"name": "r", "effect": "allow", "resource": "post", "actions": ["read"], "conditions": [` + tt.condition + `]}]}`))
+		if err != nil {
+			t.Fatalf("Parse(%s) returned error: %v", tt.condition, err)
+		}
+		if got := rules.Can(actor, "read", resource).Allowed; got != tt.want {
+			t.Errorf("%s holds = %v, want %v", tt.condition, got, tt.want)
+		}
+	}
+}
+
+func TestRuleSetCan(t *testing.T) {
+	rules, err := Parse([]byte(`{"rules": [
+		{"name": "no-archived", "effect": "deny", "resource": "post", "actions": ["*"],
+			"conditions": [{"attribute": "resource.archived", "operator": "eq", "value": true}],
+			"message": "Archived posts are read-only"},
+		{"name": "owners", "effect": "allow", "resource": "post", "actions": ["edit", "delete"],
+			"conditions": [{"attribute": "actor.id", "operator": "eq", "ref": "resource.owner_id"}]},
+		{"name": "anyone-reads", "effect": "allow", "resource": "*", "actions": ["read"]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := Actor{ID: 1}
+	post := Resource{Type: "post", Attributes: map[string]interface{}{"owner_id": uint(1)}}
+	archived := Resource{Type: "post", Attributes: map[string]interface{}{"owner_id": uint(1), "archived": true}}
+
+	tests := []struct {
+		name     string
+		actor    Actor
+		action   string
+		resource Resource
+		want     Decision
+	}{
+		{"allow rule matches", owner, "edit", post, Decision{Allowed: true, Rule: "owners"}},
+		{"wildcard resource", Actor{ID: 2}, "read", Resource{Type: "comment"}, Decision{Allowed: true, Rule: "anyone-reads"}},
+		{"no rule allows", Actor{ID: 2}, "edit", post, Decision{}},
+		{"unknown action", owner, "publish", post, Decision{}},
+		{"deny wins over allow", owner, "edit", archived,
+			Decision{Rule: "no-archived", Message: "Archived posts are read-only"}},
+		{"deny applies to every action", owner, "read", archived,
+			Decision{Rule: "no-archived", Message: "Archived posts are read-only"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Can(tt.actor, tt.action, tt.resource); got != tt.want {
+				t.Errorf("Can = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	rules, err := Parse([]byte(`{"rules": [
+		{"name": "no-delete", "effect": "deny", "resource": "post", "actions": ["delete"], "message": "Posts cannot be deleted"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Authorize(rules, Actor{}, "delete", Resource{Type: "post"})
+	var denied *DeniedError
+	if !errors.As(err, &denied) || !errors.Is(err, ErrDenied) {
+		t.Fatalf("Authorize = %v, want a *DeniedError wrapping ErrDenied", err)
+	}
+	if denied.Rule != "no-delete" || err.Error() != "Posts cannot be deleted" {
+		t.Errorf("Authorize = %+v, want the denying rule and its message", denied)
+	}
+
+	// Actions no rule allows are denied with the generic message
+	if err := Authorize(rules, Actor{}, "read", Resource{Type: "post"}); err == nil || err.Error() != ErrDenied.Error() {
+		t.Errorf("Authorize without an allow rule = %v, want %v", err, ErrDenied)
+	}
+}
+
+// userResource builds a "user" resource with the attributes the services pass to the policy
+func userResource(id uint, roles []string, orgRole string, lastAdmin bool) Resource {
+	return Resource{
+		Type: "user",
+		Attributes: map[string]interface{}{
+			"id":         id,
+			"roles":      roles,
+			"has_roles":  len(roles) > 0,
+			"org_role":   orgRole,
+			"last_admin": lastAdmin,
+		},
+	}
+}
+
+// roleChange builds the resource of a change of the role of a user holding the admin role
+func roleChange(role string, lastAdmin bool) Resource {
+	resource := userResource(1, []string{"admin"}, "", lastAdmin)
+	resource.Attributes["role"] = role
+	return resource
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	rules, err := Load("")
+	if err != nil {
+		t.Fatalf("the default policy does not load: %v", err)
+	}
+
+	userAdmin := Actor{ID: 1, Roles: []string{"admin"}, Permissions: []string{"users:update", "users:delete"}}
+	orgOwner := Actor{ID: 2, OrganizationID: 1, OrganizationRole: "owner"}
+	orgAdmin := Actor{ID: 3, OrganizationID: 1, OrganizationRole: "admin"}
+	scim := Actor{Permissions: []string{}, OrganizationID: 1, SCIM: true}
+	roleAdmin := Actor{ID: 4, Permissions: []string{"roles:manage"}}
+	member := userResource(10, nil, "member", false)
+	memberWithRole := userResource(11, []string{"admin"}, "member", false)
+
+	tests := []struct {
+		name     string
+		actor    Actor
+		action   string
+		resource Resource
+		want     bool
+		rule     string
+	}{
+		{"users update themselves", Actor{ID: 10}, "users:update", member, true, "update-self"},
+		{"users change their own email", Actor{ID: 10}, "users:update_email", member, true, "update-self"},
+		{"role holders update themselves", Actor{ID: 11}, "users:update", memberWithRole, true, "update-self"},
+		{"impersonators cannot update the user", Actor{ID: 10, Impersonated: true}, "users:update", member, false, ""},
+		{"API keys cannot update their user", Actor{ID: 10, Scoped: true}, "users:update", member, false, ""},
+		{"user admins update anyone", userAdmin, "users:update", memberWithRole, true, "user-admins-update"},
+		{"user admins change emails", userAdmin, "users:update_email", memberWithRole, true, "user-admins-update"},
+		{"org admins update members", orgAdmin, "users:update", member, true, "org-admins-update-members"},
+		{"org admins cannot change member emails", orgAdmin, "users:update_email", member, false, ""},
+		{"org owners cannot change member emails", orgOwner, "users:update_email", member, false, ""},
+		{"org admins cannot update org admins", orgAdmin, "users:update", userResource(12, nil, "admin", false), false, ""},
+		{"org owners update org admins", orgOwner, "users:update", userResource(12, nil, "admin", false), true, "org-owners-update-admins"},
+		{"org admins cannot update role holders", orgAdmin, "users:update", memberWithRole, false, "protect-role-holders"},
+		{"org owners cannot update role holders", orgOwner, "users:update", memberWithRole, false, "protect-role-holders"},
+		{"scoped org admins cannot update members", Actor{ID: 3, OrganizationRole: "admin", Scoped: true}, "users:update", member, false, ""},
+		{"user admins delete users", userAdmin, "users:delete", member, true, "user-admins-delete"},
+		{"the last admin cannot be deleted", userAdmin, "users:delete", userResource(1, []string{"admin"}, "", true), false, "protect-last-admin"},
+		{"org owners cannot delete members", orgOwner, "users:delete", member, false, ""},
+		{"SCIM updates members", scim, "users:update", member, true, "scim-provisioning"},
+		{"SCIM changes member emails", scim, "users:update_email", member, true, "scim-provisioning"},
+		{"SCIM deletes members", scim, "users:delete", member, true, "scim-provisioning"},
+		{"SCIM cannot update role holders", scim, "users:update", memberWithRole, false, "protect-role-holders"},
+		{"SCIM cannot delete role holders", scim, "users:delete", memberWithRole, false, "scim-protect-role-holders"},
+		{"SCIM cannot delete the last admin", scim, "users:delete", userResource(1, []string{"admin"}, "member", true), false, "protect-last-admin"},
+		{"role admins assign roles", roleAdmin, "roles:assign", roleChange("support", false), true, "role-admins"},
+		{"role admins remove roles", roleAdmin, "roles:remove", roleChange("admin", false), true, "role-admins"},
+		{"user admins cannot assign roles", userAdmin, "roles:assign", roleChange("support", false), false, ""},
+		{"org owners cannot assign roles", orgOwner, "roles:assign", roleChange("support", false), false, ""},
+		{"SCIM cannot assign roles", scim, "roles:assign", roleChange("support", false), false, ""},
+		{"the last admin keeps the admin role", roleAdmin, "roles:remove", roleChange("admin", true), false, "protect-last-admin-role"},
+		{"the last admin loses other roles", roleAdmin, "roles:remove", roleChange("support", true), true, "role-admins"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := rules.Can(tt.actor, tt.action, tt.resource)
+			if decision.Allowed != tt.want || decision.Rule != tt.rule {
+				t.Errorf("Can = %+v, want allowed %v by rule %q", decision, tt.want, tt.rule)
+			}
+		})
+	}
+}
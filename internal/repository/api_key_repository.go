@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository interface defines API key repository methods
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	GetByHash(hash string) (*models.APIKey, error)
+	GetByUserID(userID uint) ([]models.APIKey, error)
+	Delete(userID, id uint) (bool, error)
+	TouchLastUsed(id uint, at time.Time) error
+}
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create stores a new API key
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetByHash gets an API key by the hash of its plaintext value
+func (r *apiKeyRepository) GetByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("key_hash = ?", hash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByUserID gets every API key of a user, newest first
+func (r *apiKeyRepository) GetByUserID(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+// Delete removes one of the user's API keys. It reports false when the user has no such key.
+func (r *apiKeyRepository) Delete(userID, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// TouchLastUsed records when the key was used. To avoid a write on every request
+// the timestamp is only moved forward once it is at least a minute old.
+func (r *apiKeyRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-time.Minute)).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EmailVerificationRepository interface defines email verification token repository methods
+type EmailVerificationRepository interface {
+	Create(token *models.EmailVerificationToken) error
+	GetByHash(hash string) (*models.EmailVerificationToken, error)
+	GetLatestForUser(userID uint) (*models.EmailVerificationToken, error)
+	MarkUsed(id uint) (bool, error)
+	DeleteForUser(userID uint) error
+	DeleteExpired() error
+}
+
+// emailVerificationRepository implements EmailVerificationRepository interface
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailVerificationRepository creates a new email verification repository
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+// Create stores a new verification token
+func (r *emailVerificationRepository) Create(token *models.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash gets a verification token by its hash
+func (r *emailVerificationRepository) GetByHash(hash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetLatestForUser gets the most recently issued verification token of the user
+func (r *emailVerificationRepository) GetLatestForUser(userID uint) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks an unused token as used. It reports false when the token was already used.
+func (r *emailVerificationRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteForUser removes every outstanding token of the user
+func (r *emailVerificationRepository) DeleteForUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.EmailVerificationToken{}).Error
+}
+
+// DeleteExpired removes tokens that are past their expiry
+func (r *emailVerificationRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.EmailVerificationToken{}).Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ImpersonationRepository interface defines impersonation audit repository methods
+type ImpersonationRepository interface {
+	Create(impersonation *models.Impersonation) error
+	GetByTokenID(tokenID string) (*models.Impersonation, error)
+	GetRecent(userID uint, limit int) ([]models.Impersonation, error)
+	End(id uint) (bool, error)
+	EndExpired() error
+	WithContext(ctx context.Context) ImpersonationRepository
+}
+
+// impersonationRepository implements ImpersonationRepository interface
+type impersonationRepository struct {
+	db *gorm.DB
+}
+
+// NewImpersonationRepository creates a new impersonation repository
+func NewImpersonationRepository(db *gorm.DB) ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+// WithContext returns a repository whose queries use ctx. Queries are scoped
+// to impersonations of members of the organization ctx selects, if any.
+func (r *impersonationRepository) WithContext(ctx context.Context) ImpersonationRepository {
+	return &impersonationRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create stores a new impersonation record
+func (r *impersonationRepository) Create(impersonation *models.Impersonation) error {
+	return r.db.Create(impersonation).Error
+}
+
+// GetByTokenID gets the impersonation of an impersonation token
+func (r *impersonationRepository) GetByTokenID(tokenID string) (*models.Impersonation, error) {
+	var impersonation models.Impersonation
+	err := r.db.Where("token_id = ?", tokenID).First(&impersonation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &impersonation, nil
+}
+
+// GetRecent gets the most recent impersonations, newest first. A userID other
+// than 0 limits them to the impersonations of that user or by that user.
+func (r *impersonationRepository) GetRecent(userID uint, limit int) ([]models.Impersonation, error) {
+	var impersonations []models.Impersonation
+	query := r.db.Order("started_at DESC").Limit(limit)
+	if userID != 0 {
+		query = query.Where("user_id = ? OR impersonator_id = ?", userID, userID)
+	}
+	err := query.Find(&impersonations).Error
+	return impersonations, err
+}
+
+// End records that an impersonation ended now. It reports false when it had already ended.
+func (r *impersonationRepository) End(id uint) (bool, error) {
+	result := r.db.Model(&models.Impersonation{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Update("ended_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// EndExpired records impersonations whose token expired as ended at the expiry time
+func (r *impersonationRepository) EndExpired() error {
+	return r.db.Model(&models.Impersonation{}).
+		Where("ended_at IS NULL AND expires_at < ?", time.Now()).
+		Update("ended_at", gorm.Expr("expires_at")).Error
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InvitationRepository interface defines organization invitation repository methods
+type InvitationRepository interface {
+	Create(invitation *models.OrganizationInvitation) error
+	GetByID(organizationID, id uint) (*models.OrganizationInvitation, error)
+	GetByHash(tokenHash string) (*models.OrganizationInvitation, error)
+	GetByOrganizationID(organizationID uint) ([]models.OrganizationInvitation, error)
+	GetPending(organizationID uint, email string) (*models.OrganizationInvitation, error)
+	RenewToken(id uint, tokenHash string, expiresAt time.Time) (bool, error)
+	Accept(invitation *models.OrganizationInvitation, userID uint) (bool, error)
+	Revoke(id uint) (bool, error)
+	ExpirePending() error
+}
+
+// invitationRepository implements InvitationRepository interface
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository creates a new invitation repository
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+// Create stores a new invitation
+func (r *invitationRepository) Create(invitation *models.OrganizationInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// GetByID gets an invitation of the organization by ID
+func (r *invitationRepository) GetByID(organizationID, id uint) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := r.db.Where("organization_id = ?", organizationID).First(&invitation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetByHash gets an invitation and its organization by token hash
+func (r *invitationRepository) GetByHash(tokenHash string) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := r.db.Preload("Organization").Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetByOrganizationID gets the organization's invitations, newest first
+func (r *invitationRepository) GetByOrganizationID(organizationID uint) ([]models.OrganizationInvitation, error) {
+	var invitations []models.OrganizationInvitation
+	err := r.db.Where("organization_id = ?", organizationID).Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// GetPending gets the unexpired pending invitation of an email address to the organization
+func (r *invitationRepository) GetPending(organizationID uint, email string) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := r.db.Where("organization_id = ? AND LOWER(email) = LOWER(?) AND status = ? AND expires_at > ?",
+		organizationID, email, models.InvitationStatusPending, time.Now()).
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// RenewToken replaces the token of a pending or expired invitation and makes it
+// pending until the new expiry. It reports false when it was accepted or revoked.
+func (r *invitationRepository) RenewToken(id uint, tokenHash string, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&models.OrganizationInvitation{}).
+		Where("id = ? AND status IN ?", id, []string{models.InvitationStatusPending, models.InvitationStatusExpired}).
+		Updates(map[string]interface{}{
+			"token_hash": tokenHash,
+			"expires_at": expiresAt,
+			"status":     models.InvitationStatusPending,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Accept marks a pending invitation as accepted by the user and adds the user to
+// the organization with the invited role in one transaction. It reports false
+// when the invitation is no longer pending, so each invitation is accepted once.
+func (r *invitationRepository) Accept(invitation *models.OrganizationInvitation, userID uint) (bool, error) {
+	accepted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.OrganizationInvitation{}).
+			Where("id = ? AND status = ?", invitation.ID, models.InvitationStatusPending).
+			Updates(map[string]interface{}{
+				"status":         models.InvitationStatusAccepted,
+				"accepted_at":    time.Now(),
+				"accepted_by_id": userID,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		accepted = true
+		return tx.Omit(clause.Associations).Create(&models.OrganizationMember{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         userID,
+			Role:           invitation.Role,
+		}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return accepted, nil
+}
+
+// Revoke marks a pending or expired invitation as revoked. It reports false when it was accepted or revoked.
+func (r *invitationRepository) Revoke(id uint) (bool, error) {
+	result := r.db.Model(&models.OrganizationInvitation{}).
+		Where("id = ? AND status IN ?", id, []string{models.InvitationStatusPending, models.InvitationStatusExpired}).
+		Update("status", models.InvitationStatusRevoked)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExpirePending marks pending invitations past their expiry as expired
+func (r *invitationRepository) ExpirePending() error {
+	return r.db.Model(&models.OrganizationInvitation{}).
+		Where("status = ? AND expires_at < ?", models.InvitationStatusPending, time.Now()).
+		Update("status", models.InvitationStatusExpired).Error
+}
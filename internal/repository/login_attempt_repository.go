@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository interface defines login history repository methods
+type LoginAttemptRepository interface {
+	Create(attempt *models.LoginAttempt) error
+	GetRecentByUserID(userID uint, limit int) ([]models.LoginAttempt, error)
+	HasSuccessfulLogin(userID uint) (bool, error)
+	SeenClient(userID uint, client models.ClientInfo) (deviceSeen bool, ipSeen bool, err error)
+	DeleteOlderThan(before time.Time) error
+}
+
+// loginAttemptRepository implements LoginAttemptRepository interface
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// Create stores a login attempt
+func (r *loginAttemptRepository) Create(attempt *models.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// GetRecentByUserID gets the user's most recent login attempts, newest first
+func (r *loginAttemptRepository) GetRecentByUserID(userID uint, limit int) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// HasSuccessfulLogin reports whether the user ever logged in successfully
+func (r *loginAttemptRepository) HasSuccessfulLogin(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.LoginAttempt{}).
+		Where("user_id = ? AND success = ?", userID, true).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SeenClient reports whether the user logged in successfully before with the
+// client's user agent and, separately, from the client's IP address
+func (r *loginAttemptRepository) SeenClient(userID uint, client models.ClientInfo) (bool, bool, error) {
+	var deviceCount, ipCount int64
+	err := r.db.Model(&models.LoginAttempt{}).
+		Where("user_id = ? AND success = ? AND user_agent = ?", userID, true, client.UserAgent).
+		Limit(1).
+		Count(&deviceCount).Error
+	if err != nil {
+		return false, false, err
+	}
+
+	err = r.db.Model(&models.LoginAttempt{}).
+		Where("user_id = ? AND success = ? AND ip_address = ?", userID, true, client.IPAddress).
+		Limit(1).
+		Count(&ipCount).Error
+	if err != nil {
+		return false, false, err
+	}
+	return deviceCount > 0, ipCount > 0, nil
+}
+
+// DeleteOlderThan removes login attempts recorded before the given time
+func (r *loginAttemptRepository) DeleteOlderThan(before time.Time) error {
+	return r.db.Where("created_at < ?", before).Delete(&models.LoginAttempt{}).Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MagicLinkRepository interface defines magic link token repository methods
+type MagicLinkRepository interface {
+	Create(token *models.MagicLinkToken) error
+	GetByHash(hash string) (*models.MagicLinkToken, error)
+	MarkUsed(id uint) (bool, error)
+	DeleteForUser(userID uint) error
+	DeleteExpired() error
+}
+
+// magicLinkRepository implements MagicLinkRepository interface
+type magicLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewMagicLinkRepository creates a new magic link repository
+func NewMagicLinkRepository(db *gorm.DB) MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+// Create stores a new magic link token
+func (r *magicLinkRepository) Create(token *models.MagicLinkToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash gets a magic link token by its hash
+func (r *magicLinkRepository) GetByHash(hash string) (*models.MagicLinkToken, error) {
+	var token models.MagicLinkToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks an unused token as used. It reports false when the token was already used.
+func (r *magicLinkRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.MagicLinkToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteForUser removes every outstanding token of the user
+func (r *magicLinkRepository) DeleteForUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.MagicLinkToken{}).Error
+}
+
+// DeleteExpired removes tokens that are past their expiry
+func (r *magicLinkRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.MagicLinkToken{}).Error
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OAuthRepository interface defines OAuth client and authorization code repository methods
+type OAuthRepository interface {
+	CreateClient(client *models.OAuthClient) error
+	GetClients() ([]models.OAuthClient, error)
+	GetClientByClientID(clientID string) (*models.OAuthClient, error)
+	DeleteClient(id uint) (bool, error)
+	CreateCode(code *models.OAuthAuthorizationCode) error
+	GetCodeByHash(hash string) (*models.OAuthAuthorizationCode, error)
+	DeleteCode(id uint) (bool, error)
+	DeleteExpiredCodes() error
+}
+
+// oauthRepository implements OAuthRepository interface
+type oauthRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthRepository creates a new OAuth repository
+func NewOAuthRepository(db *gorm.DB) OAuthRepository {
+	return &oauthRepository{db: db}
+}
+
+// CreateClient stores a new OAuth client
+func (r *oauthRepository) CreateClient(client *models.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+// GetClients gets every OAuth client, newest first
+func (r *oauthRepository) GetClients() ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	err := r.db.Order("created_at desc").Find(&clients).Error
+	return clients, err
+}
+
+// GetClientByClientID gets an OAuth client by its public client ID
+func (r *oauthRepository) GetClientByClientID(clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.Where("client_id = ?", clientID).First(&client).Error
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// DeleteClient removes an OAuth client together with its pending authorization
+// codes and refresh tokens. It reports false when no such client exists.
+func (r *oauthRepository) DeleteClient(id uint) (bool, error) {
+	var deleted bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var client models.OAuthClient
+		if err := tx.First(&client, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Where("client_id = ?", client.ClientID).Delete(&models.OAuthAuthorizationCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("client_id = ?", client.ClientID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&client).Error; err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	return deleted, err
+}
+
+// CreateCode stores a new authorization code
+func (r *oauthRepository) CreateCode(code *models.OAuthAuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+// GetCodeByHash gets an authorization code by its hash
+func (r *oauthRepository) GetCodeByHash(hash string) (*models.OAuthAuthorizationCode, error) {
+	var code models.OAuthAuthorizationCode
+	err := r.db.Where("code_hash = ?", hash).First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// DeleteCode removes an authorization code. It reports false when it was already redeemed.
+func (r *oauthRepository) DeleteCode(id uint) (bool, error) {
+	result := r.db.Delete(&models.OAuthAuthorizationCode{}, id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteExpiredCodes removes authorization codes that are past their expiry
+func (r *oauthRepository) DeleteExpiredCodes() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.OAuthAuthorizationCode{}).Error
+}
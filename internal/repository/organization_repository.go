@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationRepository interface defines organization and membership repository methods
+type OrganizationRepository interface {
+	CreateWithOwner(organization *models.Organization, ownerID uint) error
+	GetByID(id uint) (*models.Organization, error)
+	GetBySlug(slug string) (*models.Organization, error)
+	GetMembership(organizationID, userID uint) (*models.OrganizationMember, error)
+	GetMembershipsByUserID(userID uint) ([]models.OrganizationMember, error)
+	GetMembers(organizationID uint) ([]models.OrganizationMember, error)
+	AddMember(member *models.OrganizationMember) error
+	RemoveMember(organizationID, userID uint) (bool, error)
+	CountOwners(organizationID uint) (int64, error)
+}
+
+// organizationRepository implements OrganizationRepository interface
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a new organization repository
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+// CreateWithOwner creates an organization and makes the user its owner in one transaction
+func (r *organizationRepository) CreateWithOwner(organization *models.Organization, ownerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(&models.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         ownerID,
+			Role:           models.OrganizationRoleOwner,
+		}).Error
+	})
+}
+
+// GetByID gets an organization by ID
+func (r *organizationRepository) GetByID(id uint) (*models.Organization, error) {
+	var organization models.Organization
+	err := r.db.First(&organization, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// GetBySlug gets an organization by slug
+func (r *organizationRepository) GetBySlug(slug string) (*models.Organization, error) {
+	var organization models.Organization
+	err := r.db.Where("slug = ?", slug).First(&organization).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// GetMembership gets the user's membership of an organization
+func (r *organizationRepository) GetMembership(organizationID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembershipsByUserID gets the user's memberships with their organizations
+func (r *organizationRepository) GetMembershipsByUserID(userID uint) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := r.db.Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&members).Error
+	return members, err
+}
+
+// GetMembers gets the members of an organization with their users
+func (r *organizationRepository) GetMembers(organizationID uint) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := r.db.InnerJoins("User").
+		Where("organization_members.organization_id = ?", organizationID).
+		Order("organization_members.created_at").
+		Find(&members).Error
+	return members, err
+}
+
+// AddMember stores a new membership. The loaded user and organization are not saved.
+func (r *organizationRepository) AddMember(member *models.OrganizationMember) error {
+	return r.db.Omit(clause.Associations).Create(member).Error
+}
+
+// RemoveMember deletes a membership. It reports false when the user was not a member.
+func (r *organizationRepository) RemoveMember(organizationID, userID uint) (bool, error) {
+	result := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&models.OrganizationMember{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CountOwners counts the owners of an organization
+func (r *organizationRepository) CountOwners(organizationID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", organizationID, models.OrganizationRoleOwner).
+		Count(&count).Error
+	return count, err
+}
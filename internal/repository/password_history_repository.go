@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasswordHistoryRepository interface defines password history repository methods
+type PasswordHistoryRepository interface {
+	Create(entry *models.PasswordHistory) error
+	GetRecent(userID uint, limit int) ([]models.PasswordHistory, error)
+	Prune(userID uint, keep int) error
+}
+
+// passwordHistoryRepository implements PasswordHistoryRepository interface
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository creates a new password history repository
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+// Create stores a previous password hash
+func (r *passwordHistoryRepository) Create(entry *models.PasswordHistory) error {
+	return r.db.Create(entry).Error
+}
+
+// GetRecent gets the user's most recent previous password hashes, newest first
+func (r *passwordHistoryRepository) GetRecent(userID uint, limit int) ([]models.PasswordHistory, error) {
+	var entries []models.PasswordHistory
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// Prune deletes all but the user's keep most recent entries
+func (r *passwordHistoryRepository) Prune(userID uint, keep int) error {
+	recent := r.db.Model(&models.PasswordHistory{}).Select("id").
+		Where("user_id = ?", userID).Order("id DESC").Limit(keep)
+	return r.db.Where("user_id = ? AND id NOT IN (?)", userID, recent).Delete(&models.PasswordHistory{}).Error
+}
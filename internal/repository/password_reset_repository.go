@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository interface defines password reset token repository methods
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	GetByHash(hash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uint) (bool, error)
+	DeleteForUser(userID uint) error
+	DeleteExpired() error
+}
+
+// passwordResetRepository implements PasswordResetRepository interface
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository creates a new password reset repository
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create stores a new password reset token
+func (r *passwordResetRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash gets a password reset token by its hash
+func (r *passwordResetRepository) GetByHash(hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks an unused token as used. It reports false when the token was already used.
+func (r *passwordResetRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteForUser removes every outstanding token of the user
+func (r *passwordResetRepository) DeleteForUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.PasswordResetToken{}).Error
+}
+
+// DeleteExpired removes tokens that are past their expiry
+func (r *passwordResetRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.PasswordResetToken{}).Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository interface defines refresh token repository methods
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	GetByHash(hash string) (*models.RefreshToken, error)
+	MarkUsed(id uint) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID uint) error
+	DeleteExpired() error
+}
+
+// refreshTokenRepository implements RefreshTokenRepository interface
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create stores a new refresh token
+func (r *refreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash gets a refresh token by its hash
+func (r *refreshTokenRepository) GetByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks an active token as used. It reports false when the token
+// was already used or revoked, which makes concurrent rotation safe.
+func (r *refreshTokenRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily revokes every token that belongs to the given family
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser revokes every token issued to the given user
+func (r *refreshTokenRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired removes tokens that are past their expiry
+func (r *refreshTokenRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.RefreshToken{}).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RevokedTokenRepository interface defines access token revocation methods
+type RevokedTokenRepository interface {
+	Create(revoked *models.RevokedToken) error
+	IsRevoked(jti string, userID uint, issuedAt time.Time) (bool, error)
+	DeleteExpired() error
+}
+
+// revokedTokenRepository implements RevokedTokenRepository interface
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository creates a new revoked token repository
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepository {
+	return &revokedTokenRepository{db: db}
+}
+
+// Create stores a revocation entry
+func (r *revokedTokenRepository) Create(revoked *models.RevokedToken) error {
+	return r.db.Create(revoked).Error
+}
+
+// IsRevoked reports whether the token with the given JTI, or every token of
+// the user issued at issuedAt, has been revoked
+func (r *revokedTokenRepository) IsRevoked(jti string, userID uint, issuedAt time.Time) (bool, error) {
+	revoked := r.db.Where("user_id = ? AND issued_before > ?", userID, issuedAt)
+	if jti != "" {
+		revoked = revoked.Or("jti = ?", jti)
+	}
+
+	var count int64
+	err := r.db.Model(&models.RevokedToken{}).
+		Where("expires_at > ?", time.Now()).
+		Where(revoked).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteExpired removes entries whose tokens have all expired
+func (r *revokedTokenRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository interface defines role and permission repository methods
+type RoleRepository interface {
+	GetAll() ([]models.Role, error)
+	GetByName(name string) (*models.Role, error)
+	AssignToUser(userID uint, role *models.Role) error
+	RemoveFromUser(userID uint, role *models.Role) error
+	HasPermission(roleNames []string, permission string) (bool, error)
+	CountUsers(roleName string) (int64, error)
+}
+
+// roleRepository implements RoleRepository interface
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// GetAll gets every role with its permissions
+func (r *roleRepository) GetAll() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("name asc").Find(&roles).Error
+	return roles, err
+}
+
+// GetByName gets a role by name
+func (r *roleRepository) GetByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// AssignToUser adds a role to a user
+func (r *roleRepository) AssignToUser(userID uint, role *models.Role) error {
+	return r.db.Model(&models.User{ID: userID}).Association("Roles").Append(role)
+}
+
+// RemoveFromUser removes a role from a user
+func (r *roleRepository) RemoveFromUser(userID uint, role *models.Role) error {
+	return r.db.Model(&models.User{ID: userID}).Association("Roles").Delete(role)
+}
+
+// HasPermission reports whether any of the named roles grants the permission
+func (r *roleRepository) HasPermission(roleNames []string, permission string) (bool, error) {
+	if len(roleNames) == 0 {
+		return false, nil
+	}
+
+	var count int64
+	err := r.db.Table("roles").
+		Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("roles.name IN ? AND permissions.name = ?", roleNames, permission).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountUsers counts the users that hold the named role, excluding deleted users
+func (r *roleRepository) CountUsers(roleName string) (int64, error) {
+	var count int64
+	err := r.db.Table("users").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ? AND users.deleted_at IS NULL", roleName).
+		Count(&count).Error
+	return count, err
+}
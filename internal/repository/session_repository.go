@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository interface defines session repository methods
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id uint) (*models.Session, error)
+	GetByFamilyID(familyID string) (*models.Session, error)
+	GetActiveByUserID(userID uint) ([]models.Session, error)
+	Touch(id uint, client models.ClientInfo) error
+	TouchLastSeen(id uint) error
+	MarkAuthenticated(id uint, at time.Time) error
+	Revoke(id uint) error
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID uint) error
+	DeleteExpired(idleBefore, revokedBefore time.Time) error
+	WithContext(ctx context.Context) SessionRepository
+}
+
+// sessionRepository implements SessionRepository interface
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// WithContext returns a repository whose queries use ctx. Queries are scoped
+// to sessions of members of the organization ctx selects, if any.
+func (r *sessionRepository) WithContext(ctx context.Context) SessionRepository {
+	return &sessionRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create stores a new session
+func (r *sessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+// GetByID gets a session by ID
+func (r *sessionRepository) GetByID(id uint) (*models.Session, error) {
+	var session models.Session
+	err := r.db.First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByFamilyID gets the session of a refresh token family
+func (r *sessionRepository) GetByFamilyID(familyID string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.Where("family_id = ?", familyID).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveByUserID gets the user's sessions that have not been revoked, most recently used first
+func (r *sessionRepository) GetActiveByUserID(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).Order("last_seen_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records a token refresh from the given client
+func (r *sessionRepository) Touch(id uint, client models.ClientInfo) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"ip_address":   client.IPAddress,
+		"user_agent":   client.UserAgent,
+	}).Error
+}
+
+// MarkAuthenticated records that the user entered credentials on the session at the given time
+func (r *sessionRepository) MarkAuthenticated(id uint, at time.Time) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", id).UpdateColumn("authenticated_at", at).Error
+}
+
+// TouchLastSeen records that the session was used. Updates are throttled to
+// once a minute so authenticated requests do not each cause a write.
+func (r *sessionRepository) TouchLastSeen(id uint) error {
+	now := time.Now()
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND last_seen_at < ?", id, now.Add(-time.Minute)).
+		UpdateColumn("last_seen_at", now).Error
+}
+
+// Revoke revokes a session
+func (r *sessionRepository) Revoke(id uint) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeFamily revokes the session of a refresh token family
+func (r *sessionRepository) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser revokes every session of the given user
+func (r *sessionRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired removes sessions unused since idleBefore and sessions revoked before revokedBefore
+func (r *sessionRepository) DeleteExpired(idleBefore, revokedBefore time.Time) error {
+	return r.db.Where("last_seen_at < ? OR revoked_at < ?", idleBefore, revokedBefore).Delete(&models.Session{}).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SocialLoginRepository interface defines social login repository methods
+type SocialLoginRepository interface {
+	CreateState(state *models.SocialLoginState) error
+	GetStateByHash(hash string) (*models.SocialLoginState, error)
+	DeleteState(id uint) (bool, error)
+	DeleteExpiredStates() error
+	GetIdentity(provider, subject string) (*models.UserIdentity, error)
+	CreateIdentity(identity *models.UserIdentity) error
+	CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error
+}
+
+// socialLoginRepository implements SocialLoginRepository interface
+type socialLoginRepository struct {
+	db *gorm.DB
+}
+
+// NewSocialLoginRepository creates a new social login repository
+func NewSocialLoginRepository(db *gorm.DB) SocialLoginRepository {
+	return &socialLoginRepository{db: db}
+}
+
+// CreateState stores a pending social login
+func (r *socialLoginRepository) CreateState(state *models.SocialLoginState) error {
+	return r.db.Create(state).Error
+}
+
+// GetStateByHash gets a pending social login by the hash of its state parameter
+func (r *socialLoginRepository) GetStateByHash(hash string) (*models.SocialLoginState, error) {
+	var state models.SocialLoginState
+	err := r.db.Where("state_hash = ?", hash).First(&state).Error
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// DeleteState removes a pending social login. It reports false when it was already consumed.
+func (r *socialLoginRepository) DeleteState(id uint) (bool, error) {
+	result := r.db.Delete(&models.SocialLoginState{}, id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteExpiredStates removes pending social logins that are past their expiry
+func (r *socialLoginRepository) DeleteExpiredStates() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.SocialLoginState{}).Error
+}
+
+// GetIdentity gets the identity linked to an external account
+func (r *socialLoginRepository) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity links an external account to an existing user
+func (r *socialLoginRepository) CreateIdentity(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateUserWithIdentity creates a user and links the external account in one transaction
+func (r *socialLoginRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"time"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository interface defines two-factor authentication repository methods
+type TwoFactorRepository interface {
+	ReplaceRecoveryCodes(userID uint, codes []models.TwoFactorRecoveryCode) error
+	UseRecoveryCode(userID uint, hash string) (bool, error)
+	DeleteRecoveryCodes(userID uint) error
+	AdvanceLastStep(userID uint, step int64) (bool, error)
+	CreateChallenge(challenge *models.TwoFactorChallenge) error
+	GetChallengeByHash(hash string) (*models.TwoFactorChallenge, error)
+	IncrementChallengeAttempts(id uint) error
+	DeleteChallenge(id uint) (bool, error)
+	DeleteExpiredChallenges() error
+}
+
+// twoFactorRepository implements TwoFactorRepository interface
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// ReplaceRecoveryCodes deletes the user's recovery codes and stores the given ones
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uint, codes []models.TwoFactorRecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It reports false when no such code exists.
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, hash string) (bool, error) {
+	result := r.db.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteRecoveryCodes removes every recovery code of the user
+func (r *twoFactorRepository) DeleteRecoveryCodes(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error
+}
+
+// AdvanceLastStep records the last accepted TOTP step. It reports false when
+// the step is not newer than the recorded one, i.e. the code was replayed.
+func (r *twoFactorRepository) AdvanceLastStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", userID, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CreateChallenge stores a new login challenge
+func (r *twoFactorRepository) CreateChallenge(challenge *models.TwoFactorChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+// GetChallengeByHash gets a login challenge by its hash
+func (r *twoFactorRepository) GetChallengeByHash(hash string) (*models.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+	err := r.db.Where("token_hash = ?", hash).First(&challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// IncrementChallengeAttempts counts a failed verification attempt
+func (r *twoFactorRepository) IncrementChallengeAttempts(id uint) error {
+	return r.db.Model(&models.TwoFactorChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// DeleteChallenge removes a challenge. It reports false when it was already consumed.
+func (r *twoFactorRepository) DeleteChallenge(id uint) (bool, error) {
+	result := r.db.Delete(&models.TwoFactorChallenge{}, id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteExpiredChallenges removes challenges that are past their expiry
+func (r *twoFactorRepository) DeleteExpiredChallenges() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.TwoFactorChallenge{}).Error
+}
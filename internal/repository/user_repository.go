@@ -1,9 +1,15 @@
 package repository
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/tenant"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository interface defines user repository methods
@@ -12,8 +18,14 @@ type UserRepository interface {
 	GetByID(id uint) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	Update(user *models.User) error
+	UpdatePassword(id uint, hashedPassword string) error
 	Delete(id uint) error
 	GetAllWithFilter(req models.UserListRequest) ([]models.User, int64, error)
+	ListByEmail(operator, value string, offset, limit int) ([]models.User, int64, error)
+	RecordFailedLogin(id uint) (int, error)
+	LockUntil(id uint, until time.Time) error
+	ClearFailedLogins(id uint) error
+	WithContext(ctx context.Context) UserRepository
 }
 
 // userRepository implements UserRepository interface
@@ -26,34 +38,60 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-// Create creates a new user
+// WithContext returns a repository whose queries use ctx. Queries are scoped
+// to the members of the organization ctx selects, if any.
+func (r *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create creates a new user. Users created with a context that selects an
+// organization become members of it in the same transaction.
 func (r *userRepository) Create(user *models.User) error {
-	return r.db.Create(user).Error
+	organizationID, scoped := tenant.OrganizationID(r.db.Statement.Context)
+	if !scoped {
+		return r.db.Create(user).Error
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(&models.OrganizationMember{
+			OrganizationID: organizationID,
+			UserID:         user.ID,
+			Role:           models.OrganizationRoleMember,
+		}).Error
+	})
 }
 
 // GetByID gets a user by ID
 func (r *userRepository) GetByID(id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.First(&user, id).Error
+	err := r.db.Preload("Roles").First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// GetByEmail gets a user by email
+// GetByEmail gets a user by email, ignoring case and surrounding whitespace
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.Preload("Roles").Where("LOWER(email) = ?", models.NormalizeEmail(email)).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// Update updates a user
+// Update updates a user. Associations such as roles are managed separately.
 func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit(clause.Associations).Save(user).Error
+}
+
+// UpdatePassword replaces the stored password hash without touching other columns
+func (r *userRepository) UpdatePassword(id uint, hashedPassword string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumn("password", hashedPassword).Error
 }
 
 // Delete soft deletes a user
@@ -61,6 +99,33 @@ func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
 
+// RecordFailedLogin atomically increments the user's failed login counter and
+// returns the new count
+func (r *userRepository) RecordFailedLogin(id uint) (int, error) {
+	var user models.User
+	err := r.db.Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		Where("id = ?", id).
+		UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error
+	if err != nil {
+		return 0, err
+	}
+	return user.FailedLoginAttempts, nil
+}
+
+// LockUntil locks the user's account until the given time
+func (r *userRepository) LockUntil(id uint, until time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumn("locked_until", until).Error
+}
+
+// ClearFailedLogins resets the failed login counter and lifts any lockout
+func (r *userRepository) ClearFailedLogins(id uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error
+}
+
 // GetAllWithFilter gets all users with filters and pagination
 func (r *userRepository) GetAllWithFilter(req models.UserListRequest) ([]models.User, int64, error) {
 	var users []models.User
@@ -109,6 +174,35 @@ func (r *userRepository) GetAllWithFilter(req models.UserListRequest) ([]models.
 	offset := (req.Page - 1) * req.Limit
 
 	// Execute query with pagination
-	err = query.Order(orderBy).Limit(req.Limit).Offset(offset).Find(&users).Error
+	err = query.Preload("Roles").Order(orderBy).Limit(req.Limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+// ListByEmail gets a page of users ordered by ID and their total count. Emails
+// are matched case-insensitively with operator "eq" (equal), "co" (contains) or
+// "sw" (starts with); an empty operator matches every user.
+func (r *userRepository) ListByEmail(operator, value string, offset, limit int) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query := r.db.Model(&models.User{})
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+	switch operator {
+	case "eq":
+		query = query.Where("LOWER(email) = LOWER(?)", value)
+	case "co":
+		query = query.Where("email ILIKE ?", "%"+pattern+"%")
+	case "sw":
+		query = query.Where("email ILIKE ?", pattern+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit == 0 {
+		return users, total, nil
+	}
+
+	err := query.Order("id asc").Offset(offset).Limit(limit).Find(&users).Error
 	return users, total, err
 }
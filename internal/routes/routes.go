@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"golang-starter-kit/config"
 	"golang-starter-kit/internal/controller"
 	"golang-starter-kit/internal/middleware"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/utils"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,8 +18,30 @@ func SetupRoutes(
 	router *gin.Engine,
 	userController *controller.UserController,
 	authController *controller.AuthController,
-	jwtSecret string,
+	twoFactorController *controller.TwoFactorController,
+	wellKnownController *controller.WellKnownController,
+	roleController *controller.RoleController,
+	apiKeyController *controller.APIKeyController,
+	socialLoginController *controller.SocialLoginController,
+	oauthController *controller.OAuthController,
+	sessionController *controller.SessionController,
+	magicLinkController *controller.MagicLinkController,
+	impersonationController *controller.ImpersonationController,
+	loginHistoryController *controller.LoginHistoryController,
+	organizationController *controller.OrganizationController,
+	invitationController *controller.InvitationController,
+	scimController *controller.SCIMController,
+	tokenValidator middleware.TokenValidator,
+	apiKeyValidator middleware.APIKeyValidator,
+	permissionChecker middleware.PermissionChecker,
+	tenantResolver middleware.TenantResolver,
+	authCookies *utils.AuthCookies,
+	tenantCfg config.TenantConfig,
+	scimCfg config.SCIMConfig,
+	corsCfg config.CORSConfig,
 ) {
+	router.Use(middleware.CORS(corsCfg))
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -25,9 +50,26 @@ func SetupRoutes(
 		})
 	})
 
+	// Discovery endpoints
+	router.GET("/.well-known/jwks.json", wellKnownController.JWKS)
+	router.GET("/.well-known/openid-configuration", wellKnownController.OpenIDConfiguration)
+
 	// Swagger endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	authMiddleware := middleware.AuthMiddleware(tokenValidator, apiKeyValidator, authCookies)
+	// Credential management requires a user token, not an API key or OAuth client token
+	userToken := middleware.RequireUserToken()
+	// Impersonation tokens cannot change the impersonated user's credentials
+	notImpersonated := middleware.DenyImpersonation()
+	// Sensitive actions require the password to have been entered recently
+	recentAuth := middleware.RequireRecentAuth(15 * time.Minute)
+	// User management is scoped to the organization the request selects
+	tenantScope := middleware.TenantMiddleware(tenantResolver, tenantCfg)
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(permissionChecker, permission)
+	}
+
 	// API version 1
 	v1 := router.Group("/api/v1")
 	{
@@ -36,26 +78,128 @@ func SetupRoutes(
 		{
 			auth.POST("/register", authController.Register)
 			auth.POST("/login", middleware.RateLimitMiddleware(5, 15*time.Minute), authController.Login)
-			auth.POST("/logout", authController.Logout)
+			auth.POST("/2fa/verify", middleware.RateLimitMiddleware(10, 15*time.Minute), twoFactorController.Verify)
+			auth.POST("/refresh", authController.Refresh)
+			auth.POST("/logout", authMiddleware, userToken, authController.Logout)
+			auth.POST("/logout-all", authMiddleware, userToken, notImpersonated, authController.LogoutAll)
+			auth.POST("/impersonation/stop", authMiddleware, impersonationController.Stop)
+			auth.POST("/reauthenticate", authMiddleware, userToken, notImpersonated, middleware.RateLimitMiddleware(10, 15*time.Minute), authController.Reauthenticate)
+			auth.POST("/forgot-password", middleware.RateLimitMiddleware(5, 15*time.Minute), authController.ForgotPassword)
+			auth.POST("/reset-password", middleware.RateLimitMiddleware(10, 15*time.Minute), authController.ResetPassword)
+			auth.POST("/verify-email", middleware.RateLimitMiddleware(10, 15*time.Minute), authController.VerifyEmail)
+			auth.POST("/verify-email/resend", middleware.RateLimitMiddleware(5, 15*time.Minute), authController.ResendVerification)
+			auth.POST("/magic-link", middleware.RateLimitMiddleware(5, 15*time.Minute), magicLinkController.SendLink)
+			auth.POST("/magic-link/verify", middleware.RateLimitMiddleware(10, 15*time.Minute), magicLinkController.Verify)
+
+			// Social login (OAuth2 / OpenID Connect)
+			auth.GET("/oauth/providers", socialLoginController.Providers)
+			auth.GET("/oauth/:provider", middleware.RateLimitMiddleware(20, 15*time.Minute), socialLoginController.Redirect)
+			auth.GET("/oauth/:provider/callback", middleware.RateLimitMiddleware(20, 15*time.Minute), socialLoginController.Callback)
 		}
 
-		// User routes (public)
+		// User routes (protected, permission based)
 		users := v1.Group("/users")
+		users.Use(authMiddleware, tenantScope)
 		{
-			users.POST("", userController.CreateUser)                        // Create user
-			users.POST("/pagination", userController.GetUsersWithPagination) // Get users with pagination
-			users.GET("/:id", userController.GetUser)                        // Get user by ID
-			users.PUT("/:id", userController.UpdateUser)                     // Update user
-			users.DELETE("/:id", userController.DeleteUser)                  // Delete user
+			users.POST("", can(models.PermissionUsersCreate), userController.CreateUser)                      // Create user
+			users.POST("/pagination", can(models.PermissionUsersRead), userController.GetUsersWithPagination) // Get users with pagination
+			users.GET("/:id", can(models.PermissionUsersRead), userController.GetUser)                        // Get user by ID
+			users.PUT("/:id", recentAuth, userController.UpdateUser)                                          // Update user (authorization policy)
+			users.DELETE("/:id", recentAuth, userController.DeleteUser)                                       // Delete user (authorization policy)
+			users.POST("/:id/unlock", can(models.PermissionUsersUnlock), userController.UnlockUser)           // Unlock user
+
+			// Role assignment (authorization policy)
+			users.POST("/:id/roles", roleController.AssignRole)
+			users.DELETE("/:id/roles/:role", roleController.RemoveRole)
+
+			// Session management (admin)
+			users.GET("/:id/sessions", can(models.PermissionUsersSessions), sessionController.ListUserSessions)
+			users.DELETE("/:id/sessions/:session_id", can(models.PermissionUsersSessions), sessionController.RevokeUserSession)
+
+			// Impersonation (admin)
+			users.POST("/:id/impersonate", userToken, notImpersonated, can(models.PermissionUsersImpersonate), recentAuth, impersonationController.Start)
+		}
+
+		// OAuth2 authorization server routes
+		oauth := v1.Group("/oauth")
+		{
+			oauth.POST("/authorize", authMiddleware, userToken, notImpersonated, oauthController.Authorize)
+			oauth.POST("/token", middleware.RateLimitMiddleware(60, time.Minute), oauthController.Token)
+			oauth.POST("/introspect", oauthController.Introspect)
+			oauth.POST("/revoke", oauthController.Revoke)
+			oauth.GET("/userinfo", authMiddleware, oauthController.UserInfo)
+
+			// Client registration (admin)
+			oauth.POST("/clients", authMiddleware, can(models.PermissionOAuthClientsManage), oauthController.CreateClient)
+			oauth.GET("/clients", authMiddleware, can(models.PermissionOAuthClientsManage), oauthController.ListClients)
+			oauth.DELETE("/clients/:id", authMiddleware, can(models.PermissionOAuthClientsManage), oauthController.DeleteClient)
 		}
 
+		// Role routes (admin)
+		v1.GET("/roles", authMiddleware, can(models.PermissionRolesManage), roleController.ListRoles)
+
+		// Impersonation audit trail (admin)
+		v1.GET("/impersonations", authMiddleware, tenantScope, can(models.PermissionUsersImpersonate), impersonationController.List)
+
+		// Organization routes (protected)
+		organizations := v1.Group("/organizations")
+		organizations.Use(authMiddleware, userToken)
+		{
+			organizations.POST("", notImpersonated, organizationController.Create)
+			organizations.GET("", organizationController.List)
+			organizations.GET("/:id/members", organizationController.ListMembers)
+			organizations.POST("/:id/members", notImpersonated, can(models.PermissionOrganizationsAdd), organizationController.AddMember)
+			organizations.DELETE("/:id/members/:user_id", notImpersonated, organizationController.RemoveMember)
+
+			// Invitations
+			organizations.POST("/:id/invitations", notImpersonated, invitationController.Create)
+			organizations.GET("/:id/invitations", invitationController.List)
+			organizations.POST("/:id/invitations/:invitation_id/resend", notImpersonated, invitationController.Resend)
+			organizations.DELETE("/:id/invitations/:invitation_id", notImpersonated, invitationController.Revoke)
+		}
+
+		// Invitation acceptance (public, the token proves the invitation)
+		v1.POST("/invitations/accept", middleware.RateLimitMiddleware(10, 15*time.Minute), invitationController.Accept)
+
 		// Protected routes
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Use(authMiddleware)
 		{
 			// Profile routes (protected)
 			protected.GET("/profile", userController.GetProfile)
-			protected.PUT("/profile", userController.UpdateProfile)
+			protected.PUT("/profile", userToken, notImpersonated, recentAuth, userController.UpdateProfile)
+			protected.PUT("/profile/password", userToken, notImpersonated, userController.ChangePassword)
+
+			// Two-factor authentication routes (protected)
+			protected.POST("/profile/2fa/enroll", userToken, notImpersonated, twoFactorController.Enroll)
+			protected.POST("/profile/2fa/confirm", userToken, notImpersonated, twoFactorController.Confirm)
+			protected.POST("/profile/2fa/disable", userToken, notImpersonated, recentAuth, twoFactorController.Disable)
+			protected.POST("/profile/2fa/recovery-codes", userToken, notImpersonated, twoFactorController.RegenerateRecoveryCodes)
+
+			// API key routes (protected)
+			protected.POST("/profile/api-keys", userToken, notImpersonated, apiKeyController.CreateAPIKey)
+			protected.GET("/profile/api-keys", userToken, apiKeyController.ListAPIKeys)
+			protected.DELETE("/profile/api-keys/:id", userToken, notImpersonated, apiKeyController.RevokeAPIKey)
+
+			// Sessions
+			protected.GET("/profile/sessions", userToken, sessionController.ListSessions)
+			protected.DELETE("/profile/sessions/:id", userToken, notImpersonated, sessionController.RevokeSession)
+			protected.GET("/profile/login-history", userToken, loginHistoryController.List)
 		}
 	}
+
+	// SCIM 2.0 provisioning for identity providers, authenticated with the SCIM token and scoped to its organization
+	scim := router.Group("/scim/v2")
+	scim.Use(middleware.SCIMAuth(scimCfg))
+	{
+		scim.GET("/ServiceProviderConfig", scimController.ServiceProviderConfig)
+		scim.GET("/ResourceTypes", scimController.ResourceTypes)
+		scim.GET("/Schemas", scimController.Schemas)
+		scim.GET("/Users", scimController.ListUsers)
+		scim.POST("/Users", scimController.CreateUser)
+		scim.GET("/Users/:id", scimController.GetUser)
+		scim.PUT("/Users/:id", scimController.ReplaceUser)
+		scim.PATCH("/Users/:id", scimController.PatchUser)
+		scim.DELETE("/Users/:id", scimController.DeleteUser)
+	}
 }
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix marks plaintext API keys so they are recognisable in configs and secret scanners
+const apiKeyPrefix = "sk_"
+
+var (
+	// ErrInvalidAPIKey is returned when an API key is unknown, expired or belongs to a deleted user
+	ErrInvalidAPIKey = errors.New("invalid or expired API key")
+	// ErrAPIKeyNotFound is returned when the user has no API key with the given ID
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrInvalidScope is returned when an API key is requested with a permission the user does not hold
+	ErrInvalidScope = errors.New("scope is not a permission you hold")
+	// ErrInvalidExpiry is returned when an API key is requested with an expiry in the past
+	ErrInvalidExpiry = errors.New("expires_at must be in the future")
+)
+
+// APIKeyService interface defines API key management and validation methods
+type APIKeyService interface {
+	CreateAPIKey(userID uint, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error)
+	ListAPIKeys(userID uint) ([]models.APIKeyResponse, error)
+	RevokeAPIKey(userID, id uint) error
+	ValidateAPIKey(key string) (*utils.JWTClaims, error)
+}
+
+// apiKeyService implements APIKeyService interface
+type apiKeyService struct {
+	apiKeyRepo  repository.APIKeyRepository
+	userRepo    repository.UserRepository
+	roleService RoleService
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository, roleService RoleService) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		userRepo:    userRepo,
+		roleService: roleService,
+	}
+}
+
+// CreateAPIKey generates a new API key for the user. The plaintext key is only returned here.
+func (s *apiKeyService) CreateAPIKey(userID uint, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	// A key may only be limited to permissions the user holds
+	for _, scope := range req.Scopes {
+		allowed, err := s.roleService.HasPermission(user.RoleNames(), scope)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrInvalidScope
+		}
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	plain := apiKeyPrefix + secret
+
+	key := &models.APIKey{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    plain[:len(apiKeyPrefix)+8],
+		KeyHash:   utils.HashToken(plain),
+		Scopes:    strings.Join(req.Scopes, " "),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &models.CreateAPIKeyResponse{
+		APIKeyResponse: key.ToResponse(),
+		Key:            plain,
+	}, nil
+}
+
+// ListAPIKeys gets the user's API keys without their plaintext values
+func (s *apiKeyService) ListAPIKeys(userID uint) ([]models.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, key.ToResponse())
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey deletes one of the user's API keys
+func (s *apiKeyService) RevokeAPIKey(userID, id uint) error {
+	deleted, err := s.apiKeyRepo.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ValidateAPIKey resolves an API key to claims for its owner, limited to the key's scopes
+func (s *apiKeyService) ValidateAPIKey(plain string) (*utils.JWTClaims, error) {
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(utils.HashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.IsExpired(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(key.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+		return nil, err
+	}
+
+	return &utils.JWTClaims{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Roles:    user.RoleNames(),
+		Scope:    key.Scopes,
+		APIKeyID: key.ID,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuthenticatorSkipped is returned by an Authenticator that does not handle the
+// login, so the next authenticator in the chain gets to check it
+var ErrAuthenticatorSkipped = errors.New("login not handled by this authenticator")
+
+// Authenticator checks an email and password against one credential store.
+// It returns the authenticated user, ErrInvalidCredentials for a wrong password,
+// or ErrAuthenticatorSkipped when the account is not its to check.
+type Authenticator interface {
+	Authenticate(email, password string) (*models.User, error)
+}
+
+// authenticatorChain tries its authenticators in order until one handles the login
+type authenticatorChain []Authenticator
+
+// NewAuthenticatorChain creates an Authenticator that tries the authenticators in
+// order. Logins no authenticator handles fail with ErrInvalidCredentials.
+func NewAuthenticatorChain(authenticators ...Authenticator) Authenticator {
+	return authenticatorChain(authenticators)
+}
+
+// Authenticate returns the result of the first authenticator that handles the login
+func (c authenticatorChain) Authenticate(email, password string) (*models.User, error) {
+	for _, authenticator := range c {
+		user, err := authenticator.Authenticate(email, password)
+		if errors.Is(err, ErrAuthenticatorSkipped) {
+			continue
+		}
+		return user, err
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// localAuthenticator checks passwords against the hashes of local accounts
+type localAuthenticator struct {
+	userRepo repository.UserRepository
+	hasher   utils.PasswordHasher
+}
+
+// NewLocalAuthenticator creates an Authenticator for accounts whose password is stored locally
+func NewLocalAuthenticator(userRepo repository.UserRepository, hasher utils.PasswordHasher) Authenticator {
+	return &localAuthenticator{
+		userRepo: userRepo,
+		hasher:   hasher,
+	}
+}
+
+// Authenticate checks the password hash. Unknown emails and accounts of other sources are skipped.
+func (a *localAuthenticator) Authenticate(email, password string) (*models.User, error) {
+	user, err := a.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuthenticatorSkipped
+		}
+		return nil, err
+	}
+	if !user.IsLocal() {
+		return nil, ErrAuthenticatorSkipped
+	}
+
+	if !a.hasher.Verify(password, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Upgrade hashes made with an older algorithm or cost while the password is at hand
+	if a.hasher.NeedsRehash(user.Password) {
+		a.rehashPassword(user, password)
+	}
+	return user, nil
+}
+
+// rehashPassword stores a new hash of the password made with the current settings.
+// Failures are only logged; the old hash keeps working.
+func (a *localAuthenticator) rehashPassword(user *models.User, password string) {
+	hashedPassword, err := a.hasher.Hash(password)
+	if err == nil {
+		err = a.userRepo.UpdatePassword(user.ID, hashedPassword)
+	}
+	if err != nil {
+		log.Printf("failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// verifyPassword checks the password of a known user through the authenticator.
+// It reports false for a wrong password and returns other failures as errors.
+func verifyPassword(authenticator Authenticator, user *models.User, password string) (bool, error) {
+	authenticated, err := authenticator.Authenticate(user.Email, password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return false, nil
+		}
+		return false, err
+	}
+	return authenticated.ID == user.ID, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/policy"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/internal/tenant"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// AuthorizationService interface defines attribute-based authorization methods
+type AuthorizationService interface {
+	AuthorizeUser(ctx context.Context, claims *utils.JWTClaims, action string, userID uint) error
+	AuthorizeRole(ctx context.Context, claims *utils.JWTClaims, action string, userID uint, roleName string) error
+	AuthorizeSCIM(ctx context.Context, action string, userID uint) error
+}
+
+// authorizationService implements AuthorizationService interface
+type authorizationService struct {
+	policy           policy.Policy
+	userRepo         repository.UserRepository
+	roleRepo         repository.RoleRepository
+	organizationRepo repository.OrganizationRepository
+}
+
+// NewAuthorizationService creates a new authorization service
+func NewAuthorizationService(
+	policy policy.Policy,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	organizationRepo repository.OrganizationRepository,
+) AuthorizationService {
+	return &authorizationService{
+		policy:           policy,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		organizationRepo: organizationRepo,
+	}
+}
+
+// AuthorizeUser checks that the claims' user may perform the action on a user.
+// Besides the user's own attributes, the policy sees the user's role in the
+// organization ctx selects (resource.org_role) and whether the user is the
+// last admin (resource.last_admin). It returns a *policy.DeniedError when the
+// policy denies the action.
+func (s *authorizationService) AuthorizeUser(ctx context.Context, claims *utils.JWTClaims, action string, userID uint) error {
+	return s.authorizeClaims(ctx, claims, action, userID, nil)
+}
+
+// AuthorizeRole checks that the claims' user may give the user the role or
+// take it away (roles:assign and roles:remove). The policy sees the same user
+// attributes as in AuthorizeUser and the role's name as resource.role.
+func (s *authorizationService) AuthorizeRole(ctx context.Context, claims *utils.JWTClaims, action string, userID uint, roleName string) error {
+	return s.authorizeClaims(ctx, claims, action, userID, map[string]interface{}{"role": roleName})
+}
+
+// AuthorizeSCIM checks that an identity provider may perform the action on a
+// user through SCIM. The provider acts with no roles or permissions, within
+// the organization ctx selects; the policy tells it apart by actor.scim.
+func (s *authorizationService) AuthorizeSCIM(ctx context.Context, action string, userID uint) error {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	actor := policy.Actor{Permissions: []string{}, SCIM: true}
+	if organizationID, ok := tenant.OrganizationID(ctx); ok {
+		actor.OrganizationID = organizationID
+	}
+	return s.authorize(actor, action, user, nil)
+}
+
+// authorizeClaims checks the claims' user's action on a user, whose policy
+// resource gets the extra attributes
+func (s *authorizationService) authorizeClaims(ctx context.Context, claims *utils.JWTClaims, action string, userID uint, extra map[string]interface{}) error {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	actor, err := s.actor(ctx, claims)
+	if err != nil {
+		return err
+	}
+	return s.authorize(actor, action, user, extra)
+}
+
+// user gets the user an action is performed on, within the organization ctx selects
+func (s *authorizationService) user(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.WithContext(ctx).GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// authorize checks the actor's action on the user against the policy
+func (s *authorizationService) authorize(actor policy.Actor, action string, user *models.User, extra map[string]interface{}) error {
+	resource := user.PolicyResource()
+	for name, value := range extra {
+		resource.Attributes[name] = value
+	}
+	resource.Attributes["last_admin"] = false
+	if slices.Contains(user.RoleNames(), models.RoleAdmin) {
+		admins, err := s.roleRepo.CountUsers(models.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		resource.Attributes["last_admin"] = admins <= 1
+	}
+	if actor.OrganizationID != 0 {
+		role, err := s.organizationRole(actor.OrganizationID, user.ID)
+		if err != nil {
+			return err
+		}
+		resource.Attributes["org_role"] = role
+	}
+
+	return policy.Authorize(s.policy, actor, action, resource)
+}
+
+// actor describes the claims' user to the policy. Permissions are those the
+// roles grant, limited to the scopes of API keys and OAuth client tokens.
+func (s *authorizationService) actor(ctx context.Context, claims *utils.JWTClaims) (policy.Actor, error) {
+	actor := policy.Actor{
+		ID:           claims.UserID,
+		Roles:        claims.Roles,
+		Permissions:  []string{},
+		Impersonated: claims.IsImpersonated(),
+		Scoped:       claims.IsScoped(),
+	}
+
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return actor, err
+	}
+	for _, role := range roles {
+		if !claims.HasRole(role.Name) {
+			continue
+		}
+		for _, permission := range role.Permissions {
+			if claims.IsScoped() && !claims.HasScope(permission.Name) {
+				continue
+			}
+			if !slices.Contains(actor.Permissions, permission.Name) {
+				actor.Permissions = append(actor.Permissions, permission.Name)
+			}
+		}
+	}
+
+	if organizationID, ok := tenant.OrganizationID(ctx); ok {
+		actor.OrganizationID = organizationID
+		if actor.OrganizationRole, err = s.organizationRole(organizationID, claims.UserID); err != nil {
+			return actor, err
+		}
+	}
+	return actor, nil
+}
+
+// organizationRole gets the user's role in the organization, or "" when they are not a member
+func (s *authorizationService) organizationRole(organizationID, userID uint) (string, error) {
+	member, err := s.organizationRepo.GetMembership(organizationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
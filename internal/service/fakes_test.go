@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/internal/tenant"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeUserRepo keeps users in memory and, like the repository, looks emails up
+// ignoring case and surrounding whitespace. Methods the tests do not use panic
+// through the embedded nil interface.
+type fakeUserRepo struct {
+	repository.UserRepository
+	users []*models.User
+	// organizations lists the organizations each user ID belongs to
+	organizations map[uint][]uint
+}
+
+// WithContext returns a view that, like the tenant callbacks, only sees the
+// members of the organization ctx selects
+func (r *fakeUserRepo) WithContext(ctx context.Context) repository.UserRepository {
+	if organizationID, ok := tenant.OrganizationID(ctx); ok {
+		return &scopedUserRepo{fakeUserRepo: r, organizationID: organizationID}
+	}
+	return r
+}
+
+func (r *fakeUserRepo) Create(user *models.User) error {
+	user.ID = uint(len(r.users) + 1)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, strings.TrimSpace(email)) {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) Update(user *models.User) error {
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	for i, user := range r.users {
+		if user.ID == id {
+			r.users = append(r.users[:i], r.users[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(id uint, hashedPassword string) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	return nil
+}
+
+func (r *fakeUserRepo) RecordFailedLogin(id uint) (int, error) {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+	user.FailedLoginAttempts++
+	return user.FailedLoginAttempts, nil
+}
+
+func (r *fakeUserRepo) LockUntil(id uint, until time.Time) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.LockedUntil = &until
+	return nil
+}
+
+func (r *fakeUserRepo) ClearFailedLogins(id uint) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	return nil
+}
+
+// scopedUserRepo is a fakeUserRepo limited to the members of an organization
+type scopedUserRepo struct {
+	*fakeUserRepo
+	organizationID uint
+}
+
+func (r *scopedUserRepo) member(id uint) bool {
+	return slices.Contains(r.organizations[id], r.organizationID)
+}
+
+func (r *scopedUserRepo) GetByID(id uint) (*models.User, error) {
+	if !r.member(id) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.fakeUserRepo.GetByID(id)
+}
+
+func (r *scopedUserRepo) Update(user *models.User) error {
+	if !r.member(user.ID) {
+		return nil
+	}
+	return r.fakeUserRepo.Update(user)
+}
+
+func (r *scopedUserRepo) Delete(id uint) error {
+	if !r.member(id) {
+		return nil
+	}
+	return r.fakeUserRepo.Delete(id)
+}
+
+// fakeRoleRepo keeps roles in memory and assigns them to the users of a fakeUserRepo
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	users *fakeUserRepo
+	roles []models.Role
+}
+
+func (r *fakeRoleRepo) GetAll() ([]models.Role, error) {
+	return r.roles, nil
+}
+
+func (r *fakeRoleRepo) GetByName(name string) (*models.Role, error) {
+	for i := range r.roles {
+		if r.roles[i].Name == name {
+			return &r.roles[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoleRepo) AssignToUser(userID uint, role *models.Role) error {
+	user, err := r.users.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(user.RoleNames(), role.Name) {
+		user.Roles = append(user.Roles, *role)
+	}
+	return nil
+}
+
+func (r *fakeRoleRepo) RemoveFromUser(userID uint, role *models.Role) error {
+	user, err := r.users.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	user.Roles = slices.DeleteFunc(user.Roles, func(held models.Role) bool { return held.Name == role.Name })
+	return nil
+}
+
+func (r *fakeRoleRepo) CountUsers(roleName string) (int64, error) {
+	var count int64
+	for _, user := range r.users.users {
+		if slices.Contains(user.RoleNames(), roleName) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeOrganizationRepo reports the organizations of a fakeUserRepo as plain memberships
+type fakeOrganizationRepo struct {
+	repository.OrganizationRepository
+	users *fakeUserRepo
+}
+
+func (r *fakeOrganizationRepo) GetMembership(organizationID, userID uint) (*models.OrganizationMember, error) {
+	if !slices.Contains(r.users.organizations[userID], organizationID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: models.OrganizationRoleMember}, nil
+}
+
+// fakeSessionRepo keeps sessions in memory
+type fakeSessionRepo struct {
+	repository.SessionRepository
+	sessions []*models.Session
+}
+
+func (r *fakeSessionRepo) WithContext(ctx context.Context) repository.SessionRepository {
+	return r
+}
+
+func (r *fakeSessionRepo) Create(session *models.Session) error {
+	session.ID = uint(len(r.sessions) + 1)
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *fakeSessionRepo) GetByID(id uint) (*models.Session, error) {
+	for _, session := range r.sessions {
+		if session.ID == id {
+			return session, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSessionRepo) GetByFamilyID(familyID string) (*models.Session, error) {
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID {
+			return session, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSessionRepo) GetActiveByUserID(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepo) Touch(id uint, client models.ClientInfo) error {
+	return nil
+}
+
+func (r *fakeSessionRepo) TouchLastSeen(id uint) error {
+	return nil
+}
+
+func (r *fakeSessionRepo) MarkAuthenticated(id uint, at time.Time) error {
+	session, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	session.AuthenticatedAt = &at
+	return nil
+}
+
+func (r *fakeSessionRepo) Revoke(id uint) error {
+	session, err := r.GetByID(id)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeFamily(familyID string) error {
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID {
+			_ = r.Revoke(session.ID)
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeAllForUser(userID uint) error {
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			_ = r.Revoke(session.ID)
+		}
+	}
+	return nil
+}
+
+// fakeRefreshRepo keeps refresh tokens in memory
+type fakeRefreshRepo struct {
+	repository.RefreshTokenRepository
+	tokens          []*models.RefreshToken
+	revokedFamilies []string
+}
+
+func (r *fakeRefreshRepo) Create(token *models.RefreshToken) error {
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeRefreshRepo) GetByHash(hash string) (*models.RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRefreshRepo) MarkUsed(id uint) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			now := time.Now()
+			token.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRefreshRepo) RevokeFamily(familyID string) error {
+	r.revokedFamilies = append(r.revokedFamilies, familyID)
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeRefreshRepo) RevokeAllForUser(userID uint) error {
+	return nil
+}
+
+// fakeRevokedRepo keeps revocation entries in memory and matches them like the repository's query
+type fakeRevokedRepo struct {
+	revoked []*models.RevokedToken
+}
+
+func (r *fakeRevokedRepo) Create(revoked *models.RevokedToken) error {
+	r.revoked = append(r.revoked, revoked)
+	return nil
+}
+
+func (r *fakeRevokedRepo) IsRevoked(jti string, userID uint, issuedAt time.Time) (bool, error) {
+	for _, revoked := range r.revoked {
+		if !revoked.ExpiresAt.After(time.Now()) {
+			continue
+		}
+		if revoked.UserID == userID && revoked.IssuedBefore != nil && revoked.IssuedBefore.After(issuedAt) {
+			return true, nil
+		}
+		if jti != "" && revoked.JTI == jti {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRevokedRepo) DeleteExpired() error {
+	return nil
+}
+
+// fakeTokenService issues a token naming the user instead of a signed JWT
+// and signs nobody out
+type fakeTokenService struct {
+	TokenService
+}
+
+func (fakeTokenService) IssueTokens(user *models.User, client models.ClientInfo) (*models.LoginResponse, error) {
+	return &models.LoginResponse{Token: "token for " + user.Email}, nil
+}
+
+func (fakeTokenService) Logout(claims *utils.JWTClaims, refreshToken string) error {
+	return nil
+}
+
+func (fakeTokenService) LogoutAll(userID uint) error {
+	return nil
+}
+
+func (fakeTokenService) RevokeAccessTokens(userID uint) error {
+	return nil
+}
+
+// fakeHasher stores passwords with a readable prefix instead of a real hash
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (fakeHasher) Verify(password, hash string) bool {
+	return hash == "hashed:"+password
+}
+
+func (fakeHasher) NeedsRehash(hash string) bool {
+	return false
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/internal/tenant"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// impersonationListLimit caps how many audit records are listed at once
+const impersonationListLimit = 100
+
+var (
+	// ErrCannotImpersonateSelf is returned when an admin tries to impersonate themselves
+	ErrCannotImpersonateSelf = errors.New("you cannot impersonate yourself")
+	// ErrImpersonationNotAllowed is returned when the target user holds permissions the admin lacks
+	ErrImpersonationNotAllowed = errors.New("you cannot impersonate a user with permissions you do not have")
+	// ErrNotImpersonating is returned when stopping with a token that is not an impersonation token
+	ErrNotImpersonating = errors.New("the token is not an impersonation token")
+)
+
+// ImpersonationService interface defines admin impersonation methods
+type ImpersonationService interface {
+	Start(ctx context.Context, impersonator *utils.JWTClaims, userID uint, req models.ImpersonateRequest, client models.ClientInfo) (*models.ImpersonationTokenResponse, error)
+	Stop(claims *utils.JWTClaims) error
+	List(ctx context.Context, userID uint) ([]models.ImpersonationResponse, error)
+	PurgeExpired() error
+}
+
+// impersonationService implements ImpersonationService interface
+type impersonationService struct {
+	impersonationRepo repository.ImpersonationRepository
+	userRepo          repository.UserRepository
+	roleRepo          repository.RoleRepository
+	tokenService      TokenService
+	keys              *utils.KeySet
+	authCfg           config.AuthConfig
+}
+
+// NewImpersonationService creates a new impersonation service
+func NewImpersonationService(
+	impersonationRepo repository.ImpersonationRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	tokenService TokenService,
+	keys *utils.KeySet,
+	authCfg config.AuthConfig,
+) ImpersonationService {
+	return &impersonationService{
+		impersonationRepo: impersonationRepo,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		tokenService:      tokenService,
+		keys:              keys,
+		authCfg:           authCfg,
+	}
+}
+
+// Start issues a short-lived access token that acts as the user. The token
+// carries the admin in its act claim, has no refresh token and no session,
+// and the impersonation is recorded before the token is returned. Within an
+// organization only its members can be impersonated and the token is bound to it.
+func (s *impersonationService) Start(ctx context.Context, impersonator *utils.JWTClaims, userID uint, req models.ImpersonateRequest, client models.ClientInfo) (*models.ImpersonationTokenResponse, error) {
+	if impersonator.UserID == userID {
+		return nil, ErrCannotImpersonateSelf
+	}
+
+	user, err := s.userRepo.WithContext(ctx).GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	// Impersonating must not grant the admin anything they cannot already do
+	allowed, err := s.coversPermissions(impersonator.Roles, user.RoleNames())
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	tokenID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	impersonation := &models.Impersonation{
+		ImpersonatorID: impersonator.UserID,
+		UserID:         user.ID,
+		Reason:         req.Reason,
+		TokenID:        tokenID,
+		IPAddress:      client.IPAddress,
+		UserAgent:      client.UserAgent,
+		StartedAt:      now,
+		ExpiresAt:      now.Add(s.authCfg.ImpersonationTTL),
+	}
+	if err := s.impersonationRepo.Create(impersonation); err != nil {
+		return nil, err
+	}
+
+	claims := utils.JWTClaims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Roles:  user.RoleNames(),
+		Actor: &utils.ActorClaims{
+			Subject: strconv.FormatUint(uint64(impersonator.UserID), 10),
+			UserID:  impersonator.UserID,
+			Email:   impersonator.Email,
+		},
+	}
+	claims.ID = tokenID
+	if organizationID, ok := tenant.OrganizationID(ctx); ok {
+		claims.OrganizationID = organizationID
+	}
+	token, expiresAt, err := utils.GenerateToken(claims, s.keys, s.authCfg.ImpersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[impersonation] user %d started impersonating user %d (impersonation %d): %s",
+		impersonator.UserID, user.ID, impersonation.ID, req.Reason)
+
+	return &models.ImpersonationTokenResponse{
+		Token:         token,
+		TokenType:     "Bearer",
+		ExpiresAt:     expiresAt,
+		User:          user.ToResponse(),
+		Impersonation: impersonation.ToResponse(),
+	}, nil
+}
+
+// Stop ends the impersonation of the presented token and revokes the token
+func (s *impersonationService) Stop(claims *utils.JWTClaims) error {
+	if !claims.IsImpersonated() {
+		return ErrNotImpersonating
+	}
+
+	impersonation, err := s.impersonationRepo.GetByTokenID(claims.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotImpersonating
+		}
+		return err
+	}
+
+	ended, err := s.impersonationRepo.End(impersonation.ID)
+	if err != nil {
+		return err
+	}
+	if ended {
+		log.Printf("[impersonation] user %d stopped impersonating user %d (impersonation %d)",
+			impersonation.ImpersonatorID, impersonation.UserID, impersonation.ID)
+	}
+
+	return s.tokenService.Logout(claims, "")
+}
+
+// List lists the most recent impersonations, newest first. A userID other than
+// 0 limits them to impersonations of or by that user. Within an organization
+// only impersonations of its members are listed.
+func (s *impersonationService) List(ctx context.Context, userID uint) ([]models.ImpersonationResponse, error) {
+	impersonations, err := s.impersonationRepo.WithContext(ctx).GetRecent(userID, impersonationListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.ImpersonationResponse, 0, len(impersonations))
+	for i := range impersonations {
+		responses = append(responses, impersonations[i].ToResponse())
+	}
+	return responses, nil
+}
+
+// PurgeExpired records impersonations whose token expired without being stopped
+// as ended. The audit records themselves are kept.
+func (s *impersonationService) PurgeExpired() error {
+	return s.impersonationRepo.EndExpired()
+}
+
+// coversPermissions reports whether the admin's roles grant every permission the target's roles grant
+func (s *impersonationService) coversPermissions(adminRoles, targetRoles []string) (bool, error) {
+	if len(targetRoles) == 0 {
+		return true, nil
+	}
+
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return false, err
+	}
+
+	granted := make(map[string]bool)
+	for _, role := range roles {
+		if !slices.Contains(adminRoles, role.Name) {
+			continue
+		}
+		for _, p := range role.Permissions {
+			granted[p.Name] = true
+		}
+	}
+
+	for _, role := range roles {
+		if !slices.Contains(targetRoles, role.Name) {
+			continue
+		}
+		for _, p := range role.Permissions {
+			if !granted[p.Name] {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
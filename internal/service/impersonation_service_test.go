@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/internal/tenant"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeImpersonationRepo keeps impersonation records in memory
+type fakeImpersonationRepo struct {
+	repository.ImpersonationRepository
+	impersonations []*models.Impersonation
+}
+
+func (r *fakeImpersonationRepo) WithContext(ctx context.Context) repository.ImpersonationRepository {
+	return r
+}
+
+func (r *fakeImpersonationRepo) Create(impersonation *models.Impersonation) error {
+	impersonation.ID = uint(len(r.impersonations) + 1)
+	r.impersonations = append(r.impersonations, impersonation)
+	return nil
+}
+
+func (r *fakeImpersonationRepo) GetByTokenID(tokenID string) (*models.Impersonation, error) {
+	for _, impersonation := range r.impersonations {
+		if impersonation.TokenID == tokenID {
+			return impersonation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeImpersonationRepo) End(id uint) (bool, error) {
+	for _, impersonation := range r.impersonations {
+		if impersonation.ID == id && impersonation.EndedAt == nil {
+			now := time.Now()
+			impersonation.EndedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type impersonationTest struct {
+	service ImpersonationService
+	repo    *fakeImpersonationRepo
+	users   *fakeUserRepo
+	keys    *utils.KeySet
+	// admin is the support admin, who holds users:read only
+	admin *utils.JWTClaims
+}
+
+// newImpersonationTest creates Alice, a support admin, Jane, a support user
+// and John, an admin
+func newImpersonationTest() *impersonationTest {
+	support := models.Role{Name: "support", Permissions: []models.Permission{{Name: "users:read"}}}
+	admin := models.Role{Name: "admin", Permissions: []models.Permission{{Name: "users:read"}, {Name: "roles:manage"}}}
+
+	users := &fakeUserRepo{}
+	_ = users.Create(&models.User{Name: "Alice", Email: "alice@example.com", Roles: []models.Role{support}})
+	_ = users.Create(&models.User{Name: "Jane", Email: "jane@example.com", Roles: []models.Role{support}})
+	_ = users.Create(&models.User{Name: "John", Email: "john@example.com", Roles: []models.Role{admin}})
+	roles := &fakeRoleRepo{users: users, roles: []models.Role{support, admin}}
+
+	repo := &fakeImpersonationRepo{}
+	keys := utils.NewHMACKeySet("secret", "test")
+	return &impersonationTest{
+		service: NewImpersonationService(repo, users, roles, fakeTokenService{}, keys,
+			config.AuthConfig{ImpersonationTTL: 15 * time.Minute}),
+		repo:  repo,
+		users: users,
+		keys:  keys,
+		admin: &utils.JWTClaims{UserID: 1, Email: "alice@example.com", Roles: []string{"support"}},
+	}
+}
+
+func TestImpersonationTokenNamesTheAdmin(t *testing.T) {
+	tt := newImpersonationTest()
+
+	response, err := tt.service.Start(context.Background(), tt.admin, 2, models.ImpersonateRequest{Reason: "Ticket #1"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	claims, err := utils.ValidateToken(response.Token, tt.keys)
+	if err != nil {
+		t.Fatalf("the impersonation token does not validate: %v", err)
+	}
+
+	if claims.UserID != 2 || claims.Email != "jane@example.com" {
+		t.Errorf("token acts as user %d %q, want Jane", claims.UserID, claims.Email)
+	}
+	if !claims.IsImpersonated() {
+		t.Fatal("the token has no act claim")
+	}
+	if claims.Actor.Subject != "1" || claims.Actor.UserID != 1 || claims.Actor.Email != "alice@example.com" {
+		t.Errorf("act claim %+v, want Alice", claims.Actor)
+	}
+	if claims.AuthTime != nil {
+		t.Error("the impersonation token carries an auth_time, so it passes recent-authentication checks")
+	}
+	if time.Until(claims.ExpiresAt.Time) > 15*time.Minute {
+		t.Errorf("token expires at %v, want within the impersonation TTL", claims.ExpiresAt.Time)
+	}
+
+	if len(tt.repo.impersonations) != 1 {
+		t.Fatalf("%d impersonations were recorded, want 1", len(tt.repo.impersonations))
+	}
+	if record := tt.repo.impersonations[0]; record.TokenID != claims.ID || record.ImpersonatorID != 1 || record.UserID != 2 || record.Reason != "Ticket #1" {
+		t.Errorf("recorded %+v, want Alice impersonating Jane with the token's ID", record)
+	}
+}
+
+func TestImpersonationTokenBindsTheOrganization(t *testing.T) {
+	tt := newImpersonationTest()
+	tt.users.organizations = map[uint][]uint{1: {7}, 2: {7}}
+
+	response, err := tt.service.Start(tenant.WithOrganization(context.Background(), 7), tt.admin, 2, models.ImpersonateRequest{Reason: "Ticket #1"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	claims, err := utils.ValidateToken(response.Token, tt.keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.OrganizationID != 7 {
+		t.Errorf("token bound to organization %d, want 7", claims.OrganizationID)
+	}
+
+	// John is not a member of the organization
+	if _, err := tt.service.Start(tenant.WithOrganization(context.Background(), 7), tt.admin, 3, models.ImpersonateRequest{Reason: "Ticket #1"}, models.ClientInfo{}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Start for a user outside the organization = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestImpersonationRefusals(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		wantErr error
+	}{
+		{"self", 1, ErrCannotImpersonateSelf},
+		{"user with more permissions", 3, ErrImpersonationNotAllowed},
+		{"unknown user", 9, ErrUserNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newImpersonationTest()
+
+			if _, err := tt.service.Start(context.Background(), tt.admin, tc.userID, models.ImpersonateRequest{Reason: "Ticket #1"}, models.ClientInfo{}); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Start = %v, want %v", err, tc.wantErr)
+			}
+			if len(tt.repo.impersonations) != 0 {
+				t.Error("a refused impersonation was recorded")
+			}
+		})
+	}
+}
+
+func TestImpersonationStop(t *testing.T) {
+	tt := newImpersonationTest()
+
+	if err := tt.service.Stop(tt.admin); !errors.Is(err, ErrNotImpersonating) {
+		t.Errorf("Stop with an ordinary token = %v, want ErrNotImpersonating", err)
+	}
+
+	response, err := tt.service.Start(context.Background(), tt.admin, 2, models.ImpersonateRequest{Reason: "Ticket #1"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := utils.ValidateToken(response.Token, tt.keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tt.service.Stop(claims); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	if tt.repo.impersonations[0].EndedAt == nil {
+		t.Error("the impersonation was not ended")
+	}
+}
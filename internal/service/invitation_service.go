@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/mailer"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvitationNotFound is returned when an invitation does not exist in the organization
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationPending is returned when the email address already has a pending invitation
+	ErrInvitationPending = errors.New("an invitation for this email address is already pending")
+	// ErrInvitationClosed is returned when resending or revoking an invitation that was accepted or revoked
+	ErrInvitationClosed = errors.New("the invitation was already accepted or revoked")
+	// ErrInvalidInvitation is returned for unknown, expired, revoked or already accepted invitation tokens
+	ErrInvalidInvitation = errors.New("invalid or expired invitation")
+	// ErrInvitationAccountRequired is returned when accepting without an account and without the details to create one
+	ErrInvitationAccountRequired = errors.New("name and password are required to create an account")
+)
+
+// InvitationService interface defines organization invitation methods
+type InvitationService interface {
+	Create(organizationID, actorID uint, req models.CreateInvitationRequest) (*models.InvitationResponse, error)
+	List(organizationID, actorID uint) ([]models.InvitationResponse, error)
+	Resend(organizationID, actorID, invitationID uint) (*models.InvitationResponse, error)
+	Revoke(organizationID, actorID, invitationID uint) error
+	Accept(ctx context.Context, req models.AcceptInvitationRequest) (*models.AcceptInvitationResponse, error)
+	PurgeExpired() error
+}
+
+// invitationService implements InvitationService interface
+type invitationService struct {
+	invitationRepo   repository.InvitationRepository
+	organizationRepo repository.OrganizationRepository
+	userRepo         repository.UserRepository
+	userService      UserService
+	mailer           mailer.Mailer
+	appCfg           config.AppConfig
+	authCfg          config.AuthConfig
+}
+
+// NewInvitationService creates a new invitation service
+func NewInvitationService(
+	invitationRepo repository.InvitationRepository,
+	organizationRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	userService UserService,
+	mailer mailer.Mailer,
+	appCfg config.AppConfig,
+	authCfg config.AuthConfig,
+) InvitationService {
+	return &invitationService{
+		invitationRepo:   invitationRepo,
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		userService:      userService,
+		mailer:           mailer,
+		appCfg:           appCfg,
+		authCfg:          authCfg,
+	}
+}
+
+// Create invites an email address to the organization with a role and emails
+// the invitation link. Owners and admins invite members and admins; only owners
+// invite owners.
+func (s *invitationService) Create(organizationID, actorID uint, req models.CreateInvitationRequest) (*models.InvitationResponse, error) {
+	actor, err := s.manager(organizationID, actorID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Existing members cannot be invited again
+	if user, err := s.userRepo.GetByEmail(req.Email); err == nil {
+		if _, err := s.organizationRepo.GetMembership(organizationID, user.ID); err == nil {
+			return nil, ErrAlreadyOrganizationMember
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if _, err := s.invitationRepo.GetPending(organizationID, req.Email); err == nil {
+		return nil, ErrInvitationPending
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.OrganizationInvitation{
+		OrganizationID: organizationID,
+		Email:          models.NormalizeEmail(req.Email),
+		Role:           req.Role,
+		TokenHash:      utils.HashToken(token),
+		InvitedByID:    actor.UserID,
+		Status:         models.InvitationStatusPending,
+		ExpiresAt:      time.Now().Add(s.authCfg.InvitationTTL),
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	s.send(invitation, actorID, token)
+
+	response := invitation.ToResponse()
+	return &response, nil
+}
+
+// List lists the organization's invitations, newest first
+func (s *invitationService) List(organizationID, actorID uint) ([]models.InvitationResponse, error) {
+	if _, err := s.manager(organizationID, actorID, models.OrganizationRoleMember); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitationRepo.GetByOrganizationID(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		responses = append(responses, invitations[i].ToResponse())
+	}
+	return responses, nil
+}
+
+// Resend emails a pending or expired invitation again with a new link and a new
+// expiry. The previous link stops working.
+func (s *invitationService) Resend(organizationID, actorID, invitationID uint) (*models.InvitationResponse, error) {
+	invitation, err := s.invitation(organizationID, actorID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.authCfg.InvitationTTL)
+	renewed, err := s.invitationRepo.RenewToken(invitation.ID, utils.HashToken(token), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !renewed {
+		return nil, ErrInvitationClosed
+	}
+	invitation.Status = models.InvitationStatusPending
+	invitation.ExpiresAt = expiresAt
+
+	s.send(invitation, actorID, token)
+
+	response := invitation.ToResponse()
+	return &response, nil
+}
+
+// Revoke cancels a pending or expired invitation so its link can no longer be accepted
+func (s *invitationService) Revoke(organizationID, actorID, invitationID uint) error {
+	invitation, err := s.invitation(organizationID, actorID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.invitationRepo.Revoke(invitation.ID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvitationClosed
+	}
+	return nil
+}
+
+// Accept redeems an invitation token. The invited email's account joins the
+// organization with the invited role; without an account, one is registered
+// with the given name and password. Opening the link proves control of the
+// mailbox, so it also verifies the email address.
+func (s *invitationService) Accept(ctx context.Context, req models.AcceptInvitationRequest) (*models.AcceptInvitationResponse, error) {
+	invitation, err := s.invitationRepo.GetByHash(utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if invitation.StatusAt(time.Now()) != models.InvitationStatusPending {
+		return nil, ErrInvalidInvitation
+	}
+
+	user, err := s.invitedUser(ctx, invitation, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.organizationRepo.GetMembership(invitation.OrganizationID, user.ID); err == nil {
+		return nil, ErrAlreadyOrganizationMember
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	}
+
+	accepted, err := s.invitationRepo.Accept(invitation, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, ErrInvalidInvitation
+	}
+
+	organization := invitation.Organization.ToResponse()
+	organization.Role = invitation.Role
+	return &models.AcceptInvitationResponse{
+		Organization: organization,
+		User:         user.ToResponse(),
+	}, nil
+}
+
+// PurgeExpired marks pending invitations past their expiry as expired. The records are kept.
+func (s *invitationService) PurgeExpired() error {
+	return s.invitationRepo.ExpirePending()
+}
+
+// invitedUser gets the account of the invited email, registering it when there is none
+func (s *invitationService) invitedUser(ctx context.Context, invitation *models.OrganizationInvitation, req models.AcceptInvitationRequest) (*models.User, error) {
+	user, err := s.userRepo.GetByEmail(invitation.Email)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if req.Name == "" || req.Password == "" {
+		return nil, ErrInvitationAccountRequired
+	}
+
+	created, err := s.userService.CreateUser(ctx, models.UserCreateRequest{
+		Name:     req.Name,
+		Email:    invitation.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(created.ID)
+}
+
+// manager gets the actor's membership and checks that it may manage invitations for the role
+func (s *invitationService) manager(organizationID, actorID uint, role string) (*models.OrganizationMember, error) {
+	actor, err := s.organizationRepo.GetMembership(organizationID, actorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	if !actor.CanAssignRole(role) {
+		return nil, ErrOrganizationForbidden
+	}
+	return actor, nil
+}
+
+// invitation gets an invitation the actor may manage
+func (s *invitationService) invitation(organizationID, actorID, invitationID uint) (*models.OrganizationInvitation, error) {
+	actor, err := s.manager(organizationID, actorID, models.OrganizationRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.invitationRepo.GetByID(organizationID, invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	// Only owners manage invitations for owners
+	if !actor.CanAssignRole(invitation.Role) {
+		return nil, ErrOrganizationForbidden
+	}
+	return invitation, nil
+}
+
+// send emails the invitation link in the background. Failures are only logged;
+// the invitation can be resent.
+func (s *invitationService) send(invitation *models.OrganizationInvitation, inviterID uint, token string) {
+	organization, err := s.organizationRepo.GetByID(invitation.OrganizationID)
+	if err != nil {
+		log.Printf("failed to send invitation %d: %v", invitation.ID, err)
+		return
+	}
+	inviter := "A member"
+	if user, err := s.userRepo.GetByID(inviterID); err == nil {
+		inviter = user.Name
+	}
+
+	link := fmt.Sprintf("%s/invitations/accept?token=%s", s.appCfg.URL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s on %s", organization.Name, s.appCfg.Name),
+		Body: fmt.Sprintf(
+			"Hi,\n\n%s invited you to join %s as %s. Use the link below to accept the invitation. The link expires on %s.\n\n%s\n\nIf you were not expecting this invitation, you can ignore this email.\n",
+			inviter, organization.Name, invitation.Role, invitation.ExpiresAt.Format(time.RFC1123), link,
+		),
+	}
+
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("failed to send invitation email: %v", err)
+		}
+	}()
+}
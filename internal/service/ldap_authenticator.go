@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"golang-starter-kit/internal/directory"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrDirectoryUnavailable is returned when the LDAP directory cannot check a login
+var ErrDirectoryUnavailable = errors.New("directory service is unavailable")
+
+// Directory checks credentials against an external user directory such as LDAP
+type Directory interface {
+	Authenticate(login, password string) (*directory.Entry, error)
+}
+
+// ldapAuthenticator checks passwords against a directory and provisions local accounts for its users
+type ldapAuthenticator struct {
+	directory Directory
+	userRepo  repository.UserRepository
+	hasher    utils.PasswordHasher
+}
+
+// NewLDAPAuthenticator creates an Authenticator backed by the directory
+func NewLDAPAuthenticator(dir Directory, userRepo repository.UserRepository, hasher utils.PasswordHasher) Authenticator {
+	return &ldapAuthenticator{
+		directory: dir,
+		userRepo:  userRepo,
+		hasher:    hasher,
+	}
+}
+
+// Authenticate checks the password with the directory. Local accounts are skipped so
+// that a directory entry with the same email cannot take them over. Directory users
+// logging in for the first time get a local account with a verified email address.
+func (a *ldapAuthenticator) Authenticate(email, password string) (*models.User, error) {
+	user, err := a.userRepo.GetByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user != nil && user.IsLocal() {
+		return nil, ErrAuthenticatorSkipped
+	}
+
+	entry, err := a.directory.Authenticate(email, password)
+	switch {
+	case errors.Is(err, directory.ErrInvalidCredentials):
+		return nil, ErrInvalidCredentials
+	case errors.Is(err, directory.ErrUserNotFound):
+		// Provisioned users removed from the directory can no longer log in
+		if user != nil {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, ErrAuthenticatorSkipped
+	case err != nil:
+		log.Printf("LDAP authentication failed: %v", err)
+		return nil, ErrDirectoryUnavailable
+	}
+
+	if user == nil {
+		return a.provision(email, entry)
+	}
+
+	// Keep the name in sync with the directory
+	if name := entryName(entry, user.Email); name != user.Name {
+		user.Name = name
+		if err := a.userRepo.Update(user); err != nil {
+			log.Printf("failed to sync directory name of user %d: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// provision creates the local account of a directory user on their first login.
+// The directory's spelling of the email is used when it differs from the login.
+func (a *ldapAuthenticator) provision(email string, entry *directory.Entry) (*models.User, error) {
+	email = models.NormalizeEmail(email)
+	if entry.Email != "" && models.NormalizeEmail(entry.Email) != email {
+		user, err := a.userRepo.GetByEmail(entry.Email)
+		if err == nil {
+			if user.IsLocal() {
+				return nil, ErrAuthenticatorSkipped
+			}
+			return user, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		email = models.NormalizeEmail(entry.Email)
+	}
+
+	// The local password is never checked; the directory owns it
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := a.hasher.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedAt := time.Now()
+	user := &models.User{
+		Name:            entryName(entry, email),
+		Email:           email,
+		Password:        hashedPassword,
+		EmailVerifiedAt: &verifiedAt,
+		AuthSource:      models.AuthSourceLDAP,
+	}
+	if err := a.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// entryName returns the directory entry's name, falling back to the local part
+// of the email when it does not fit the user name rules
+func entryName(entry *directory.Entry, email string) string {
+	name := strings.TrimSpace(entry.Name)
+	if length := len([]rune(name)); length < 2 || length > 100 {
+		name = strings.SplitN(email, "@", 2)[0]
+	}
+	return name
+}
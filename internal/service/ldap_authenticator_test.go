@@ -0,0 +1,235 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/directory"
+	"golang-starter-kit/internal/directory/directorytest"
+	"golang-starter-kit/internal/models"
+)
+
+const (
+	ldapServiceDN = "cn=service,dc=example,dc=com"
+	ldapJaneDN    = "uid=jane,ou=people,dc=example,dc=com"
+)
+
+type ldapTest struct {
+	server *directorytest.Server
+	users  *fakeUserRepo
+	ldap   Authenticator
+	chain  Authenticator
+}
+
+// newLDAPTest starts a directory holding Jane and chains the local and LDAP
+// authenticators the way main does
+func newLDAPTest(t *testing.T) *ldapTest {
+	t.Helper()
+
+	server := directorytest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddEntry(ldapServiceDN, "service secret", nil)
+	server.AddEntry(ldapJaneDN, "directory secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"jane@example.com"},
+		"cn":          {"Jane Doe"},
+	})
+
+	dir, err := directory.NewLDAP(config.LDAPConfig{
+		URL:            server.URL,
+		BindDN:         ldapServiceDN,
+		BindPassword:   "service secret",
+		BaseDN:         "dc=example,dc=com",
+		UserFilter:     "(&(objectClass=person)(mail=%s))",
+		EmailAttribute: "mail",
+		NameAttribute:  "cn",
+		Timeout:        5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &fakeUserRepo{}
+	ldap := NewLDAPAuthenticator(dir, users, fakeHasher{})
+	return &ldapTest{
+		server: server,
+		users:  users,
+		ldap:   ldap,
+		chain:  NewAuthenticatorChain(NewLocalAuthenticator(users, fakeHasher{}), ldap),
+	}
+}
+
+func TestLDAPAuthenticatorProvisionsUserOnFirstLogin(t *testing.T) {
+	tt := newLDAPTest(t)
+
+	user, err := tt.ldap.Authenticate("jane@example.com", "directory secret")
+	if err != nil {
+		t.Fatalf("Authenticate returned error: %v", err)
+	}
+	if user.ID == 0 || user.Email != "jane@example.com" || user.Name != "Jane Doe" {
+		t.Errorf("provisioned user %+v, want Jane from the directory", user)
+	}
+	if user.AuthSource != models.AuthSourceLDAP || !user.IsEmailVerified() {
+		t.Errorf("provisioned user has source %q and verified email %v, want a verified LDAP account", user.AuthSource, user.IsEmailVerified())
+	}
+	if (fakeHasher{}).Verify("directory secret", user.Password) {
+		t.Error("the directory password was stored locally")
+	}
+
+	// Later logins reuse the account and pick up name changes
+	tt.server.AddEntry(ldapJaneDN, "directory secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"jane@example.com"},
+		"cn":          {"Jane Smith"},
+	})
+	again, err := tt.ldap.Authenticate("jane@example.com", "directory secret")
+	if err != nil {
+		t.Fatalf("second Authenticate returned error: %v", err)
+	}
+	if again.ID != user.ID || len(tt.users.users) != 1 {
+		t.Errorf("second login gave user %d of %d users, want the provisioned one", again.ID, len(tt.users.users))
+	}
+	if again.Name != "Jane Smith" {
+		t.Errorf("name = %q, want the directory's new name", again.Name)
+	}
+}
+
+func TestLDAPAuthenticatorNormalizesDirectoryEmail(t *testing.T) {
+	tt := newLDAPTest(t)
+	tt.server.AddEntry(ldapJaneDN, "directory secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"Jane@Example.com"},
+	})
+
+	user, err := tt.ldap.Authenticate("JANE@example.com", "directory secret")
+	if err != nil {
+		t.Fatalf("Authenticate returned error: %v", err)
+	}
+	if user.Email != "jane@example.com" {
+		t.Errorf("provisioned user with email %q, want it lower-cased", user.Email)
+	}
+}
+
+func TestLDAPAuthenticatorRejectsWrongPassword(t *testing.T) {
+	tt := newLDAPTest(t)
+
+	if _, err := tt.ldap.Authenticate("jane@example.com", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate = %v, want ErrInvalidCredentials", err)
+	}
+	if len(tt.users.users) != 0 {
+		t.Error("a user was provisioned for a failed bind")
+	}
+}
+
+func TestLDAPAuthenticatorSkipsUnknownUsers(t *testing.T) {
+	tt := newLDAPTest(t)
+
+	if _, err := tt.ldap.Authenticate("john@example.com", "secret"); !errors.Is(err, ErrAuthenticatorSkipped) {
+		t.Fatalf("Authenticate = %v, want ErrAuthenticatorSkipped", err)
+	}
+}
+
+func TestLDAPAuthenticatorSkipsLocalAccounts(t *testing.T) {
+	tt := newLDAPTest(t)
+	local := &models.User{Name: "Jane", Email: "jane@example.com", Password: "hashed:local secret"}
+	_ = tt.users.Create(local)
+
+	// The directory entry with the same email must not take the local account over
+	if _, err := tt.ldap.Authenticate("jane@example.com", "directory secret"); !errors.Is(err, ErrAuthenticatorSkipped) {
+		t.Fatalf("Authenticate = %v, want ErrAuthenticatorSkipped", err)
+	}
+	if local.AuthSource != "" || local.Name != "Jane" {
+		t.Errorf("the local account was changed: %+v", local)
+	}
+}
+
+func TestLDAPAuthenticatorSkipsLocalAccountWithDirectoryEmail(t *testing.T) {
+	tt := newLDAPTest(t)
+	// The directory reports another spelling of the email than the login
+	tt.server.AddEntry("uid=john,ou=people,dc=example,dc=com", "directory secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"john@example.com", "john.doe@example.com"},
+	})
+	_ = tt.users.Create(&models.User{Name: "John", Email: "john@example.com", Password: "hashed:local secret"})
+
+	if _, err := tt.ldap.Authenticate("john.doe@example.com", "directory secret"); !errors.Is(err, ErrAuthenticatorSkipped) {
+		t.Fatalf("Authenticate = %v, want ErrAuthenticatorSkipped", err)
+	}
+	if len(tt.users.users) != 1 {
+		t.Error("a directory account was provisioned next to the local account")
+	}
+}
+
+func TestLDAPAuthenticatorRejectsUsersRemovedFromDirectory(t *testing.T) {
+	tt := newLDAPTest(t)
+	if _, err := tt.ldap.Authenticate("jane@example.com", "directory secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	tt.server.RemoveEntry(ldapJaneDN)
+
+	if _, err := tt.ldap.Authenticate("jane@example.com", "directory secret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := tt.chain.Authenticate("jane@example.com", "directory secret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("chain Authenticate = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLDAPAuthenticatorDirectoryUnavailable(t *testing.T) {
+	tt := newLDAPTest(t)
+	tt.server.Close()
+
+	if _, err := tt.ldap.Authenticate("jane@example.com", "directory secret"); !errors.Is(err, ErrDirectoryUnavailable) {
+		t.Fatalf("Authenticate = %v, want ErrDirectoryUnavailable", err)
+	}
+}
+
+func TestAuthenticatorChainOrder(t *testing.T) {
+	tt := newLDAPTest(t)
+	local := &models.User{Name: "John", Email: "john@example.com", Password: "hashed:local secret"}
+	_ = tt.users.Create(local)
+	// John also has a directory entry, which the local account shadows
+	tt.server.AddEntry("uid=john,ou=people,dc=example,dc=com", "directory secret", map[string][]string{
+		"objectClass": {"person"},
+		"mail":        {"john@example.com"},
+	})
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantUser string
+		wantErr  error
+	}{
+		{"local account with its password", "john@example.com", "local secret", "john@example.com", nil},
+		{"local account with the directory password", "john@example.com", "directory secret", "", ErrInvalidCredentials},
+		{"directory user", "jane@example.com", "directory secret", "jane@example.com", nil},
+		{"directory user with a wrong password", "jane@example.com", "wrong", "", ErrInvalidCredentials},
+		{"unknown everywhere", "nobody@example.com", "secret", "", ErrInvalidCredentials},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user, err := tt.chain.Authenticate(tc.email, tc.password)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Authenticate = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && user.Email != tc.wantUser {
+				t.Errorf("Authenticate signed in %s, want %s", user.Email, tc.wantUser)
+			}
+		})
+	}
+
+	// The random local password of a provisioned account is never checked
+	jane, err := tt.users.GetByEmail("jane@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jane.Password = "hashed:known local password"
+	if _, err := tt.chain.Authenticate("jane@example.com", "known local password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("the local password of a directory account logged in: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/mailer"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+)
+
+// loginHistoryListLimit caps how many login attempts are listed
+const loginHistoryListLimit = 50
+
+// errTwoFactorPending is recorded for logins that passed their first factor
+// and were answered with a two-factor challenge
+var errTwoFactorPending = errors.New("two-factor authentication pending")
+
+// LoginHistoryService interface defines login history methods
+type LoginHistoryService interface {
+	Record(method, email string, user *models.User, client models.ClientInfo, loginErr error)
+	List(userID uint) ([]models.LoginAttemptResponse, error)
+	PurgeExpired() error
+}
+
+// loginHistoryService implements LoginHistoryService interface
+type loginHistoryService struct {
+	loginAttemptRepo repository.LoginAttemptRepository
+	mailer           mailer.Mailer
+	appCfg           config.AppConfig
+	authCfg          config.AuthConfig
+}
+
+// NewLoginHistoryService creates a new login history service
+func NewLoginHistoryService(
+	loginAttemptRepo repository.LoginAttemptRepository,
+	mailer mailer.Mailer,
+	appCfg config.AppConfig,
+	authCfg config.AuthConfig,
+) LoginHistoryService {
+	return &loginHistoryService{
+		loginAttemptRepo: loginAttemptRepo,
+		mailer:           mailer,
+		appCfg:           appCfg,
+		authCfg:          authCfg,
+	}
+}
+
+// Record adds a login attempt to the history. user is nil when the email did not
+// belong to an account; loginErr is the error the login failed with, or nil.
+// A successful login from a new device or IP address notifies the user by email.
+// Failures are only logged so they never affect the login itself.
+func (s *loginHistoryService) Record(method, email string, user *models.User, client models.ClientInfo, loginErr error) {
+	// Attempts that cannot be tied to an account or email, such as unknown links, are not recorded
+	if user == nil && email == "" {
+		return
+	}
+
+	attempt := &models.LoginAttempt{
+		Email:     email,
+		Method:    method,
+		Success:   loginErr == nil,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+		attempt.Email = user.Email
+	}
+	if loginErr != nil {
+		attempt.FailureReason = loginFailureReason(loginErr)
+	}
+
+	if attempt.Success && user != nil {
+		newDevice, err := s.isNewDevice(user.ID, client)
+		if err != nil {
+			log.Printf("failed to check login history of user %d: %v", user.ID, err)
+		}
+		attempt.NewDevice = newDevice
+	}
+
+	if err := s.loginAttemptRepo.Create(attempt); err != nil {
+		log.Printf("failed to record login attempt for %s: %v", attempt.Email, err)
+	}
+
+	if attempt.NewDevice && s.authCfg.NewDeviceNotification {
+		s.sendNewDeviceEmail(user, client)
+	}
+}
+
+// List lists the user's most recent login attempts, newest first
+func (s *loginHistoryService) List(userID uint) ([]models.LoginAttemptResponse, error) {
+	attempts, err := s.loginAttemptRepo.GetRecentByUserID(userID, loginHistoryListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.LoginAttemptResponse, 0, len(attempts))
+	for i := range attempts {
+		responses = append(responses, attempts[i].ToResponse())
+	}
+	return responses, nil
+}
+
+// PurgeExpired removes login attempts older than the retention period
+func (s *loginHistoryService) PurgeExpired() error {
+	if s.authCfg.LoginHistoryRetention <= 0 {
+		return nil
+	}
+	return s.loginAttemptRepo.DeleteOlderThan(time.Now().Add(-s.authCfg.LoginHistoryRetention))
+}
+
+// isNewDevice reports whether the user logged in before, but never with the
+// client's user agent or never from its IP address. A first login is not new.
+func (s *loginHistoryService) isNewDevice(userID uint, client models.ClientInfo) (bool, error) {
+	loggedIn, err := s.loginAttemptRepo.HasSuccessfulLogin(userID)
+	if err != nil || !loggedIn {
+		return false, err
+	}
+
+	deviceSeen, ipSeen, err := s.loginAttemptRepo.SeenClient(userID, client)
+	if err != nil {
+		return false, err
+	}
+	return !deviceSeen || !ipSeen, nil
+}
+
+// sendNewDeviceEmail tells the user about a login from a new device or IP address
+func (s *loginHistoryService) sendNewDeviceEmail(user *models.User, client models.ClientInfo) {
+	device := client.UserAgent
+	if device == "" {
+		device = "Unknown"
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("New login to your %s account", s.appCfg.Name),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account was just signed in to from a new device or location.\n\nTime: %s\nIP address: %s\nDevice: %s\n\nIf this was you, you can ignore this email. If not, change your password and sign the device out from your active sessions.\n",
+			user.Name, time.Now().UTC().Format(time.RFC1123), client.IPAddress, device,
+		),
+	}
+
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("failed to send new device email: %v", err)
+		}
+	}()
+}
+
+// loginOutcome returns the error to record for a login. Logins answered with a
+// two-factor challenge do not count as successful until the challenge is verified.
+func loginOutcome(response *models.LoginResponse, err error) error {
+	if err == nil && response != nil && response.TwoFactorRequired {
+		return errTwoFactorPending
+	}
+	return err
+}
+
+// loginFailureReason maps the error a login failed with to a failure reason
+func loginFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrIncorrectPassword):
+		return models.LoginFailureInvalidCredentials
+	case errors.Is(err, ErrAccountLocked):
+		return models.LoginFailureAccountLocked
+	case errors.Is(err, ErrAccountDeactivated):
+		return models.LoginFailureAccountDeactivated
+	case errors.Is(err, ErrEmailNotVerified):
+		return models.LoginFailureEmailNotVerified
+	case errors.Is(err, ErrPasswordExpired):
+		return models.LoginFailurePasswordExpired
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		return models.LoginFailureInvalidTwoFactor
+	case errors.Is(err, errTwoFactorPending):
+		return models.LoginFailureTwoFactorPending
+	default:
+		return models.LoginFailureError
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/mailer"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMagicLinkDisabled is returned when passwordless login is switched off
+	ErrMagicLinkDisabled = errors.New("magic link login is disabled")
+	// ErrInvalidMagicLink is returned for unknown, expired or already used magic links
+	ErrInvalidMagicLink = errors.New("invalid or expired login link")
+)
+
+// MagicLinkService interface defines passwordless login methods
+type MagicLinkService interface {
+	SendLink(email string) error
+	Verify(req models.MagicLinkVerifyRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	PurgeExpired() error
+}
+
+// magicLinkService implements MagicLinkService interface
+type magicLinkService struct {
+	userRepo         repository.UserRepository
+	magicLinkRepo    repository.MagicLinkRepository
+	tokenService     TokenService
+	twoFactorService TwoFactorService
+	loginHistory     LoginHistoryService
+	mailer           mailer.Mailer
+	appCfg           config.AppConfig
+	authCfg          config.AuthConfig
+}
+
+// NewMagicLinkService creates a new magic link service
+func NewMagicLinkService(
+	userRepo repository.UserRepository,
+	magicLinkRepo repository.MagicLinkRepository,
+	tokenService TokenService,
+	twoFactorService TwoFactorService,
+	loginHistory LoginHistoryService,
+	mailer mailer.Mailer,
+	appCfg config.AppConfig,
+	authCfg config.AuthConfig,
+) MagicLinkService {
+	return &magicLinkService{
+		userRepo:         userRepo,
+		magicLinkRepo:    magicLinkRepo,
+		tokenService:     tokenService,
+		twoFactorService: twoFactorService,
+		loginHistory:     loginHistory,
+		mailer:           mailer,
+		appCfg:           appCfg,
+		authCfg:          authCfg,
+	}
+}
+
+// SendLink emails a single-use login link to the user. Directory users get no
+// link since the directory decides who may sign in as them.
+// It does not report whether the email belongs to an account.
+func (s *magicLinkService) SendLink(email string) error {
+	if !s.authCfg.MagicLinkEnabled {
+		return ErrMagicLinkDisabled
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsDeactivated() || !user.IsLocal() {
+		return nil
+	}
+
+	// Only the most recent link stays valid
+	if err := s.magicLinkRepo.DeleteForUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	magicLink := &models.MagicLinkToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.authCfg.MagicLinkTTL),
+	}
+	if err := s.magicLinkRepo.Create(magicLink); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/magic-link?token=%s", s.appCfg.URL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Your %s login link", s.appCfg.Name),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to log in. The link expires in %s and can only be used once.\n\n%s\n\nIf you did not request a login link, you can ignore this email.\n",
+			user.Name, s.authCfg.MagicLinkTTL, link,
+		),
+	}
+
+	// Send in the background so the response time does not reveal whether the account exists
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("failed to send magic link email: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// Verify redeems a magic link for the same response as a password login.
+// Opening the link proves control of the mailbox, so it also verifies the email address.
+func (s *magicLinkService) Verify(req models.MagicLinkVerifyRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	if !s.authCfg.MagicLinkEnabled {
+		return nil, ErrMagicLinkDisabled
+	}
+
+	magicLink, err := s.magicLinkRepo.GetByHash(utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+	if magicLink.UsedAt != nil || time.Now().After(magicLink.ExpiresAt) {
+		return nil, ErrInvalidMagicLink
+	}
+
+	// Links are single-use
+	marked, err := s.magicLinkRepo.MarkUsed(magicLink.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, ErrInvalidMagicLink
+	}
+
+	user, err := s.userRepo.GetByID(magicLink.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+	// The account may have moved to the directory after the link was sent
+	if !user.IsLocal() {
+		return nil, ErrInvalidMagicLink
+	}
+
+	if user.IsLocked(time.Now()) {
+		err := &AccountLockedError{Until: *user.LockedUntil}
+		s.loginHistory.Record(models.LoginMethodMagicLink, user.Email, user, client, err)
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		s.loginHistory.Record(models.LoginMethodMagicLink, user.Email, user, client, ErrAccountDeactivated)
+		return nil, ErrAccountDeactivated
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	}
+
+	var response *models.LoginResponse
+	if user.IsTwoFactorEnabled() {
+		response, err = s.twoFactorService.CreateChallenge(user)
+	} else {
+		response, err = s.tokenService.IssueTokens(user, client)
+	}
+	s.loginHistory.Record(models.LoginMethodMagicLink, user.Email, user, client, loginOutcome(response, err))
+	return response, err
+}
+
+// PurgeExpired removes magic links that can no longer be used
+func (s *magicLinkService) PurgeExpired() error {
+	return s.magicLinkRepo.DeleteExpired()
+}
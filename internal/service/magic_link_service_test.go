@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/mailer"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeMagicLinkRepo keeps magic links in memory
+type fakeMagicLinkRepo struct {
+	links []*models.MagicLinkToken
+}
+
+func (r *fakeMagicLinkRepo) Create(link *models.MagicLinkToken) error {
+	link.ID = uint(len(r.links) + 1)
+	r.links = append(r.links, link)
+	return nil
+}
+
+func (r *fakeMagicLinkRepo) GetByHash(hash string) (*models.MagicLinkToken, error) {
+	for _, link := range r.links {
+		if link.TokenHash == hash {
+			return link, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeMagicLinkRepo) MarkUsed(id uint) (bool, error) {
+	for _, link := range r.links {
+		if link.ID == id && link.UsedAt == nil {
+			now := time.Now()
+			link.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeMagicLinkRepo) DeleteForUser(userID uint) error {
+	return nil
+}
+
+func (r *fakeMagicLinkRepo) DeleteExpired() error {
+	return nil
+}
+
+// discardMailer drops every message
+type discardMailer struct{}
+
+func (discardMailer) Send(msg mailer.Message) error {
+	return nil
+}
+
+type magicLinkTest struct {
+	service MagicLinkService
+	users   *fakeUserRepo
+	links   *fakeMagicLinkRepo
+}
+
+func newMagicLinkTest() *magicLinkTest {
+	users := &fakeUserRepo{}
+	links := &fakeMagicLinkRepo{}
+	return &magicLinkTest{
+		service: NewMagicLinkService(users, links, fakeTokenService{}, nil, &fakeLoginHistory{}, discardMailer{},
+			config.AppConfig{URL: "https://app.example.com"},
+			config.AuthConfig{MagicLinkEnabled: true, MagicLinkTTL: time.Minute}),
+		users: users,
+		links: links,
+	}
+}
+
+// addLink stores a valid link for the user and returns its token
+func (tt *magicLinkTest) addLink(userID uint) string {
+	_ = tt.links.Create(&models.MagicLinkToken{
+		UserID:    userID,
+		TokenHash: utils.HashToken("link token"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	return "link token"
+}
+
+func TestMagicLinkSendLinkSkipsDirectoryUsers(t *testing.T) {
+	tt := newMagicLinkTest()
+	_ = tt.users.Create(&models.User{Name: "Jane", Email: "jane@example.com"})
+	_ = tt.users.Create(&models.User{Name: "John", Email: "john@example.com", AuthSource: models.AuthSourceLDAP})
+
+	if err := tt.service.SendLink("jane@example.com"); err != nil {
+		t.Fatalf("SendLink for a local user returned error: %v", err)
+	}
+	if len(tt.links.links) != 1 {
+		t.Fatalf("%d links were created for a local user, want 1", len(tt.links.links))
+	}
+
+	if err := tt.service.SendLink("john@example.com"); err != nil {
+		t.Fatalf("SendLink for a directory user returned error: %v", err)
+	}
+	if len(tt.links.links) != 1 {
+		t.Error("a link was created for a directory user")
+	}
+}
+
+func TestMagicLinkVerifyRejectsDirectoryUsers(t *testing.T) {
+	tt := newMagicLinkTest()
+	user := &models.User{Name: "Jane", Email: "jane@example.com"}
+	_ = tt.users.Create(user)
+	token := tt.addLink(user.ID)
+
+	// The account moved to the directory after the link was sent
+	user.AuthSource = models.AuthSourceLDAP
+
+	if _, err := tt.service.Verify(models.MagicLinkVerifyRequest{Token: token}, models.ClientInfo{}); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Fatalf("Verify = %v, want ErrInvalidMagicLink", err)
+	}
+	if user.IsEmailVerified() {
+		t.Error("the link verified the email of a directory user")
+	}
+}
+
+func TestMagicLinkVerifySignsInLocalUsers(t *testing.T) {
+	tt := newMagicLinkTest()
+	user := &models.User{Name: "Jane", Email: "jane@example.com"}
+	_ = tt.users.Create(user)
+	token := tt.addLink(user.ID)
+
+	response, err := tt.service.Verify(models.MagicLinkVerifyRequest{Token: token}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if response.Token != "token for jane@example.com" || !user.IsEmailVerified() {
+		t.Errorf("Verify signed in %q with verified email %v, want Jane with a verified email", response.Token, user.IsEmailVerified())
+	}
+	if _, err := tt.service.Verify(models.MagicLinkVerifyRequest{Token: token}, models.ClientInfo{}); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Errorf("Verify reusing a link = %v, want ErrInvalidMagicLink", err)
+	}
+}
@@ -0,0 +1,667 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOAuthClientNotFound is returned when an OAuth client does not exist
+	ErrOAuthClientNotFound = errors.New("OAuth client not found")
+	// ErrRedirectURIRequired is returned when a client using the authorization code grant has no redirect URI
+	ErrRedirectURIRequired = errors.New("clients using authorization_code need at least one redirect URI")
+	// ErrPublicClientCredentials is returned when a public client is registered for the client credentials grant
+	ErrPublicClientCredentials = errors.New("public clients cannot use client_credentials")
+	// ErrInsufficientScope is returned when a token lacks the scope an endpoint requires
+	ErrInsufficientScope = errors.New("the token does not have the required scope")
+)
+
+// OAuthError is an OAuth 2.0 protocol error (RFC 6749 section 5.2)
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// oauthError creates an OAuth protocol error
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthService interface defines the OAuth2 authorization server and OpenID provider methods
+type OAuthService interface {
+	CreateClient(req models.CreateOAuthClientRequest) (*models.CreateOAuthClientResponse, error)
+	ListClients() ([]models.OAuthClientResponse, error)
+	DeleteClient(id uint) error
+	Authorize(userID uint, req models.AuthorizeRequest) (*models.AuthorizeResponse, error)
+	Token(clientID, clientSecret string, req models.OAuthTokenRequest) (*models.OAuthTokenResponse, error)
+	Introspect(clientID, clientSecret, token string) (*models.IntrospectionResponse, error)
+	Revoke(clientID, clientSecret, token string) error
+	UserInfo(claims *utils.JWTClaims) (*models.UserInfoResponse, error)
+	Discovery() models.OpenIDConfiguration
+	PurgeExpired() error
+}
+
+// oauthService implements OAuthService interface
+type oauthService struct {
+	oauthRepo    repository.OAuthRepository
+	refreshRepo  repository.RefreshTokenRepository
+	userRepo     repository.UserRepository
+	tokenService TokenService
+	keys         *utils.KeySet
+	appCfg       config.AppConfig
+	jwtCfg       config.JWTConfig
+	oauthCfg     config.OAuthConfig
+}
+
+// NewOAuthService creates a new OAuth service
+func NewOAuthService(
+	oauthRepo repository.OAuthRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	userRepo repository.UserRepository,
+	tokenService TokenService,
+	keys *utils.KeySet,
+	appCfg config.AppConfig,
+	jwtCfg config.JWTConfig,
+	oauthCfg config.OAuthConfig,
+) OAuthService {
+	return &oauthService{
+		oauthRepo:    oauthRepo,
+		refreshRepo:  refreshRepo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		keys:         keys,
+		appCfg:       appCfg,
+		jwtCfg:       jwtCfg,
+		oauthCfg:     oauthCfg,
+	}
+}
+
+// CreateClient registers an OAuth client. Confidential clients get a secret that is only returned here.
+func (s *oauthService) CreateClient(req models.CreateOAuthClientRequest) (*models.CreateOAuthClientResponse, error) {
+	client := &models.OAuthClient{
+		Name:         req.Name,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		GrantTypes:   strings.Join(req.GrantTypes, " "),
+		Scopes:       strings.Join(req.Scopes, " "),
+		Public:       req.Public,
+	}
+	if client.AllowsGrant(models.GrantTypeAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return nil, ErrRedirectURIRequired
+	}
+	if client.Public && client.AllowsGrant(models.GrantTypeClientCredentials) {
+		return nil, ErrPublicClientCredentials
+	}
+
+	clientID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	client.ClientID = clientID
+
+	var secret string
+	if !client.Public {
+		if secret, err = utils.GenerateRandomToken(32); err != nil {
+			return nil, err
+		}
+		client.SecretHash = utils.HashToken(secret)
+	}
+
+	if err := s.oauthRepo.CreateClient(client); err != nil {
+		return nil, err
+	}
+
+	return &models.CreateOAuthClientResponse{
+		OAuthClientResponse: client.ToResponse(),
+		ClientSecret:        secret,
+	}, nil
+}
+
+// ListClients gets every registered OAuth client
+func (s *oauthService) ListClients() ([]models.OAuthClientResponse, error) {
+	clients, err := s.oauthRepo.GetClients()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.OAuthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		responses = append(responses, client.ToResponse())
+	}
+	return responses, nil
+}
+
+// DeleteClient removes an OAuth client and every refresh token issued to it
+func (s *oauthService) DeleteClient(id uint) error {
+	deleted, err := s.oauthRepo.DeleteClient(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+// Authorize issues an authorization code to the client for the signed-in user.
+// Errors about the client or redirect URI are returned; every other error is
+// reported to the client through the redirect URI as RFC 6749 requires.
+func (s *oauthService) Authorize(userID uint, req models.AuthorizeRequest) (*models.AuthorizeResponse, error) {
+	client, err := s.oauthRepo.GetClientByClientID(req.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, oauthError("invalid_client", "unknown client")
+		}
+		return nil, err
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" {
+		registered := strings.Fields(client.RedirectURIs)
+		if len(registered) != 1 {
+			return nil, oauthError("invalid_request", "redirect_uri is required")
+		}
+		redirectURI = registered[0]
+	} else if !client.AllowsRedirectURI(redirectURI) {
+		return nil, oauthError("invalid_request", "redirect_uri is not registered for this client")
+	}
+
+	redirect := func(params url.Values) (*models.AuthorizeResponse, error) {
+		if req.State != "" {
+			params.Set("state", req.State)
+		}
+		u, err := url.Parse(redirectURI)
+		if err != nil {
+			return nil, err
+		}
+		q := u.Query()
+		for key, values := range params {
+			q[key] = values
+		}
+		u.RawQuery = q.Encode()
+		return &models.AuthorizeResponse{RedirectTo: u.String()}, nil
+	}
+	redirectError := func(code, description string) (*models.AuthorizeResponse, error) {
+		return redirect(url.Values{"error": {code}, "error_description": {description}})
+	}
+
+	if req.ResponseType != "code" {
+		return redirectError("unsupported_response_type", "only the code response type is supported")
+	}
+	if !client.AllowsGrant(models.GrantTypeAuthorizationCode) {
+		return redirectError("unauthorized_client", "the client may not use the authorization code grant")
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return redirectError("invalid_request", "PKCE with code_challenge_method S256 is required")
+	}
+	if !client.AllowsScopes(strings.Fields(req.Scope)) {
+		return redirectError("invalid_scope", "the requested scope is not allowed for this client")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	code, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.oauthRepo.CreateCode(&models.OAuthAuthorizationCode{
+		CodeHash:      utils.HashToken(code),
+		ClientID:      client.ClientID,
+		UserID:        user.ID,
+		RedirectURI:   redirectURI,
+		Scope:         strings.Join(strings.Fields(req.Scope), " "),
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+		ExpiresAt:     time.Now().Add(s.oauthCfg.AuthorizationCodeTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return redirect(url.Values{"code": {code}})
+}
+
+// Token implements the token endpoint for the authorization code, client credentials and refresh token grants
+func (s *oauthService) Token(clientID, clientSecret string, req models.OAuthTokenRequest) (*models.OAuthTokenResponse, error) {
+	client, err := s.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case models.GrantTypeAuthorizationCode, models.GrantTypeClientCredentials, models.GrantTypeRefreshToken:
+		if !client.AllowsGrant(req.GrantType) {
+			return nil, oauthError("unauthorized_client", "the client may not use this grant type")
+		}
+	case "":
+		return nil, oauthError("invalid_request", "grant_type is required")
+	default:
+		return nil, oauthError("unsupported_grant_type", "the grant type is not supported")
+	}
+
+	switch req.GrantType {
+	case models.GrantTypeAuthorizationCode:
+		return s.exchangeCode(client, req)
+	case models.GrantTypeClientCredentials:
+		return s.clientCredentials(client, req)
+	default:
+		return s.refresh(client, req)
+	}
+}
+
+// Introspect reports whether a token is active and describes it (RFC 7662).
+// Only confidential clients may introspect tokens.
+func (s *oauthService) Introspect(clientID, clientSecret, token string) (*models.IntrospectionResponse, error) {
+	client, err := s.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public {
+		return nil, oauthError("unauthorized_client", "public clients cannot introspect tokens")
+	}
+
+	inactive := &models.IntrospectionResponse{Active: false}
+
+	stored, err := s.refreshRepo.GetByHash(utils.HashToken(token))
+	if err == nil {
+		if stored.ClientID == "" || !stored.IsActive(time.Now()) {
+			return inactive, nil
+		}
+		return &models.IntrospectionResponse{
+			Active:    true,
+			Scope:     stored.Scope,
+			ClientID:  stored.ClientID,
+			TokenType: "refresh_token",
+			Exp:       stored.ExpiresAt.Unix(),
+			Iat:       stored.CreatedAt.Unix(),
+			Sub:       strconv.FormatUint(uint64(stored.UserID), 10),
+		}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	claims, err := s.tokenService.ValidateAccessToken(token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	response := &models.IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		Username:  claims.Email,
+		TokenType: "access_token",
+		Sub:       claims.Subject,
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+	}
+	if response.Sub == "" {
+		response.Sub = strconv.FormatUint(uint64(claims.UserID), 10)
+	}
+	if claims.ExpiresAt != nil {
+		response.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.Iat = claims.IssuedAt.Unix()
+	}
+	return response, nil
+}
+
+// Revoke revokes a refresh token (with its whole family) or an access token
+// issued to the client (RFC 7009). Unknown tokens are ignored.
+func (s *oauthService) Revoke(clientID, clientSecret, token string) error {
+	client, err := s.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.refreshRepo.GetByHash(utils.HashToken(token))
+	if err == nil {
+		if stored.ClientID != client.ClientID {
+			return nil
+		}
+		return s.refreshRepo.RevokeFamily(stored.FamilyID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	claims, err := s.tokenService.ValidateAccessToken(token)
+	if err != nil || claims.ClientID != client.ClientID {
+		return nil
+	}
+	return s.tokenService.Logout(claims, "")
+}
+
+// UserInfo returns the OpenID Connect claims of the token's user, limited to the granted scopes
+func (s *oauthService) UserInfo(claims *utils.JWTClaims) (*models.UserInfoResponse, error) {
+	if claims.ClientID != "" && !claims.HasScope("openid") {
+		return nil, ErrInsufficientScope
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	profile := user.ToResponse()
+	info := &models.UserInfoResponse{Sub: strconv.FormatUint(uint64(profile.ID), 10)}
+	if !claims.IsScoped() || claims.HasScope("profile") {
+		info.Name = profile.Name
+		info.Roles = profile.Roles
+		info.UpdatedAt = profile.UpdatedAt.Unix()
+	}
+	if !claims.IsScoped() || claims.HasScope("email") {
+		verified := profile.EmailVerifiedAt != nil
+		info.Email = profile.Email
+		info.EmailVerified = &verified
+	}
+	return info, nil
+}
+
+// Discovery returns the OpenID Provider metadata
+func (s *oauthService) Discovery() models.OpenIDConfiguration {
+	api := s.appCfg.URL + "/api/v1/oauth"
+	return models.OpenIDConfiguration{
+		Issuer:                            s.oauthCfg.Issuer,
+		AuthorizationEndpoint:             s.oauthCfg.AuthorizeURL,
+		TokenEndpoint:                     api + "/token",
+		UserinfoEndpoint:                  api + "/userinfo",
+		JWKSURI:                           s.appCfg.URL + "/.well-known/jwks.json",
+		RevocationEndpoint:                api + "/revoke",
+		IntrospectionEndpoint:             api + "/introspect",
+		ScopesSupported:                   []string{"openid", "profile", "email"},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{models.GrantTypeAuthorizationCode, models.GrantTypeClientCredentials, models.GrantTypeRefreshToken},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{s.keys.Algorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "name", "email", "email_verified", "roles", "updated_at"},
+	}
+}
+
+// PurgeExpired removes authorization codes that were never redeemed
+func (s *oauthService) PurgeExpired() error {
+	return s.oauthRepo.DeleteExpiredCodes()
+}
+
+// authenticateClient checks the client credentials. Public clients authenticate with their ID only.
+func (s *oauthService) authenticateClient(clientID, clientSecret string) (*models.OAuthClient, error) {
+	invalid := oauthError("invalid_client", "client authentication failed")
+	if clientID == "" {
+		return nil, invalid
+	}
+
+	client, err := s.oauthRepo.GetClientByClientID(clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	if client.Public {
+		if clientSecret != "" {
+			return nil, invalid
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, invalid
+	}
+	return client, nil
+}
+
+// exchangeCode redeems an authorization code after verifying the PKCE code verifier
+func (s *oauthService) exchangeCode(client *models.OAuthClient, req models.OAuthTokenRequest) (*models.OAuthTokenResponse, error) {
+	invalid := oauthError("invalid_grant", "authorization code is invalid or expired")
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, oauthError("invalid_request", "code and code_verifier are required")
+	}
+
+	code, err := s.oauthRepo.GetCodeByHash(utils.HashToken(req.Code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	// Each code can be redeemed once
+	deleted, err := s.oauthRepo.DeleteCode(code.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted || code.ClientID != client.ClientID || time.Now().After(code.ExpiresAt) {
+		return nil, invalid
+	}
+	if req.RedirectURI != "" && req.RedirectURI != code.RedirectURI {
+		return nil, oauthError("invalid_grant", "redirect_uri does not match the authorization request")
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.CodeChallengeS256(req.CodeVerifier)), []byte(code.CodeChallenge)) != 1 {
+		return nil, oauthError("invalid_grant", "code_verifier does not match the code challenge")
+	}
+
+	user, err := s.userRepo.GetByID(code.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, invalid
+	}
+
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(client, user, code.Scope, familyID, code.Nonce)
+}
+
+// clientCredentials issues an access token to a confidential client acting on its own behalf
+func (s *oauthService) clientCredentials(client *models.OAuthClient, req models.OAuthTokenRequest) (*models.OAuthTokenResponse, error) {
+	if client.Public {
+		return nil, oauthError("unauthorized_client", "public clients cannot use client_credentials")
+	}
+
+	scope := client.Scopes
+	if req.Scope != "" {
+		if !client.AllowsScopes(strings.Fields(req.Scope)) {
+			return nil, oauthError("invalid_scope", "the requested scope is not allowed for this client")
+		}
+		scope = strings.Join(strings.Fields(req.Scope), " ")
+	}
+
+	claims := utils.JWTClaims{
+		ClientID: client.ClientID,
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  client.ClientID,
+			Audience: jwt.ClaimStrings{client.ClientID},
+		},
+	}
+	accessToken, _, err := utils.GenerateToken(claims, s.keys, s.jwtCfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.jwtCfg.AccessTokenTTL.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// refresh rotates a refresh token issued to the client.
+// Presenting a token that was already rotated revokes its whole family.
+func (s *oauthService) refresh(client *models.OAuthClient, req models.OAuthTokenRequest) (*models.OAuthTokenResponse, error) {
+	invalid := oauthError("invalid_grant", "refresh token is invalid or expired")
+	if req.RefreshToken == "" {
+		return nil, oauthError("invalid_request", "refresh_token is required")
+	}
+
+	stored, err := s.refreshRepo.GetByHash(utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if stored.ClientID != client.ClientID {
+		return nil, invalid
+	}
+
+	if stored.UsedAt != nil {
+		if err := s.refreshRepo.RevokeFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, invalid
+	}
+	if !stored.IsActive(time.Now()) {
+		return nil, invalid
+	}
+
+	// A refresh may narrow the original scope but never widen it
+	scope := stored.Scope
+	if req.Scope != "" {
+		if !scopeIncludes(stored.Scope, strings.Fields(req.Scope)...) {
+			return nil, oauthError("invalid_scope", "the requested scope exceeds the original grant")
+		}
+		scope = strings.Join(strings.Fields(req.Scope), " ")
+	}
+
+	marked, err := s.refreshRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		// Another request rotated this token first
+		if err := s.refreshRepo.RevokeFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, invalid
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, invalid
+	}
+
+	return s.issue(client, user, scope, stored.FamilyID, "")
+}
+
+// issue creates the access token, refresh token and ID token of a user grant
+func (s *oauthService) issue(client *models.OAuthClient, user *models.User, scope, familyID, nonce string) (*models.OAuthTokenResponse, error) {
+	subject := strconv.FormatUint(uint64(user.ID), 10)
+
+	claims := utils.JWTClaims{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Roles:    user.RoleNames(),
+		ClientID: client.ClientID,
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  subject,
+			Audience: jwt.ClaimStrings{client.ClientID},
+		},
+	}
+	accessToken, _, err := utils.GenerateToken(claims, s.keys, s.jwtCfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.jwtCfg.AccessTokenTTL.Seconds()),
+		Scope:       scope,
+	}
+
+	if client.AllowsGrant(models.GrantTypeRefreshToken) {
+		refreshToken, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.refreshRepo.Create(&models.RefreshToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(refreshToken),
+			FamilyID:  familyID,
+			ClientID:  client.ClientID,
+			Scope:     scope,
+			ExpiresAt: time.Now().Add(s.oauthCfg.RefreshTokenTTL),
+		}); err != nil {
+			return nil, err
+		}
+		response.RefreshToken = refreshToken
+	}
+
+	if scopeIncludes(scope, "openid") {
+		idClaims := utils.IDTokenClaims{
+			Nonce:            nonce,
+			RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+		}
+		if scopeIncludes(scope, "profile") {
+			idClaims.Name = user.Name
+		}
+		if scopeIncludes(scope, "email") {
+			verified := user.IsEmailVerified()
+			idClaims.Email = user.Email
+			idClaims.EmailVerified = &verified
+		}
+		idToken, err := utils.GenerateIDToken(idClaims, s.keys, s.oauthCfg.Issuer, client.ClientID, s.jwtCfg.AccessTokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		response.IDToken = idToken
+	}
+
+	return response, nil
+}
+
+// scopeIncludes reports whether every requested scope is part of the space separated granted scope
+func scopeIncludes(granted string, requested ...string) bool {
+	set := map[string]bool{}
+	for _, scope := range strings.Fields(granted) {
+		set[scope] = true
+	}
+	for _, scope := range requested {
+		if !set[scope] {
+			return false
+		}
+	}
+	return true
+}
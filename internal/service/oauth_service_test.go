@@ -0,0 +1,302 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeOAuthRepo keeps OAuth clients and authorization codes in memory. Deleted
+// codes can still be read, as by a concurrent request that read the code
+// before another deleted it, so only DeleteCode tells whether a code was redeemed.
+type fakeOAuthRepo struct {
+	clients []*models.OAuthClient
+	codes   []*models.OAuthAuthorizationCode
+	deleted map[uint]bool
+}
+
+func (r *fakeOAuthRepo) CreateClient(client *models.OAuthClient) error {
+	client.ID = uint(len(r.clients) + 1)
+	r.clients = append(r.clients, client)
+	return nil
+}
+
+func (r *fakeOAuthRepo) GetClients() ([]models.OAuthClient, error) {
+	clients := make([]models.OAuthClient, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, *client)
+	}
+	return clients, nil
+}
+
+func (r *fakeOAuthRepo) GetClientByClientID(clientID string) (*models.OAuthClient, error) {
+	for _, client := range r.clients {
+		if client.ClientID == clientID {
+			return client, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOAuthRepo) DeleteClient(id uint) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeOAuthRepo) CreateCode(code *models.OAuthAuthorizationCode) error {
+	code.ID = uint(len(r.codes) + 1)
+	r.codes = append(r.codes, code)
+	return nil
+}
+
+func (r *fakeOAuthRepo) GetCodeByHash(hash string) (*models.OAuthAuthorizationCode, error) {
+	for _, code := range r.codes {
+		if code.CodeHash == hash {
+			return code, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeOAuthRepo) DeleteCode(id uint) (bool, error) {
+	if r.deleted[id] || id == 0 || int(id) > len(r.codes) {
+		return false, nil
+	}
+	r.deleted[id] = true
+	return true, nil
+}
+
+func (r *fakeOAuthRepo) DeleteExpiredCodes() error {
+	return nil
+}
+
+const (
+	oauthRedirectURI = "https://app.example.com/callback"
+	oauthVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r7wW1gFWFOEjXk"
+)
+
+type oauthTest struct {
+	service OAuthService
+	repo    *fakeOAuthRepo
+	refresh *fakeRefreshRepo
+	// client is a confidential client for the authorization code and refresh token grants
+	client *models.CreateOAuthClientResponse
+}
+
+func newOAuthTest(t *testing.T) *oauthTest {
+	t.Helper()
+
+	users := &fakeUserRepo{}
+	_ = users.Create(&models.User{Name: "Jane", Email: "jane@example.com"})
+	repo := &fakeOAuthRepo{deleted: map[uint]bool{}}
+	refresh := &fakeRefreshRepo{}
+
+	s := NewOAuthService(repo, refresh, users, fakeTokenService{}, utils.NewHMACKeySet("secret", "test"),
+		config.AppConfig{URL: "https://api.example.com"},
+		config.JWTConfig{AccessTokenTTL: 15 * time.Minute},
+		config.OAuthConfig{Issuer: "https://api.example.com", AuthorizationCodeTTL: time.Minute, RefreshTokenTTL: time.Hour})
+
+	client, err := s.CreateClient(models.CreateOAuthClientRequest{
+		Name:         "Dashboard",
+		RedirectURIs: []string{oauthRedirectURI},
+		GrantTypes:   []string{models.GrantTypeAuthorizationCode, models.GrantTypeRefreshToken},
+		Scopes:       []string{"openid", "profile"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &oauthTest{service: s, repo: repo, refresh: refresh, client: client}
+}
+
+// authorize asks for a code for Jane bound to the challenge of oauthVerifier
+// and returns the redirect's query
+func (tt *oauthTest) authorize(t *testing.T, clientID, challengeMethod string) url.Values {
+	t.Helper()
+
+	response, err := tt.service.Authorize(1, models.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            clientID,
+		Scope:               "openid profile",
+		State:               "state",
+		CodeChallenge:       utils.CodeChallengeS256(oauthVerifier),
+		CodeChallengeMethod: challengeMethod,
+	})
+	if err != nil {
+		t.Fatalf("Authorize returned error: %v", err)
+	}
+	redirect, err := url.Parse(response.RedirectTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return redirect.Query()
+}
+
+// exchange redeems a code with the confidential client's credentials
+func (tt *oauthTest) exchange(code, verifier string) (*models.OAuthTokenResponse, error) {
+	return tt.service.Token(tt.client.ClientID, tt.client.ClientSecret, models.OAuthTokenRequest{
+		GrantType:    models.GrantTypeAuthorizationCode,
+		Code:         code,
+		RedirectURI:  oauthRedirectURI,
+		CodeVerifier: verifier,
+	})
+}
+
+// oauthErrorCode returns the OAuth error code of err, or "" when it is not an OAuth error
+func oauthErrorCode(err error) string {
+	var oauthErr *OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func TestOAuthAuthorizeRequiresPKCE(t *testing.T) {
+	tt := newOAuthTest(t)
+
+	for _, method := range []string{"", "plain"} {
+		query := tt.authorize(t, tt.client.ClientID, method)
+		if query.Get("error") != "invalid_request" || query.Get("code") != "" {
+			t.Errorf("Authorize with code_challenge_method %q redirected with %v, want invalid_request", method, query)
+		}
+	}
+	if len(tt.repo.codes) != 0 {
+		t.Error("a code was issued without an S256 challenge")
+	}
+
+	query := tt.authorize(t, tt.client.ClientID, "S256")
+	if query.Get("code") == "" || query.Get("state") != "state" {
+		t.Fatalf("Authorize redirected with %v, want a code and the state", query)
+	}
+	if tt.repo.codes[0].CodeHash == query.Get("code") {
+		t.Error("the code was stored in plain text")
+	}
+}
+
+func TestOAuthCodeExchangeChecksVerifier(t *testing.T) {
+	tt := newOAuthTest(t)
+
+	code := tt.authorize(t, tt.client.ClientID, "S256").Get("code")
+	if _, err := tt.exchange(code, "another verifier"); oauthErrorCode(err) != "invalid_grant" {
+		t.Fatalf("Token with a wrong code_verifier = %v, want invalid_grant", err)
+	}
+	// A failed attempt uses the code up
+	if _, err := tt.exchange(code, oauthVerifier); oauthErrorCode(err) != "invalid_grant" {
+		t.Errorf("Token after a failed attempt = %v, want invalid_grant", err)
+	}
+
+	code = tt.authorize(t, tt.client.ClientID, "S256").Get("code")
+	response, err := tt.exchange(code, oauthVerifier)
+	if err != nil {
+		t.Fatalf("Token returned error: %v", err)
+	}
+	if response.AccessToken == "" || response.RefreshToken == "" || response.IDToken == "" || response.Scope != "openid profile" {
+		t.Errorf("Token = %+v, want access, refresh and ID tokens for the requested scope", response)
+	}
+}
+
+func TestOAuthCodesAreSingleUse(t *testing.T) {
+	tt := newOAuthTest(t)
+
+	code := tt.authorize(t, tt.client.ClientID, "S256").Get("code")
+	if _, err := tt.exchange(code, oauthVerifier); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tt.exchange(code, oauthVerifier); oauthErrorCode(err) != "invalid_grant" {
+		t.Errorf("Token redeeming a code twice = %v, want invalid_grant", err)
+	}
+
+	code = tt.authorize(t, tt.client.ClientID, "S256").Get("code")
+	tt.repo.codes[len(tt.repo.codes)-1].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := tt.exchange(code, oauthVerifier); oauthErrorCode(err) != "invalid_grant" {
+		t.Errorf("Token with an expired code = %v, want invalid_grant", err)
+	}
+}
+
+func TestOAuthClientAuthentication(t *testing.T) {
+	tt := newOAuthTest(t)
+	public, err := tt.service.CreateClient(models.CreateOAuthClientRequest{
+		Name:         "Mobile",
+		RedirectURIs: []string{oauthRedirectURI},
+		GrantTypes:   []string{models.GrantTypeAuthorizationCode},
+		Scopes:       []string{"openid", "profile"},
+		Public:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if public.ClientSecret != "" {
+		t.Error("a public client got a secret")
+	}
+
+	tests := []struct {
+		name     string
+		clientID string
+		secret   string
+		wantCode string
+	}{
+		{"confidential client with its secret", tt.client.ClientID, tt.client.ClientSecret, ""},
+		{"confidential client with a wrong secret", tt.client.ClientID, "wrong", "invalid_client"},
+		{"confidential client without a secret", tt.client.ClientID, "", "invalid_client"},
+		{"unknown client", "unknown", tt.client.ClientSecret, "invalid_client"},
+		{"public client", public.ClientID, "", ""},
+		{"public client sending a secret", public.ClientID, "secret", "invalid_client"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// An unknown client presents a code issued to the confidential one
+			codeClient := tc.clientID
+			if codeClient == "unknown" {
+				codeClient = tt.client.ClientID
+			}
+			code := tt.authorize(t, codeClient, "S256").Get("code")
+			_, err := tt.service.Token(tc.clientID, tc.secret, models.OAuthTokenRequest{
+				GrantType:    models.GrantTypeAuthorizationCode,
+				Code:         code,
+				CodeVerifier: oauthVerifier,
+			})
+			if got := oauthErrorCode(err); got != tc.wantCode || (tc.wantCode == "" && err != nil) {
+				t.Errorf("Token = %v, want %q", err, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestOAuthRefreshReuseRevokesFamily(t *testing.T) {
+	tt := newOAuthTest(t)
+	refresh := func(token string) (*models.OAuthTokenResponse, error) {
+		return tt.service.Token(tt.client.ClientID, tt.client.ClientSecret, models.OAuthTokenRequest{
+			GrantType:    models.GrantTypeRefreshToken,
+			RefreshToken: token,
+		})
+	}
+
+	code := tt.authorize(t, tt.client.ClientID, "S256").Get("code")
+	granted, err := tt.exchange(code, oauthVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := refresh(granted.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh returned error: %v", err)
+	}
+	if rotated.RefreshToken == granted.RefreshToken {
+		t.Fatal("the refresh token was not rotated")
+	}
+
+	if _, err := refresh(granted.RefreshToken); oauthErrorCode(err) != "invalid_grant" {
+		t.Fatalf("refresh reusing a rotated token = %v, want invalid_grant", err)
+	}
+	if len(tt.refresh.revokedFamilies) != 1 || tt.refresh.revokedFamilies[0] != tt.refresh.tokens[0].FamilyID {
+		t.Errorf("revoked families %v, want the grant's family", tt.refresh.revokedFamilies)
+	}
+	if _, err := refresh(rotated.RefreshToken); oauthErrorCode(err) != "invalid_grant" {
+		t.Errorf("refresh with the family's latest token = %v, want invalid_grant", err)
+	}
+}
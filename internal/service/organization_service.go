@@ -0,0 +1,248 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOrganizationNotFound is returned when an organization does not exist or the user is not a member
+	ErrOrganizationNotFound = errors.New("organization not found")
+	// ErrInvalidOrganizationSlug is returned for numeric slugs, which would be read as organization IDs
+	ErrInvalidOrganizationSlug = errors.New("organization slug cannot be a number")
+	// ErrOrganizationSlugTaken is returned when another organization uses the slug
+	ErrOrganizationSlugTaken = errors.New("organization slug is already taken")
+	// ErrOrganizationForbidden is returned when the member's role does not allow managing members
+	ErrOrganizationForbidden = errors.New("you cannot manage the members of this organization")
+	// ErrAlreadyOrganizationMember is returned when adding a user who is already a member
+	ErrAlreadyOrganizationMember = errors.New("user is already a member of this organization")
+	// ErrOrganizationMemberNotFound is returned when removing a user who is not a member
+	ErrOrganizationMemberNotFound = errors.New("organization member not found")
+	// ErrLastOrganizationOwner is returned when removing the only owner of an organization
+	ErrLastOrganizationOwner = errors.New("an organization must keep at least one owner")
+)
+
+// OrganizationService interface defines organization and membership methods
+type OrganizationService interface {
+	Create(userID uint, req models.OrganizationCreateRequest) (*models.OrganizationResponse, error)
+	ListForUser(userID uint) ([]models.OrganizationResponse, error)
+	ListMembers(organizationID, userID uint) ([]models.OrganizationMemberResponse, error)
+	AddMember(organizationID, actorID uint, req models.AddOrganizationMemberRequest) (*models.OrganizationMemberResponse, error)
+	RemoveMember(organizationID, actorID, userID uint) error
+	ResolveTenant(ref string, userID uint) (uint, bool, error)
+	MemberOrganizations(userID uint) ([]uint, error)
+}
+
+// organizationService implements OrganizationService interface
+type organizationService struct {
+	organizationRepo repository.OrganizationRepository
+	userRepo         repository.UserRepository
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(organizationRepo repository.OrganizationRepository, userRepo repository.UserRepository) OrganizationService {
+	return &organizationService{
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// Create creates an organization owned by the user
+func (s *organizationService) Create(userID uint, req models.OrganizationCreateRequest) (*models.OrganizationResponse, error) {
+	if _, err := strconv.ParseUint(req.Slug, 10, 64); err == nil {
+		return nil, ErrInvalidOrganizationSlug
+	}
+
+	if _, err := s.organizationRepo.GetBySlug(req.Slug); err == nil {
+		return nil, ErrOrganizationSlugTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	organization := &models.Organization{
+		Name: req.Name,
+		Slug: req.Slug,
+	}
+	if err := s.organizationRepo.CreateWithOwner(organization, userID); err != nil {
+		return nil, err
+	}
+
+	response := organization.ToResponse()
+	response.Role = models.OrganizationRoleOwner
+	return &response, nil
+}
+
+// ListForUser lists the organizations the user belongs to with their role in each
+func (s *organizationService) ListForUser(userID uint) ([]models.OrganizationResponse, error) {
+	members, err := s.organizationRepo.GetMembershipsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.OrganizationResponse, 0, len(members))
+	for _, member := range members {
+		response := member.Organization.ToResponse()
+		response.Role = member.Role
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// ListMembers lists the members of an organization the user belongs to
+func (s *organizationService) ListMembers(organizationID, userID uint) ([]models.OrganizationMemberResponse, error) {
+	if _, err := s.membership(organizationID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.organizationRepo.GetMembers(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.OrganizationMemberResponse, 0, len(members))
+	for i := range members {
+		responses = append(responses, members[i].ToResponse())
+	}
+	return responses, nil
+}
+
+// AddMember adds an existing user to the organization without their consent, so
+// the route is limited to holders of organizations:add_members; everyone else
+// joins by accepting an invitation. The actor must also be an owner or admin of
+// the organization, and only owners add owners.
+func (s *organizationService) AddMember(organizationID, actorID uint, req models.AddOrganizationMemberRequest) (*models.OrganizationMemberResponse, error) {
+	actor, err := s.membership(organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanAssignRole(req.Role) {
+		return nil, ErrOrganizationForbidden
+	}
+
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := s.organizationRepo.GetMembership(organizationID, user.ID); err == nil {
+		return nil, ErrAlreadyOrganizationMember
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         user.ID,
+		Role:           req.Role,
+		User:           *user,
+	}
+	if err := s.organizationRepo.AddMember(member); err != nil {
+		return nil, err
+	}
+
+	response := member.ToResponse()
+	return &response, nil
+}
+
+// RemoveMember removes a user from the organization. Members may remove
+// themselves; owners and admins remove others, and only owners remove owners.
+// The last owner cannot be removed.
+func (s *organizationService) RemoveMember(organizationID, actorID, userID uint) error {
+	actor, err := s.membership(organizationID, actorID)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.organizationRepo.GetMembership(organizationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrganizationMemberNotFound
+		}
+		return err
+	}
+
+	if actorID != userID && !actor.CanAssignRole(member.Role) {
+		return ErrOrganizationForbidden
+	}
+
+	if member.Role == models.OrganizationRoleOwner {
+		owners, err := s.organizationRepo.CountOwners(organizationID)
+		if err != nil {
+			return err
+		}
+		if owners <= 1 {
+			return ErrLastOrganizationOwner
+		}
+	}
+
+	removed, err := s.organizationRepo.RemoveMember(organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrOrganizationMemberNotFound
+	}
+	return nil
+}
+
+// ResolveTenant resolves an organization ID or slug for the tenant middleware and
+// reports whether the user is a member. Unknown organizations are reported as
+// not a member so the response does not reveal which organizations exist.
+func (s *organizationService) ResolveTenant(ref string, userID uint) (uint, bool, error) {
+	var organization *models.Organization
+	var err error
+	if id, parseErr := strconv.ParseUint(ref, 10, 32); parseErr == nil {
+		organization, err = s.organizationRepo.GetByID(uint(id))
+	} else {
+		organization, err = s.organizationRepo.GetBySlug(ref)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	if _, err := s.organizationRepo.GetMembership(organization.ID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return organization.ID, false, nil
+		}
+		return 0, false, err
+	}
+	return organization.ID, true, nil
+}
+
+// MemberOrganizations lists the IDs of the organizations the user belongs to, for
+// requests to the tenant middleware that select none
+func (s *organizationService) MemberOrganizations(userID uint) ([]uint, error) {
+	members, err := s.organizationRepo.GetMembershipsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.OrganizationID)
+	}
+	return ids, nil
+}
+
+// membership gets the user's membership, reporting organizations the user does not belong to as not found
+func (s *organizationService) membership(organizationID, userID uint) (*models.OrganizationMember, error) {
+	member, err := s.organizationRepo.GetMembership(organizationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
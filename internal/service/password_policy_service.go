@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+)
+
+var (
+	// ErrPasswordPolicy is returned when a new password does not meet the password policy
+	ErrPasswordPolicy = errors.New("password does not meet the password policy")
+	// ErrPasswordExpired is returned by Login when the password is older than the maximum password age
+	ErrPasswordExpired = errors.New("password has expired, please reset it")
+)
+
+// PasswordPolicyError wraps ErrPasswordPolicy with every rule the password breaks
+type PasswordPolicyError struct {
+	Violations []models.PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrPasswordPolicy.Error(), strings.Join(messages, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrPasswordPolicy)
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrPasswordPolicy
+}
+
+// PasswordPolicyService interface defines password policy methods
+type PasswordPolicyService interface {
+	Check(user *models.User, password string) error
+	Remember(userID uint, previousHash string) error
+	IsExpired(user *models.User) bool
+}
+
+// passwordPolicyService implements PasswordPolicyService interface
+type passwordPolicyService struct {
+	historyRepo repository.PasswordHistoryRepository
+	hasher      utils.PasswordHasher
+	cfg         config.PasswordConfig
+}
+
+// NewPasswordPolicyService creates a new password policy service
+func NewPasswordPolicyService(
+	historyRepo repository.PasswordHistoryRepository,
+	hasher utils.PasswordHasher,
+	cfg config.PasswordConfig,
+) PasswordPolicyService {
+	return &passwordPolicyService{
+		historyRepo: historyRepo,
+		hasher:      hasher,
+		cfg:         cfg,
+	}
+}
+
+// Check validates a new password against the policy. user is the account whose
+// password changes, or nil for a new account. Every broken rule is reported in
+// a *PasswordPolicyError.
+func (s *passwordPolicyService) Check(user *models.User, password string) error {
+	violations := s.checkRules(password)
+
+	breached, err := s.isBreached(password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		violations = append(violations, models.PasswordViolation{
+			Code:    models.PasswordBreached,
+			Message: "Password has appeared in a data breach and cannot be used",
+		})
+	}
+
+	if user != nil {
+		reused, err := s.isReused(user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, models.PasswordViolation{
+				Code:    models.PasswordReused,
+				Message: fmt.Sprintf("Password must differ from your last %d passwords", s.cfg.HistorySize),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Remember adds a replaced password hash to the user's history and drops
+// entries that no longer count towards the history size
+func (s *passwordPolicyService) Remember(userID uint, previousHash string) error {
+	// The current password is always checked, so the history holds one entry less
+	keep := s.cfg.HistorySize - 1
+	if keep <= 0 || previousHash == "" {
+		return nil
+	}
+
+	entry := &models.PasswordHistory{
+		UserID:       userID,
+		PasswordHash: previousHash,
+	}
+	if err := s.historyRepo.Create(entry); err != nil {
+		return err
+	}
+	return s.historyRepo.Prune(userID, keep)
+}
+
+// IsExpired reports whether the user's password is older than the maximum password age.
+// Passwords set before their change time was tracked count from account creation.
+func (s *passwordPolicyService) IsExpired(user *models.User) bool {
+	// Directory passwords expire under the directory's own policy
+	if s.cfg.MaxAge <= 0 || !user.IsLocal() {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > s.cfg.MaxAge
+}
+
+// checkRules checks the length and character class rules
+func (s *passwordPolicyService) checkRules(password string) []models.PasswordViolation {
+	var violations []models.PasswordViolation
+
+	length := utf8.RuneCountInString(password)
+	if length < s.cfg.MinLength {
+		violations = append(violations, models.PasswordViolation{
+			Code:    models.PasswordTooShort,
+			Message: fmt.Sprintf("Password must be at least %d characters long", s.cfg.MinLength),
+		})
+	}
+	if s.cfg.MaxLength > 0 && length > s.cfg.MaxLength {
+		violations = append(violations, models.PasswordViolation{
+			Code:    models.PasswordTooLong,
+			Message: fmt.Sprintf("Password must be at most %d characters long", s.cfg.MaxLength),
+		})
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	if s.cfg.RequireUppercase && !upper {
+		violations = append(violations, models.PasswordViolation{
+			Code:    models.PasswordMissingUppercase,
+			Message: "Password must contain an uppercase letter",
+		})
+	}
+	if s.cfg.RequireLowercase && !lower {
+		violations = append(violations, models.PasswordViolation{
+			Code:    models.PasswordMissingLowercase,
+			Message: "Password must contain a lowercase letter",
+		})
+	}
+	if s.cfg.RequireDigit && !digit {
+		violations = append(violations, models.PasswordViolation{
+			Code:    models.PasswordMissingDigit,
+			Message: "Password must contain a digit",
+		})
+	}
+	if s.cfg.RequireSymbol && !symbol {
+		violations = append(violations, models.PasswordViolation{
+			Code:    models.PasswordMissingSymbol,
+			Message: "Password must contain a symbol",
+		})
+	}
+
+	return violations
+}
+
+// isReused reports whether the password matches the user's current password or
+// one of the previous passwords kept in the history
+func (s *passwordPolicyService) isReused(user *models.User, password string) (bool, error) {
+	if s.cfg.HistorySize <= 0 {
+		return false, nil
+	}
+	if user.Password != "" && s.hasher.Verify(password, user.Password) {
+		return true, nil
+	}
+	if s.cfg.HistorySize == 1 {
+		return false, nil
+	}
+
+	entries, err := s.historyRepo.GetRecent(user.ID, s.cfg.HistorySize-1)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if s.hasher.Verify(password, entry.PasswordHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isBreached looks the password up in the local breached password list. The list
+// uses the k-anonymity range format: the first 5 hex characters of the SHA-1 hash
+// name the file, which holds the remaining 35 characters and a count per line.
+func (s *passwordPolicyService) isBreached(password string) (bool, error) {
+	if s.cfg.BreachedListDir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(s.cfg.BreachedListDir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(entry, suffix) {
+			continue
+		}
+		// Padded range files list fake suffixes with a count of 0
+		n, err := strconv.Atoi(count)
+		return err == nil && n > 0, nil
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+)
+
+// fakePasswordHistoryRepo keeps password history entries in memory, newest first
+type fakePasswordHistoryRepo struct {
+	entries []models.PasswordHistory
+}
+
+func (r *fakePasswordHistoryRepo) Create(entry *models.PasswordHistory) error {
+	r.entries = append([]models.PasswordHistory{*entry}, r.entries...)
+	return nil
+}
+
+func (r *fakePasswordHistoryRepo) GetRecent(userID uint, limit int) ([]models.PasswordHistory, error) {
+	var recent []models.PasswordHistory
+	for _, entry := range r.entries {
+		if entry.UserID == userID && len(recent) < limit {
+			recent = append(recent, entry)
+		}
+	}
+	return recent, nil
+}
+
+func (r *fakePasswordHistoryRepo) Prune(userID uint, keep int) error {
+	kept, err := r.GetRecent(userID, keep)
+	r.entries = kept
+	return err
+}
+
+// writeBreachedList writes a range file for the SHA-1 prefix 5BAA6, which holds "password"
+func writeBreachedList(t *testing.T, lines string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func violationCodes(err error) []string {
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil
+	}
+	codes := make([]string, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		codes = append(codes, v.Code)
+	}
+	return codes
+}
+
+func TestIsBreached(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		password string
+		want     bool
+	}{
+		{"listed", "0018A45C4D1DEF81644B54AB7F969B88D65:1\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\n", "password", true},
+		{"lowercase suffix and CRLF", "1e4c9b93f3f0682250b6cf8331b7ee68fd8:3\r\n", "password", true},
+		{"padding entry", "1E4C9B93F3F0682250B6CF8331B7EE68FD8:0\n", "password", false},
+		{"malformed count", "1E4C9B93F3F0682250B6CF8331B7EE68FD8:many\n", "password", false},
+		{"not listed", "0018A45C4D1DEF81644B54AB7F969B88D65:1\n", "password", false},
+		{"no range file", "1E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n", "correct horse battery staple", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &passwordPolicyService{cfg: config.PasswordConfig{BreachedListDir: writeBreachedList(t, tt.list)}}
+			breached, err := s.isBreached(tt.password)
+			if err != nil {
+				t.Fatalf("isBreached returned error: %v", err)
+			}
+			if breached != tt.want {
+				t.Errorf("isBreached = %v, want %v", breached, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBreachedWithoutList(t *testing.T) {
+	s := &passwordPolicyService{}
+	if breached, err := s.isBreached("password"); err != nil || breached {
+		t.Fatalf("isBreached without a list = %v, %v; want false", breached, err)
+	}
+}
+
+func TestCheckReportsEveryViolation(t *testing.T) {
+	s := NewPasswordPolicyService(&fakePasswordHistoryRepo{}, fakeHasher{}, config.PasswordConfig{
+		MinLength:        12,
+		RequireUppercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		BreachedListDir:  writeBreachedList(t, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\n"),
+	})
+
+	got := violationCodes(s.Check(nil, "password"))
+	want := []string{
+		models.PasswordTooShort,
+		models.PasswordMissingUppercase,
+		models.PasswordMissingDigit,
+		models.PasswordMissingSymbol,
+		models.PasswordBreached,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Check violations = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("violation %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	if err := s.Check(nil, "Tr0ub4dor&3 horse"); err != nil {
+		t.Errorf("Check rejected a password that meets the policy: %v", err)
+	}
+}
+
+func TestCheckRejectsReusedPasswords(t *testing.T) {
+	history := &fakePasswordHistoryRepo{}
+	s := NewPasswordPolicyService(history, fakeHasher{}, config.PasswordConfig{HistorySize: 3})
+	user := &models.User{ID: 1, Password: "hashed:current"}
+
+	for _, previous := range []string{"oldest", "older", "old"} {
+		if err := s.Remember(user.ID, "hashed:"+previous); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The current password and the last two replaced ones count; older ones were pruned
+	for password, reused := range map[string]bool{"current": true, "old": true, "older": true, "oldest": false, "new": false} {
+		codes := violationCodes(s.Check(user, password))
+		if got := len(codes) == 1 && codes[0] == models.PasswordReused; got != reused {
+			t.Errorf("Check(%q) violations = %v, want reused %v", password, codes, reused)
+		}
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	s := NewPasswordPolicyService(nil, fakeHasher{}, config.PasswordConfig{MaxAge: 24 * time.Hour})
+	old := time.Now().Add(-48 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		user models.User
+		want bool
+	}{
+		{"changed recently", models.User{CreatedAt: old, PasswordChangedAt: &recent}, false},
+		{"changed long ago", models.User{CreatedAt: old, PasswordChangedAt: &old}, true},
+		{"never changed", models.User{CreatedAt: old}, true},
+		{"directory account", models.User{CreatedAt: old, AuthSource: models.AuthSourceLDAP}, false},
+	}
+
+	for _, tt := range tests {
+		if got := s.IsExpired(&tt.user); got != tt.want {
+			t.Errorf("%s: IsExpired = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
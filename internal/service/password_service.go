@@ -0,0 +1,250 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/mailer"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidResetToken is returned for unknown, used or expired password reset tokens
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	// ErrIncorrectPassword is returned when the current password does not match
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	// ErrPasswordUnchanged is returned when the new password equals the current one
+	ErrPasswordUnchanged = errors.New("new password must be different from the current password")
+	// ErrDirectoryPassword is returned when changing the password of a user authenticated by a directory
+	ErrDirectoryPassword = errors.New("password is managed by the directory service")
+)
+
+// PasswordService interface defines password recovery methods
+type PasswordService interface {
+	ForgotPassword(email string) error
+	ResetPassword(req models.ResetPasswordRequest) error
+	ChangePassword(userID uint, req models.ChangePasswordRequest, client models.ClientInfo) (*models.ChangePasswordResponse, error)
+	PurgeExpired() error
+}
+
+// passwordService implements PasswordService interface
+type passwordService struct {
+	userRepo       repository.UserRepository
+	resetRepo      repository.PasswordResetRepository
+	tokenService   TokenService
+	passwordPolicy PasswordPolicyService
+	mailer         mailer.Mailer
+	hasher         utils.PasswordHasher
+	appCfg         config.AppConfig
+	authCfg        config.AuthConfig
+}
+
+// NewPasswordService creates a new password service
+func NewPasswordService(
+	userRepo repository.UserRepository,
+	resetRepo repository.PasswordResetRepository,
+	tokenService TokenService,
+	passwordPolicy PasswordPolicyService,
+	mailer mailer.Mailer,
+	hasher utils.PasswordHasher,
+	appCfg config.AppConfig,
+	authCfg config.AuthConfig,
+) PasswordService {
+	return &passwordService{
+		userRepo:       userRepo,
+		resetRepo:      resetRepo,
+		tokenService:   tokenService,
+		passwordPolicy: passwordPolicy,
+		mailer:         mailer,
+		hasher:         hasher,
+		appCfg:         appCfg,
+		authCfg:        authCfg,
+	}
+}
+
+// ForgotPassword sends a password reset link to the user.
+// It does not report whether the email belongs to an account.
+// Directory users get no link since their password is not stored here.
+func (s *passwordService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsLocal() {
+		return nil
+	}
+
+	// Only the most recent link stays valid
+	if err := s.resetRepo.DeleteForUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	reset := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.authCfg.PasswordResetTTL),
+	}
+	if err := s.resetRepo.Create(reset); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appCfg.URL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Reset your %s password", s.appCfg.Name),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password. The link expires in %s and can only be used once.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			user.Name, s.authCfg.PasswordResetTTL, link,
+		),
+	}
+
+	// Send in the background so the response time does not reveal whether the account exists
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("failed to send password reset email: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user out everywhere
+func (s *passwordService) ResetPassword(req models.ResetPasswordRequest) error {
+	reset, err := s.resetRepo.GetByHash(utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	marked, err := s.resetRepo.MarkUsed(reset.ID)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetByID(reset.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !user.IsLocal() {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.passwordPolicy.Check(user, req.Password); err != nil {
+		return err
+	}
+	if err := s.setPassword(user, req.Password); err != nil {
+		return err
+	}
+
+	// Existing sessions may belong to whoever had the old password
+	return s.tokenService.LogoutAll(user.ID)
+}
+
+// ChangePassword replaces the user's password after checking the current one.
+// When requested, every other session is revoked and a fresh token pair is returned for the caller.
+func (s *passwordService) ChangePassword(userID uint, req models.ChangePasswordRequest, client models.ClientInfo) (*models.ChangePasswordResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if !user.IsLocal() {
+		return nil, ErrDirectoryPassword
+	}
+
+	// Guesses of the current password count towards the account lockout like failed logins
+	if user.IsLocked(time.Now()) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+	if !s.hasher.Verify(req.CurrentPassword, user.Password) {
+		if err := recordFailedLogin(s.userRepo, s.authCfg, user); err != nil {
+			return nil, err
+		}
+		return nil, ErrIncorrectPassword
+	}
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ClearFailedLogins(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.CurrentPassword == req.Password {
+		return nil, ErrPasswordUnchanged
+	}
+
+	if err := s.passwordPolicy.Check(user, req.Password); err != nil {
+		return nil, err
+	}
+	if err := s.setPassword(user, req.Password); err != nil {
+		return nil, err
+	}
+
+	response := &models.ChangePasswordResponse{}
+	if !req.RevokeOtherSessions {
+		return response, nil
+	}
+
+	if err := s.tokenService.LogoutAll(user.ID); err != nil {
+		return nil, err
+	}
+	tokens, err := s.tokenService.IssueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+	response.Tokens = tokens
+
+	return response, nil
+}
+
+// PurgeExpired removes password reset tokens that can no longer be used
+func (s *passwordService) PurgeExpired() error {
+	return s.resetRepo.DeleteExpired()
+}
+
+// setPassword stores a new password for the user and keeps the replaced one in
+// the password history
+func (s *passwordService) setPassword(user *models.User, password string) error {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	previousHash := user.Password
+	now := time.Now()
+	user.Password = hashedPassword
+	user.PasswordChangedAt = &now
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	return s.passwordPolicy.Remember(user.ID, previousHash)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrRoleNotFound is returned when a role name does not exist
+var ErrRoleNotFound = errors.New("role not found")
+
+// RoleService interface defines role-based access control methods
+type RoleService interface {
+	ListRoles() ([]models.RoleResponse, error)
+	AssignRole(ctx context.Context, claims *utils.JWTClaims, userID uint, roleName string) (*models.UserResponse, error)
+	RemoveRole(ctx context.Context, claims *utils.JWTClaims, userID uint, roleName string) (*models.UserResponse, error)
+	HasPermission(roles []string, permission string) (bool, error)
+}
+
+// roleService implements RoleService interface
+type roleService struct {
+	roleRepo             repository.RoleRepository
+	userRepo             repository.UserRepository
+	tokenService         TokenService
+	authorizationService AuthorizationService
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(roleRepo repository.RoleRepository, userRepo repository.UserRepository, tokenService TokenService, authorizationService AuthorizationService) RoleService {
+	return &roleService{
+		roleRepo:             roleRepo,
+		userRepo:             userRepo,
+		tokenService:         tokenService,
+		authorizationService: authorizationService,
+	}
+}
+
+// ListRoles gets every role with its permissions
+func (s *roleService) ListRoles() ([]models.RoleResponse, error) {
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, role.ToResponse())
+	}
+	return responses, nil
+}
+
+// AssignRole gives a user a role if the authorization policy allows roles:assign
+func (s *roleService) AssignRole(ctx context.Context, claims *utils.JWTClaims, userID uint, roleName string) (*models.UserResponse, error) {
+	return s.changeRole(ctx, claims, models.ActionRolesAssign, userID, roleName, s.roleRepo.AssignToUser)
+}
+
+// RemoveRole takes a role away from a user if the authorization policy allows roles:remove
+func (s *roleService) RemoveRole(ctx context.Context, claims *utils.JWTClaims, userID uint, roleName string) (*models.UserResponse, error) {
+	return s.changeRole(ctx, claims, models.ActionRolesRemove, userID, roleName, s.roleRepo.RemoveFromUser)
+}
+
+// HasPermission reports whether any of the roles grants the permission
+func (s *roleService) HasPermission(roles []string, permission string) (bool, error) {
+	return s.roleRepo.HasPermission(roles, permission)
+}
+
+// changeRole authorizes and applies a role change and revokes the user's access
+// tokens so the roles embedded in them are refreshed
+func (s *roleService) changeRole(ctx context.Context, claims *utils.JWTClaims, action string, userID uint, roleName string, apply func(uint, *models.Role) error) (*models.UserResponse, error) {
+	if err := s.authorizationService.AuthorizeRole(ctx, claims, action, userID, roleName); err != nil {
+		return nil, err
+	}
+
+	users := s.userRepo.WithContext(ctx)
+	user, err := users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	if err := apply(user.ID, role); err != nil {
+		return nil, err
+	}
+	if err := s.tokenService.RevokeAccessTokens(user.ID); err != nil {
+		return nil, err
+	}
+
+	user, err = users.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := user.ToResponse()
+	return &response, nil
+}
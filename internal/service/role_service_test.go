@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/policy"
+	"golang-starter-kit/utils"
+)
+
+// newRoleTest holds Jane, the only admin, and John, who holds no role.
+// The support role grants users:read and the admin role roles:manage.
+func newRoleTest(t *testing.T) (RoleService, *fakeUserRepo) {
+	t.Helper()
+
+	users := &fakeUserRepo{}
+	roles := &fakeRoleRepo{users: users, roles: []models.Role{
+		{Name: models.RoleAdmin, Permissions: []models.Permission{{Name: models.PermissionRolesManage}}},
+		{Name: "support", Permissions: []models.Permission{{Name: models.PermissionUsersRead}}},
+	}}
+	_ = users.Create(&models.User{Name: "Jane", Email: "jane@example.com", Roles: []models.Role{roles.roles[0]}})
+	_ = users.Create(&models.User{Name: "John", Email: "john@example.com"})
+
+	rules, err := policy.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorization := NewAuthorizationService(rules, users, roles, &fakeOrganizationRepo{users: users})
+	return NewRoleService(roles, users, fakeTokenService{}, authorization), users
+}
+
+func TestRoleServiceAuthorizesRoleChanges(t *testing.T) {
+	s, users := newRoleTest(t)
+	ctx := context.Background()
+	admin := &utils.JWTClaims{UserID: 1, Roles: []string{models.RoleAdmin}}
+	support := &utils.JWTClaims{UserID: 2, Roles: []string{"support"}}
+
+	if _, err := s.AssignRole(ctx, support, 2, models.RoleAdmin); !errors.Is(err, policy.ErrDenied) {
+		t.Fatalf("AssignRole without roles:manage = %v, want a policy denial", err)
+	}
+	john, _ := users.GetByID(2)
+	if len(john.Roles) != 0 {
+		t.Fatalf("a denied assignment gave John the roles %v", john.RoleNames())
+	}
+
+	if user, err := s.AssignRole(ctx, admin, 2, "support"); err != nil || !slices.Contains(user.Roles, "support") {
+		t.Fatalf("AssignRole = %+v, %v; want John to hold support", user, err)
+	}
+	if _, err := s.RemoveRole(ctx, support, 2, "support"); !errors.Is(err, policy.ErrDenied) {
+		t.Errorf("RemoveRole without roles:manage = %v, want a policy denial", err)
+	}
+	if _, err := s.AssignRole(ctx, admin, 2, "auditor"); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("AssignRole of an unknown role = %v, want ErrRoleNotFound", err)
+	}
+	if _, err := s.AssignRole(ctx, admin, 9, "support"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("AssignRole to an unknown user = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestRoleServiceKeepsTheLastAdmin(t *testing.T) {
+	s, _ := newRoleTest(t)
+	ctx := context.Background()
+	admin := &utils.JWTClaims{UserID: 1, Roles: []string{models.RoleAdmin}}
+
+	var denied *policy.DeniedError
+	if _, err := s.RemoveRole(ctx, admin, 1, models.RoleAdmin); !errors.As(err, &denied) || denied.Rule != "protect-last-admin-role" {
+		t.Fatalf("RemoveRole of the last admin's admin role = %v, want a protect-last-admin-role denial", err)
+	}
+
+	// With a second admin either may step down
+	if _, err := s.AssignRole(ctx, admin, 2, models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := s.RemoveRole(ctx, admin, 1, models.RoleAdmin); err != nil || slices.Contains(user.Roles, models.RoleAdmin) {
+		t.Errorf("RemoveRole with another admin left = %+v, %v; want Jane without the admin role", user, err)
+	}
+}
@@ -0,0 +1,659 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/policy"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// scimMaxResults is the largest page of users a SCIM list request returns
+const scimMaxResults = 100
+
+// SCIM error types (RFC 7644 section 3.12)
+const (
+	scimInvalidFilter = "invalidFilter"
+	scimInvalidValue  = "invalidValue"
+	scimInvalidPath   = "invalidPath"
+	scimNoTarget      = "noTarget"
+	scimMutability    = "mutability"
+	scimUniqueness    = "uniqueness"
+	scimInvalidSyntax = "invalidSyntax"
+)
+
+// SCIMError is a SCIM request error with the HTTP status and SCIM error type to report it with
+type SCIMError struct {
+	Status int
+	Type   string
+	Detail string
+}
+
+func (e *SCIMError) Error() string {
+	return e.Detail
+}
+
+// scimError creates a SCIMError
+func scimError(status int, scimType, detail string) *SCIMError {
+	return &SCIMError{Status: status, Type: scimType, Detail: detail}
+}
+
+// scimFilterPattern matches filters of the form `attribute operator "value"`
+var scimFilterPattern = regexp.MustCompile(`^\s*([A-Za-z.]+)\s+([A-Za-z]+)\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// scimEmailValuePath matches the value path of an email by type, e.g. emails[type eq "work"].value
+var scimEmailValuePath = regexp.MustCompile(`(?i)^emails\[type eq "[^"]*"\]\.value$`)
+
+// SCIMService interface defines SCIM 2.0 user provisioning methods. Users are
+// those of the organization ctx selects; changes are authorized by the policy.
+type SCIMService interface {
+	ListUsers(ctx context.Context, req models.SCIMListRequest) (*models.SCIMListResponse, error)
+	GetUser(ctx context.Context, id uint) (*models.SCIMUser, error)
+	CreateUser(ctx context.Context, req models.SCIMUser) (*models.SCIMUser, error)
+	ReplaceUser(ctx context.Context, id uint, req models.SCIMUser) (*models.SCIMUser, error)
+	PatchUser(ctx context.Context, id uint, req models.SCIMPatchRequest) (*models.SCIMUser, error)
+	DeleteUser(ctx context.Context, id uint) error
+	ServiceProviderConfig() models.SCIMServiceProviderConfig
+	ResourceTypes() models.SCIMListResponse
+	Schemas() models.SCIMListResponse
+}
+
+// scimService implements SCIMService interface
+type scimService struct {
+	userService          UserService
+	userRepo             repository.UserRepository
+	tokenService         TokenService
+	passwordPolicy       PasswordPolicyService
+	authorizationService AuthorizationService
+	hasher               utils.PasswordHasher
+	validator            *validator.Validate
+	baseURL              string
+}
+
+// NewSCIMService creates a new SCIM service. Resources are located under APP_URL/scim/v2.
+func NewSCIMService(
+	userService UserService,
+	userRepo repository.UserRepository,
+	tokenService TokenService,
+	passwordPolicy PasswordPolicyService,
+	authorizationService AuthorizationService,
+	hasher utils.PasswordHasher,
+	appCfg config.AppConfig,
+) SCIMService {
+	return &scimService{
+		userService:          userService,
+		userRepo:             userRepo,
+		tokenService:         tokenService,
+		passwordPolicy:       passwordPolicy,
+		authorizationService: authorizationService,
+		hasher:               hasher,
+		validator:            validator.New(),
+		baseURL:              appCfg.URL + "/scim/v2",
+	}
+}
+
+// ListUsers gets a page of users, optionally filtered on userName or emails
+// with the eq, co or sw operator
+func (s *scimService) ListUsers(ctx context.Context, req models.SCIMListRequest) (*models.SCIMListResponse, error) {
+	operator, value, err := parseSCIMFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	startIndex := max(req.StartIndex, 1)
+	count := scimMaxResults
+	if req.Count != nil {
+		count = min(max(*req.Count, 0), scimMaxResults)
+	}
+
+	users, total, err := s.userRepo.WithContext(ctx).ListByEmail(operator, value, startIndex-1, count)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]models.SCIMUser, 0, len(users))
+	for i := range users {
+		resources = append(resources, users[i].ToSCIM(s.baseURL))
+	}
+	return &models.SCIMListResponse{
+		Schemas:      []string{models.SCIMMessageListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// GetUser gets a user
+func (s *scimService) GetUser(ctx context.Context, id uint) (*models.SCIMUser, error) {
+	user, err := s.user(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := user.ToSCIM(s.baseURL)
+	return &resource, nil
+}
+
+// CreateUser creates a user. The identity provider vouches for the email
+// address, so it counts as verified. Without a password the user gets an
+// unusable random one and signs in through password reset, a magic link or
+// social login. The user joins the organization ctx selects.
+func (s *scimService) CreateUser(ctx context.Context, req models.SCIMUser) (*models.SCIMUser, error) {
+	user := &models.User{}
+	if err := s.apply(user, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailAvailable(user.Email, 0); err != nil {
+		return nil, err
+	}
+
+	password := req.Password
+	if password != "" {
+		if err := s.passwordPolicy.Check(nil, password); err != nil {
+			return nil, scimError(http.StatusBadRequest, scimInvalidValue, err.Error())
+		}
+	} else {
+		var err error
+		if password, err = utils.GenerateRandomToken(32); err != nil {
+			return nil, err
+		}
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.Password = hashedPassword
+	user.PasswordChangedAt = &now
+	user.EmailVerifiedAt = &now
+
+	if err := s.userRepo.WithContext(ctx).Create(user); err != nil {
+		return nil, err
+	}
+
+	resource := user.ToSCIM(s.baseURL)
+	return &resource, nil
+}
+
+// ReplaceUser replaces the user's attributes. Deactivating the user signs them
+// out everywhere.
+func (s *scimService) ReplaceUser(ctx context.Context, id uint, req models.SCIMUser) (*models.SCIMUser, error) {
+	user, err := s.user(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.replace(ctx, user, req)
+}
+
+// PatchUser applies add, replace and remove operations to the user
+func (s *scimService) PatchUser(ctx context.Context, id uint, req models.SCIMPatchRequest) (*models.SCIMUser, error) {
+	user, err := s.user(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := user.ToSCIM(s.baseURL)
+	for _, operation := range req.Operations {
+		if err := patchSCIMUser(&resource, operation); err != nil {
+			return nil, err
+		}
+	}
+	return s.replace(ctx, user, resource)
+}
+
+// DeleteUser deletes the user and revokes their sessions and tokens
+func (s *scimService) DeleteUser(ctx context.Context, id uint) error {
+	if err := s.authorize(ctx, models.PermissionUsersDelete, id); err != nil {
+		return err
+	}
+	if err := s.userService.DeleteUser(ctx, id); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return scimError(http.StatusNotFound, "", err.Error())
+		}
+		return err
+	}
+	return s.tokenService.LogoutAll(id)
+}
+
+// ServiceProviderConfig describes the supported SCIM features
+func (s *scimService) ServiceProviderConfig() models.SCIMServiceProviderConfig {
+	return models.SCIMServiceProviderConfig{
+		Schemas:        []string{models.SCIMSchemaServiceProviderConfig},
+		Patch:          models.SCIMSupported{Supported: true},
+		Bulk:           models.SCIMBulkSupport{Supported: false},
+		Filter:         models.SCIMFilterSupport{Supported: true, MaxResults: scimMaxResults},
+		ChangePassword: models.SCIMSupported{Supported: true},
+		Sort:           models.SCIMSupported{Supported: false},
+		ETag:           models.SCIMSupported{Supported: false},
+		AuthenticationSchemes: []models.SCIMAuthenticationType{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer Token",
+			Description: "Authentication with the SCIM_TOKEN bearer token",
+			Primary:     true,
+		}},
+		Meta: &models.SCIMMeta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     s.baseURL + "/ServiceProviderConfig",
+		},
+	}
+}
+
+// ResourceTypes lists the served resource types
+func (s *scimService) ResourceTypes() models.SCIMListResponse {
+	resourceTypes := []models.SCIMResourceType{{
+		Schemas:     []string{models.SCIMSchemaResourceType},
+		ID:          "User",
+		Name:        "User",
+		Endpoint:    "/Users",
+		Description: "User Account",
+		Schema:      models.SCIMSchemaUser,
+		Meta: &models.SCIMMeta{
+			ResourceType: "ResourceType",
+			Location:     s.baseURL + "/ResourceTypes/User",
+		},
+	}}
+	return scimList(resourceTypes, len(resourceTypes))
+}
+
+// Schemas lists the schemas of the served resources
+func (s *scimService) Schemas() models.SCIMListResponse {
+	text := func(name, description string, required bool) models.SCIMAttribute {
+		return models.SCIMAttribute{
+			Name:        name,
+			Type:        "string",
+			Description: description,
+			Required:    required,
+			Mutability:  "readWrite",
+			Returned:    "default",
+			Uniqueness:  "none",
+		}
+	}
+
+	userName := text("userName", "The user's email address", true)
+	userName.Uniqueness = "server"
+	password := text("password", "The user's password", false)
+	password.Mutability = "writeOnly"
+	password.Returned = "never"
+	emails := text("emails", "Email addresses; the primary one is the userName", false)
+	emails.Type = "complex"
+	emails.MultiValued = true
+	emails.SubAttributes = []models.SCIMAttribute{
+		text("value", "Email address", true),
+		text("type", "Label such as work", false),
+		{Name: "primary", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+	}
+	name := text("name", "The user's name", false)
+	name.Type = "complex"
+	name.SubAttributes = []models.SCIMAttribute{
+		text("formatted", "Full name", false),
+		text("givenName", "Given name", false),
+		text("familyName", "Family name", false),
+	}
+
+	schemas := []models.SCIMSchema{{
+		Schemas:     []string{models.SCIMSchemaSchema},
+		ID:          models.SCIMSchemaUser,
+		Name:        "User",
+		Description: "User Account",
+		Attributes: []models.SCIMAttribute{
+			userName,
+			name,
+			text("displayName", "The user's full name", false),
+			emails,
+			{Name: "active", Type: "boolean", Description: "Whether the user can sign in", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+			password,
+		},
+		Meta: &models.SCIMMeta{
+			ResourceType: "Schema",
+			Location:     s.baseURL + "/Schemas/" + models.SCIMSchemaUser,
+		},
+	}}
+	return scimList(schemas, len(schemas))
+}
+
+// replace applies the resource's attributes and password to the user and
+// signs the user out everywhere when it deactivates them
+func (s *scimService) replace(ctx context.Context, user *models.User, req models.SCIMUser) (*models.SCIMUser, error) {
+	if err := s.authorize(ctx, models.PermissionUsersUpdate, user.ID); err != nil {
+		return nil, err
+	}
+
+	wasActive := !user.IsDeactivated()
+	previousEmail := user.Email
+	if err := s.apply(user, req); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, previousEmail) {
+		if err := s.authorize(ctx, models.ActionUsersUpdateEmail, user.ID); err != nil {
+			return nil, err
+		}
+		if err := s.checkEmailAvailable(user.Email, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	previousHash := ""
+	if req.Password != "" {
+		if err := s.passwordPolicy.Check(user, req.Password); err != nil {
+			return nil, scimError(http.StatusBadRequest, scimInvalidValue, err.Error())
+		}
+		hashedPassword, err := s.hasher.Hash(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		previousHash = user.Password
+		user.Password = hashedPassword
+		user.PasswordChangedAt = &now
+	}
+
+	if err := s.userRepo.WithContext(ctx).Update(user); err != nil {
+		return nil, err
+	}
+	if previousHash != "" {
+		if err := s.passwordPolicy.Remember(user.ID, previousHash); err != nil {
+			return nil, err
+		}
+	}
+	if wasActive && user.IsDeactivated() {
+		if err := s.tokenService.LogoutAll(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	resource := user.ToSCIM(s.baseURL)
+	return &resource, nil
+}
+
+// apply copies the resource's userName (or primary email), name, externalId
+// and active flag to the user. The name is taken from displayName, name.formatted
+// or the given and family names, in that order.
+func (s *scimService) apply(user *models.User, req models.SCIMUser) error {
+	email := models.NormalizeEmail(req.UserName)
+	if email == "" {
+		email = models.NormalizeEmail(primarySCIMEmail(req.Emails))
+	}
+	if err := s.validator.Var(email, "required,email"); err != nil {
+		return scimError(http.StatusBadRequest, scimInvalidValue, "userName must be an email address")
+	}
+	if !strings.EqualFold(email, user.Email) {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	user.Email = email
+
+	name := req.DisplayName
+	if name == "" && req.Name != nil {
+		name = req.Name.Formatted
+		if name == "" {
+			name = strings.TrimSpace(req.Name.GivenName + " " + req.Name.FamilyName)
+		}
+	}
+	if name == "" && user.Name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	if name != "" {
+		if err := s.validator.Var(name, "min=2,max=100"); err != nil {
+			return scimError(http.StatusBadRequest, scimInvalidValue, "name must be between 2 and 100 characters")
+		}
+		user.Name = name
+	}
+
+	user.ExternalID = req.ExternalID
+
+	if req.Active != nil {
+		switch {
+		case *req.Active:
+			user.DeactivatedAt = nil
+		case !user.IsDeactivated():
+			now := time.Now()
+			user.DeactivatedAt = &now
+		}
+	}
+	return nil
+}
+
+// checkEmailAvailable checks that no other user has the email address
+func (s *scimService) checkEmailAvailable(email string, userID uint) error {
+	users, _, err := s.userRepo.ListByEmail("eq", email, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 && users[0].ID != userID {
+		return scimError(http.StatusConflict, scimUniqueness, "a user with this userName already exists")
+	}
+	return nil
+}
+
+// authorize checks the policy for the identity provider's action on the user
+func (s *scimService) authorize(ctx context.Context, action string, userID uint) error {
+	err := s.authorizationService.AuthorizeSCIM(ctx, action, userID)
+	var denied *policy.DeniedError
+	switch {
+	case errors.As(err, &denied):
+		return scimError(http.StatusForbidden, "", denied.Error())
+	case errors.Is(err, ErrUserNotFound):
+		return scimError(http.StatusNotFound, "", err.Error())
+	}
+	return err
+}
+
+// user gets a user of the organization ctx selects by ID
+func (s *scimService) user(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.userRepo.WithContext(ctx).GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, scimError(http.StatusNotFound, "", ErrUserNotFound.Error())
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// parseSCIMFilter parses a filter on userName or emails into the email match
+// operator and value. An empty filter matches every user.
+func parseSCIMFilter(filter string) (string, string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+
+	invalid := scimError(http.StatusBadRequest, scimInvalidFilter,
+		`filter must have the form: userName|emails eq|co|sw "value"`)
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", invalid
+	}
+
+	switch strings.ToLower(match[1]) {
+	case "username", "emails", "emails.value":
+	default:
+		return "", "", invalid
+	}
+
+	operator := strings.ToLower(match[2])
+	switch operator {
+	case "eq", "co", "sw":
+	default:
+		return "", "", invalid
+	}
+
+	var value string
+	if err := json.Unmarshal([]byte(match[3]), &value); err != nil {
+		return "", "", invalid
+	}
+	return operator, value, nil
+}
+
+// patchSCIMUser applies one PATCH operation to the resource. Operations
+// without a path carry an object of attributes to set.
+func patchSCIMUser(resource *models.SCIMUser, operation models.SCIMPatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	switch op {
+	case "add", "replace":
+	case "remove":
+		if operation.Path == "" {
+			return scimError(http.StatusBadRequest, scimNoTarget, "remove operations require a path")
+		}
+	default:
+		return scimError(http.StatusBadRequest, scimInvalidSyntax, fmt.Sprintf("unsupported operation %q", operation.Op))
+	}
+
+	if operation.Path == "" {
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+			return scimError(http.StatusBadRequest, scimInvalidValue, "operations without a path require an object value")
+		}
+		for path, value := range attributes {
+			if err := patchSCIMAttribute(resource, op, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return patchSCIMAttribute(resource, op, operation.Path, operation.Value)
+}
+
+// patchSCIMAttribute sets or removes one attribute of the resource
+func patchSCIMAttribute(resource *models.SCIMUser, op, path string, value json.RawMessage) error {
+	if resource.Name == nil {
+		resource.Name = &models.SCIMName{}
+	}
+
+	// Removing clears the attribute
+	var target *string
+	switch strings.ToLower(path) {
+	case "username":
+		target = &resource.UserName
+	case "displayname":
+		target = &resource.DisplayName
+	case "externalid":
+		target = &resource.ExternalID
+	case "password":
+		target = &resource.Password
+	case "name.formatted":
+		target = &resource.Name.Formatted
+	case "name.givenname":
+		target = &resource.Name.GivenName
+	case "name.familyname":
+		target = &resource.Name.FamilyName
+	case "name":
+		if op == "remove" {
+			resource.Name = &models.SCIMName{}
+			resource.DisplayName = ""
+			return nil
+		}
+		var name models.SCIMName
+		if err := json.Unmarshal(value, &name); err != nil {
+			return scimError(http.StatusBadRequest, scimInvalidValue, "name must be an object")
+		}
+		// Derive the name from the new parts instead of the old formatted name
+		resource.Name = &name
+		resource.DisplayName = ""
+		return nil
+	case "active":
+		if op == "remove" {
+			return scimError(http.StatusBadRequest, scimMutability, "active cannot be removed")
+		}
+		active, err := parseSCIMBool(value)
+		if err != nil {
+			return err
+		}
+		resource.Active = &active
+		return nil
+	case "emails":
+		if op == "remove" {
+			return scimError(http.StatusBadRequest, scimMutability, "emails cannot be removed")
+		}
+		var emails []models.SCIMEmail
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return scimError(http.StatusBadRequest, scimInvalidValue, "emails must be a list of email objects")
+		}
+		// The userName is the user's email address, so it follows the primary email
+		if email := primarySCIMEmail(emails); email != "" {
+			resource.UserName = email
+		}
+		resource.Emails = emails
+		return nil
+	default:
+		if !scimEmailValuePath.MatchString(path) {
+			return scimError(http.StatusBadRequest, scimInvalidPath, fmt.Sprintf("unsupported path %q", path))
+		}
+		if op == "remove" {
+			return scimError(http.StatusBadRequest, scimMutability, "emails cannot be removed")
+		}
+		target = &resource.UserName
+	}
+
+	if op == "remove" {
+		if target == &resource.UserName || target == &resource.Password {
+			return scimError(http.StatusBadRequest, scimMutability, fmt.Sprintf("%s cannot be removed", path))
+		}
+		*target = ""
+		return nil
+	}
+	if err := json.Unmarshal(value, target); err != nil {
+		return scimError(http.StatusBadRequest, scimInvalidValue, fmt.Sprintf("%s must be a string", path))
+	}
+	// Name parts replace the derived display name
+	if strings.HasPrefix(strings.ToLower(path), "name.") {
+		resource.DisplayName = ""
+		if !strings.EqualFold(path, "name.formatted") {
+			resource.Name.Formatted = ""
+		}
+	}
+	return nil
+}
+
+// parseSCIMBool parses a boolean value. Some identity providers send "True" and "False" strings.
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, scimError(http.StatusBadRequest, scimInvalidValue, "active must be a boolean")
+}
+
+// primarySCIMEmail returns the primary email address, or the first one when none is primary
+func primarySCIMEmail(emails []models.SCIMEmail) string {
+	for _, email := range emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+	return ""
+}
+
+// scimList wraps resources in a list response holding all of them
+func scimList(resources interface{}, total int) models.SCIMListResponse {
+	return models.SCIMListResponse{
+		Schemas:      []string{models.SCIMMessageListResponse},
+		TotalResults: int64(total),
+		StartIndex:   1,
+		ItemsPerPage: total,
+		Resources:    resources,
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/policy"
+	"golang-starter-kit/internal/tenant"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		operator string
+		value    string
+	}{
+		{``, "", ""},
+		{`   `, "", ""},
+		{`userName eq "jane@example.com"`, "eq", "jane@example.com"},
+		{`USERNAME EQ "jane@example.com"`, "eq", "jane@example.com"},
+		{`emails co "example.com"`, "co", "example.com"},
+		{`emails.value sw "jane"`, "sw", "jane"},
+		{` userName eq "jane" `, "eq", "jane"},
+		{`userName eq "say \"hi\"\\"`, "eq", `say "hi"\`},
+		{`userName eq "jäne"`, "eq", "jäne"},
+	}
+
+	for _, tt := range tests {
+		operator, value, err := parseSCIMFilter(tt.filter)
+		if err != nil {
+			t.Errorf("parseSCIMFilter(%s) returned error: %v", tt.filter, err)
+			continue
+		}
+		if operator != tt.operator || value != tt.value {
+			t.Errorf("parseSCIMFilter(%s) = %q, %q; want %q, %q", tt.filter, operator, value, tt.operator, tt.value)
+		}
+	}
+}
+
+func TestParseSCIMFilterRejectsUnsupportedFilters(t *testing.T) {
+	filters := []string{
+		`displayName eq "Jane"`,
+		`userName ne "jane@example.com"`,
+		`userName pr`,
+		`userName eq jane@example.com`,
+		`userName eq "jane" and active eq true`,
+		`userName eq "jane" or userName eq "john"`,
+		`userName eq "unterminated`,
+		`userName eq "bad \x escape"`,
+	}
+
+	for _, filter := range filters {
+		_, _, err := parseSCIMFilter(filter)
+		var scimErr *SCIMError
+		if !errors.As(err, &scimErr) || scimErr.Status != http.StatusBadRequest || scimErr.Type != scimInvalidFilter {
+			t.Errorf("parseSCIMFilter(%s) = %v, want an invalidFilter error", filter, err)
+		}
+	}
+}
+
+func TestPatchSCIMUser(t *testing.T) {
+	active := true
+	inactive := false
+
+	tests := []struct {
+		name      string
+		operation string
+		want      models.SCIMUser
+	}{
+		{
+			name:      "replace active",
+			operation: `{"op": "replace", "path": "active", "value": false}`,
+			want:      models.SCIMUser{UserName: "jane@example.com", Name: &models.SCIMName{Formatted: "Jane Doe"}, DisplayName: "Jane Doe", Active: &inactive},
+		},
+		{
+			name:      "Azure AD style capitalized op and string boolean",
+			operation: `{"op": "Replace", "path": "active", "value": "False"}`,
+			want:      models.SCIMUser{UserName: "jane@example.com", Name: &models.SCIMName{Formatted: "Jane Doe"}, DisplayName: "Jane Doe", Active: &inactive},
+		},
+		{
+			name:      "attributes without a path",
+			operation: `{"op": "replace", "value": {"userName": "jane.doe@example.com", "externalId": "00u1"}}`,
+			want:      models.SCIMUser{UserName: "jane.doe@example.com", ExternalID: "00u1", Name: &models.SCIMName{Formatted: "Jane Doe"}, DisplayName: "Jane Doe", Active: &active},
+		},
+		{
+			name:      "name part replaces the derived names",
+			operation: `{"op": "replace", "path": "name.givenName", "value": "Janet"}`,
+			want:      models.SCIMUser{UserName: "jane@example.com", Name: &models.SCIMName{GivenName: "Janet"}, Active: &active},
+		},
+		{
+			name:      "name object",
+			operation: `{"op": "replace", "path": "name", "value": {"givenName": "Janet", "familyName": "Doe"}}`,
+			want:      models.SCIMUser{UserName: "jane@example.com", Name: &models.SCIMName{GivenName: "Janet", FamilyName: "Doe"}, Active: &active},
+		},
+		{
+			name:      "remove the display name",
+			operation: `{"op": "remove", "path": "displayName"}`,
+			want:      models.SCIMUser{UserName: "jane@example.com", Name: &models.SCIMName{Formatted: "Jane Doe"}, Active: &active},
+		},
+		{
+			name:      "emails follow the primary address",
+			operation: `{"op": "replace", "path": "emails", "value": [{"value": "jd@example.com"}, {"value": "jane.doe@example.com", "primary": true}]}`,
+			want: models.SCIMUser{
+				UserName:    "jane.doe@example.com",
+				Name:        &models.SCIMName{Formatted: "Jane Doe"},
+				DisplayName: "Jane Doe",
+				Emails:      []models.SCIMEmail{{Value: "jd@example.com"}, {Value: "jane.doe@example.com", Primary: true}},
+				Active:      &active,
+			},
+		},
+		{
+			name:      "email value path",
+			operation: `{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "jane.doe@example.com"}`,
+			want:      models.SCIMUser{UserName: "jane.doe@example.com", Name: &models.SCIMName{Formatted: "Jane Doe"}, DisplayName: "Jane Doe", Active: &active},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := models.SCIMUser{UserName: "jane@example.com", Name: &models.SCIMName{Formatted: "Jane Doe"}, DisplayName: "Jane Doe", Active: &active}
+			var operation models.SCIMPatchOperation
+			if err := json.Unmarshal([]byte(tt.operation), &operation); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := patchSCIMUser(&resource, operation); err != nil {
+				t.Fatalf("patchSCIMUser returned error: %v", err)
+			}
+			if !reflect.DeepEqual(resource, tt.want) {
+				t.Errorf("patchSCIMUser = %+v, want %+v", resource, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchSCIMUserRejectsInvalidOperations(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		scimType  string
+	}{
+		{"unknown op", `{"op": "move", "path": "active", "value": true}`, scimInvalidSyntax},
+		{"remove without a path", `{"op": "remove"}`, scimNoTarget},
+		{"no path and no object", `{"op": "replace", "value": "jane"}`, scimInvalidValue},
+		{"unknown path", `{"op": "replace", "path": "title", "value": "CEO"}`, scimInvalidPath},
+		{"remove userName", `{"op": "remove", "path": "userName"}`, scimMutability},
+		{"remove password", `{"op": "remove", "path": "password"}`, scimMutability},
+		{"remove active", `{"op": "remove", "path": "active"}`, scimMutability},
+		{"remove emails", `{"op": "remove", "path": "emails"}`, scimMutability},
+		{"remove email value", `{"op": "remove", "path": "emails[type eq \"work\"].value"}`, scimMutability},
+		{"non-boolean active", `{"op": "replace", "path": "active", "value": "maybe"}`, scimInvalidValue},
+		{"non-string userName", `{"op": "replace", "path": "userName", "value": 42}`, scimInvalidValue},
+		{"emails not a list", `{"op": "replace", "path": "emails", "value": "jane@example.com"}`, scimInvalidValue},
+		{"invalid attribute among others", `{"op": "replace", "value": {"displayName": "Jane", "title": "CEO"}}`, scimInvalidPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := models.SCIMUser{UserName: "jane@example.com"}
+			var operation models.SCIMPatchOperation
+			if err := json.Unmarshal([]byte(tt.operation), &operation); err != nil {
+				t.Fatal(err)
+			}
+
+			err := patchSCIMUser(&resource, operation)
+			var scimErr *SCIMError
+			if !errors.As(err, &scimErr) || scimErr.Status != http.StatusBadRequest || scimErr.Type != tt.scimType {
+				t.Errorf("patchSCIMUser = %v, want a %s error", err, tt.scimType)
+			}
+		})
+	}
+}
+
+// newSCIMTest provisions organization 1, which holds Jane and Ada, who has a role.
+// John belongs to organization 2.
+func newSCIMTest(t *testing.T) (SCIMService, *fakeUserRepo, context.Context) {
+	t.Helper()
+
+	users := newTenantUsers()
+	_ = users.Create(&models.User{Name: "Ada", Email: "ada@example.com", Roles: []models.Role{{Name: "support"}}})
+	users.organizations[3] = []uint{1}
+
+	rules, err := policy.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorization := NewAuthorizationService(rules, users, &fakeRoleRepo{users: users}, &fakeOrganizationRepo{users: users})
+	userService := NewUserService(users, nil, nil, nil, nil, nil, fakeHasher{}, config.AuthConfig{})
+	s := NewSCIMService(userService, users, fakeTokenService{}, nil, authorization, fakeHasher{}, config.AppConfig{URL: "https://app.example.com"})
+	return s, users, tenant.WithOrganization(context.Background(), 1)
+}
+
+// scimStatus returns the HTTP status a SCIM error is reported with
+func scimStatus(err error) int {
+	var scimErr *SCIMError
+	if errors.As(err, &scimErr) {
+		return scimErr.Status
+	}
+	return 0
+}
+
+func TestSCIMServiceScopesToOrganization(t *testing.T) {
+	s, users, ctx := newSCIMTest(t)
+
+	// John belongs to another organization
+	if _, err := s.GetUser(ctx, 2); scimStatus(err) != http.StatusNotFound {
+		t.Errorf("GetUser = %v, want 404", err)
+	}
+	if _, err := s.ReplaceUser(ctx, 2, models.SCIMUser{UserName: "john@example.com", DisplayName: "Mallory"}); scimStatus(err) != http.StatusNotFound {
+		t.Errorf("ReplaceUser = %v, want 404", err)
+	}
+	if _, err := s.PatchUser(ctx, 2, models.SCIMPatchRequest{}); scimStatus(err) != http.StatusNotFound {
+		t.Errorf("PatchUser = %v, want 404", err)
+	}
+	if err := s.DeleteUser(ctx, 2); scimStatus(err) != http.StatusNotFound {
+		t.Errorf("DeleteUser = %v, want 404", err)
+	}
+	if john, err := users.GetByID(2); err != nil || john.Name != "John" {
+		t.Fatalf("John was changed or deleted by another organization's identity provider: %+v, %v", john, err)
+	}
+
+	// Members without roles are provisioned
+	if user, err := s.ReplaceUser(ctx, 1, models.SCIMUser{UserName: "jane@example.com", DisplayName: "Jane Doe"}); err != nil || user.DisplayName != "Jane Doe" {
+		t.Fatalf("ReplaceUser = %+v, %v; want Jane renamed", user, err)
+	}
+	if err := s.DeleteUser(ctx, 1); err != nil {
+		t.Fatalf("DeleteUser = %v, want Jane deleted", err)
+	}
+}
+
+func TestSCIMServiceRefusesRoleHolders(t *testing.T) {
+	s, users, ctx := newSCIMTest(t)
+	inactive := false
+
+	if _, err := s.ReplaceUser(ctx, 3, models.SCIMUser{UserName: "ada@example.com", Active: &inactive}); scimStatus(err) != http.StatusForbidden {
+		t.Errorf("ReplaceUser = %v, want 403", err)
+	}
+	patch := models.SCIMPatchRequest{Operations: []models.SCIMPatchOperation{{Op: "replace", Path: "userName", Value: json.RawMessage(`"mallory@example.com"`)}}}
+	if _, err := s.PatchUser(ctx, 3, patch); scimStatus(err) != http.StatusForbidden {
+		t.Errorf("PatchUser = %v, want 403", err)
+	}
+	if err := s.DeleteUser(ctx, 3); scimStatus(err) != http.StatusForbidden {
+		t.Errorf("DeleteUser = %v, want 403", err)
+	}
+
+	ada, err := users.GetByID(3)
+	if err != nil || ada.IsDeactivated() || ada.Email != "ada@example.com" {
+		t.Errorf("the role holder was changed through SCIM: %+v, %v", ada, err)
+	}
+}
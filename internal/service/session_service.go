@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ErrSessionNotFound is returned when a session does not exist or belongs to another user
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionService interface defines session management methods
+type SessionService interface {
+	ListSessions(ctx context.Context, userID, currentSessionID uint) ([]models.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID uint) error
+}
+
+// sessionService implements SessionService interface
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+	refreshRepo repository.RefreshTokenRepository
+	userRepo    repository.UserRepository
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(
+	sessionRepo repository.SessionRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	userRepo repository.UserRepository,
+) SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		refreshRepo: refreshRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// ListSessions lists the user's active sessions. currentSessionID marks the
+// session of the request, if any. Within an organization the user must be a member of it.
+func (s *sessionService) ListSessions(ctx context.Context, userID, currentSessionID uint) ([]models.SessionResponse, error) {
+	if _, err := s.userRepo.WithContext(ctx).GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.WithContext(ctx).GetActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, sessions[i].ToResponse(currentSessionID))
+	}
+	return responses, nil
+}
+
+// RevokeSession signs a device out: the session's refresh tokens are revoked
+// and its access tokens are rejected from then on. Within an organization the
+// user must be a member of it.
+func (s *sessionService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	if _, err := s.userRepo.WithContext(ctx).GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	sessionRepo := s.sessionRepo.WithContext(ctx)
+	session, err := sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	if err := sessionRepo.Revoke(session.ID); err != nil {
+		return err
+	}
+	return s.refreshRepo.RevokeFamily(session.FamilyID)
+}
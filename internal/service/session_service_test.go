@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/tenant"
+)
+
+func TestSessionServiceScopesToOrganization(t *testing.T) {
+	users := newTenantUsers()
+	sessions := &fakeSessionRepo{sessions: []*models.Session{
+		{ID: 1, UserID: 1, FamilyID: "jane"},
+		{ID: 2, UserID: 2, FamilyID: "john"},
+	}}
+	refreshTokens := &fakeRefreshRepo{}
+	s := NewSessionService(sessions, refreshTokens, users)
+	acme := tenant.WithOrganization(context.Background(), 1)
+
+	if _, err := s.ListSessions(acme, 2, 0); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("ListSessions of another organization's user = %v, want ErrUserNotFound", err)
+	}
+	if err := s.RevokeSession(acme, 2, 2); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("RevokeSession of another organization's user = %v, want ErrUserNotFound", err)
+	}
+	// A member's ID does not reach another user's session
+	if err := s.RevokeSession(acme, 1, 2); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("RevokeSession of another user's session = %v, want ErrSessionNotFound", err)
+	}
+	if sessions.sessions[1].RevokedAt != nil || len(refreshTokens.revokedFamilies) != 0 {
+		t.Fatal("a session of another organization was revoked")
+	}
+
+	list, err := s.ListSessions(acme, 1, 1)
+	if err != nil || len(list) != 1 || !list[0].Current {
+		t.Fatalf("ListSessions = %+v, %v; want Jane's current session", list, err)
+	}
+	if err := s.RevokeSession(acme, 1, 1); err != nil {
+		t.Fatalf("RevokeSession = %v", err)
+	}
+	if sessions.sessions[0].RevokedAt == nil || len(refreshTokens.revokedFamilies) != 1 || refreshTokens.revokedFamilies[0] != "jane" {
+		t.Error("Jane's session and its refresh tokens were not revoked")
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/internal/social"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownSocialProvider is returned when no social login provider has the requested name
+	ErrUnknownSocialProvider = social.ErrUnknownProvider
+	// ErrInvalidSocialLoginState is returned when the callback state is unknown, expired or already used
+	ErrInvalidSocialLoginState = errors.New("invalid or expired social login state")
+	// ErrSocialEmailNotVerified is returned when an unlinked provider account has no verified email
+	ErrSocialEmailNotVerified = errors.New("the provider did not report a verified email address")
+	// ErrSocialLoginFailed is returned when the provider rejects the code or returns an invalid response
+	ErrSocialLoginFailed = errors.New("social login failed")
+	// ErrSocialDirectoryAccount is returned when the email of an unlinked account belongs to a directory user
+	ErrSocialDirectoryAccount = errors.New("accounts managed by the directory service cannot sign in with a social login")
+)
+
+// SocialLoginService interface defines social (OAuth2 / OpenID Connect) login methods
+type SocialLoginService interface {
+	Providers() []string
+	AuthCodeURL(provider string) (authURL string, state string, err error)
+	Callback(provider, code, state string, client models.ClientInfo) (*models.LoginResponse, error)
+	PurgeExpired() error
+}
+
+// socialLoginService implements SocialLoginService interface
+type socialLoginService struct {
+	registry         *social.Registry
+	socialRepo       repository.SocialLoginRepository
+	userRepo         repository.UserRepository
+	tokenService     TokenService
+	twoFactorService TwoFactorService
+	loginHistory     LoginHistoryService
+	hasher           utils.PasswordHasher
+	socialCfg        config.SocialConfig
+}
+
+// NewSocialLoginService creates a new social login service
+func NewSocialLoginService(
+	registry *social.Registry,
+	socialRepo repository.SocialLoginRepository,
+	userRepo repository.UserRepository,
+	tokenService TokenService,
+	twoFactorService TwoFactorService,
+	loginHistory LoginHistoryService,
+	hasher utils.PasswordHasher,
+	socialCfg config.SocialConfig,
+) SocialLoginService {
+	return &socialLoginService{
+		registry:         registry,
+		socialRepo:       socialRepo,
+		userRepo:         userRepo,
+		tokenService:     tokenService,
+		twoFactorService: twoFactorService,
+		loginHistory:     loginHistory,
+		hasher:           hasher,
+		socialCfg:        socialCfg,
+	}
+}
+
+// Providers returns the names of the enabled providers
+func (s *socialLoginService) Providers() []string {
+	return s.registry.Names()
+}
+
+// AuthCodeURL starts a login with the provider. It stores the PKCE verifier and
+// nonce under a random state and returns the provider URL and that state.
+func (s *socialLoginService) AuthCodeURL(providerName string) (string, string, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), social.AuthRequest{
+		State:         state,
+		CodeChallenge: utils.CodeChallengeS256(verifier),
+		Nonce:         nonce,
+	})
+	if err != nil {
+		log.Printf("social login with %s failed: %v", providerName, err)
+		return "", "", ErrSocialLoginFailed
+	}
+
+	if err := s.socialRepo.CreateState(&models.SocialLoginState{
+		Provider:     providerName,
+		StateHash:    utils.HashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.socialCfg.StateTTL),
+	}); err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// Callback completes a login: it consumes the state, redeems the code with the
+// provider and signs in the linked user, linking or creating one when the
+// provider reports a verified email address.
+func (s *socialLoginService) Callback(providerName, code, state string, client models.ClientInfo) (*models.LoginResponse, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.socialRepo.GetStateByHash(utils.HashToken(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidSocialLoginState
+		}
+		return nil, err
+	}
+
+	// Each state can be used once
+	deleted, err := s.socialRepo.DeleteState(pending.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted || pending.Provider != providerName || time.Now().After(pending.ExpiresAt) {
+		return nil, ErrInvalidSocialLoginState
+	}
+
+	identity, err := provider.Exchange(context.Background(), code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		log.Printf("social login with %s failed: %v", providerName, err)
+		return nil, ErrSocialLoginFailed
+	}
+
+	user, err := s.resolveUser(providerName, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsLocked(time.Now()) {
+		err := &AccountLockedError{Until: *user.LockedUntil}
+		s.loginHistory.Record(models.LoginMethodSocial, user.Email, user, client, err)
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		s.loginHistory.Record(models.LoginMethodSocial, user.Email, user, client, ErrAccountDeactivated)
+		return nil, ErrAccountDeactivated
+	}
+
+	var response *models.LoginResponse
+	if user.IsTwoFactorEnabled() {
+		response, err = s.twoFactorService.CreateChallenge(user)
+	} else {
+		response, err = s.tokenService.IssueTokens(user, client)
+	}
+	s.loginHistory.Record(models.LoginMethodSocial, user.Email, user, client, loginOutcome(response, err))
+	return response, err
+}
+
+// PurgeExpired removes pending social logins that were never completed
+func (s *socialLoginService) PurgeExpired() error {
+	return s.socialRepo.DeleteExpiredStates()
+}
+
+// resolveUser finds the user linked to the external account. Unlinked accounts
+// are linked by email, or get a new user, only when the email is verified.
+// Directory users are never linked, since the directory decides who may sign in as them.
+func (s *socialLoginService) resolveUser(providerName string, identity *social.Identity) (*models.User, error) {
+	linked, err := s.socialRepo.GetIdentity(providerName, identity.Subject)
+	if err == nil {
+		user, err := s.userRepo.GetByID(linked.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrSocialEmailNotVerified
+	}
+	identity.Email = models.NormalizeEmail(identity.Email)
+
+	link := &models.UserIdentity{
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+
+	user, err := s.userRepo.GetByEmail(identity.Email)
+	if err == nil {
+		if !user.IsLocal() {
+			return nil, ErrSocialDirectoryAccount
+		}
+		link.UserID = user.ID
+		if err := s.socialRepo.CreateIdentity(link); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// New users get an unusable random password; they can set one through the password reset flow
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.hasher.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	verifiedAt := time.Now()
+	user = &models.User{
+		Name:              name,
+		Email:             identity.Email,
+		Password:          hashedPassword,
+		EmailVerifiedAt:   &verifiedAt,
+		PasswordChangedAt: &verifiedAt,
+	}
+	if err := s.socialRepo.CreateUserWithIdentity(user, link); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/social"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeSocialRepo keeps pending logins and linked identities in memory
+type fakeSocialRepo struct {
+	users      *fakeUserRepo
+	states     []*models.SocialLoginState
+	identities []*models.UserIdentity
+}
+
+func (r *fakeSocialRepo) CreateState(state *models.SocialLoginState) error {
+	state.ID = uint(len(r.states) + 1)
+	r.states = append(r.states, state)
+	return nil
+}
+
+func (r *fakeSocialRepo) GetStateByHash(hash string) (*models.SocialLoginState, error) {
+	for _, state := range r.states {
+		if state != nil && state.StateHash == hash {
+			return state, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSocialRepo) DeleteState(id uint) (bool, error) {
+	for i, state := range r.states {
+		if state != nil && state.ID == id {
+			r.states[i] = nil
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeSocialRepo) DeleteExpiredStates() error {
+	return nil
+}
+
+func (r *fakeSocialRepo) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSocialRepo) CreateIdentity(identity *models.UserIdentity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeSocialRepo) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	if err := r.users.Create(user); err != nil {
+		return err
+	}
+	identity.UserID = user.ID
+	return r.CreateIdentity(identity)
+}
+
+// fakeProvider is an identity provider that enforces PKCE and the nonce the way a real one does
+type fakeProvider struct {
+	identity  social.Identity
+	challenge string
+	nonce     string
+}
+
+func (p *fakeProvider) Name() string {
+	return "test"
+}
+
+func (p *fakeProvider) AuthCodeURL(ctx context.Context, req social.AuthRequest) (string, error) {
+	p.challenge = req.CodeChallenge
+	p.nonce = req.Nonce
+	return "https://idp.example.com/authorize?state=" + req.State, nil
+}
+
+func (p *fakeProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*social.Identity, error) {
+	if code != "code" || utils.CodeChallengeS256(codeVerifier) != p.challenge {
+		return nil, errors.New("invalid_grant")
+	}
+	if nonce != p.nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	identity := p.identity
+	return &identity, nil
+}
+
+// fakeLoginHistory remembers the outcome of every recorded login
+type fakeLoginHistory struct {
+	LoginHistoryService
+	outcomes []error
+}
+
+func (h *fakeLoginHistory) Record(method, email string, user *models.User, client models.ClientInfo, loginErr error) {
+	h.outcomes = append(h.outcomes, loginErr)
+}
+
+type socialLoginTest struct {
+	service  SocialLoginService
+	provider *fakeProvider
+	users    *fakeUserRepo
+	repo     *fakeSocialRepo
+}
+
+func newSocialLoginTest(identity social.Identity) *socialLoginTest {
+	users := &fakeUserRepo{}
+	repo := &fakeSocialRepo{users: users}
+	provider := &fakeProvider{identity: identity}
+
+	registry, _ := social.NewRegistry(config.SocialConfig{}, nil)
+	registry.Register(provider)
+
+	return &socialLoginTest{
+		service: NewSocialLoginService(registry, repo, users, fakeTokenService{}, nil,
+			&fakeLoginHistory{}, fakeHasher{}, config.SocialConfig{StateTTL: time.Minute}),
+		provider: provider,
+		users:    users,
+		repo:     repo,
+	}
+}
+
+// login starts a login and completes it with the state it returned
+func (tt *socialLoginTest) login(t *testing.T) (*models.LoginResponse, error) {
+	t.Helper()
+
+	_, state, err := tt.service.AuthCodeURL("test")
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned error: %v", err)
+	}
+	return tt.service.Callback("test", "code", state, models.ClientInfo{})
+}
+
+func TestSocialLoginAuthCodeURLBindsPKCEAndNonce(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{})
+
+	authURL, state, err := tt.service.AuthCodeURL("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authURL != "https://idp.example.com/authorize?state="+state {
+		t.Errorf("AuthCodeURL = %s, want the provider URL for state %s", authURL, state)
+	}
+
+	// Only the hash of the state is stored, next to the verifier of the challenge sent out
+	pending, err := tt.repo.GetStateByHash(utils.HashToken(state))
+	if err != nil {
+		t.Fatal("the state was not stored by its hash")
+	}
+	if utils.CodeChallengeS256(pending.CodeVerifier) != tt.provider.challenge {
+		t.Error("the stored code verifier does not match the challenge sent to the provider")
+	}
+	if pending.Nonce == "" || pending.Nonce != tt.provider.nonce {
+		t.Errorf("stored nonce %q, sent %q", pending.Nonce, tt.provider.nonce)
+	}
+}
+
+func TestSocialLoginCallbackState(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{Subject: "1", Email: "jane@example.com", EmailVerified: true})
+
+	_, state, err := tt.service.AuthCodeURL("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tt.service.Callback("test", "code", "forged state", models.ClientInfo{}); !errors.Is(err, ErrInvalidSocialLoginState) {
+		t.Errorf("Callback with an unknown state = %v, want ErrInvalidSocialLoginState", err)
+	}
+	if _, err := tt.service.Callback("test", "code", state, models.ClientInfo{}); err != nil {
+		t.Fatalf("Callback returned error: %v", err)
+	}
+	if _, err := tt.service.Callback("test", "code", state, models.ClientInfo{}); !errors.Is(err, ErrInvalidSocialLoginState) {
+		t.Errorf("Callback reusing a state = %v, want ErrInvalidSocialLoginState", err)
+	}
+}
+
+func TestSocialLoginCallbackRejectsExpiredState(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{Subject: "1", Email: "jane@example.com", EmailVerified: true})
+
+	_, state, err := tt.service.AuthCodeURL("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt.repo.states[0].ExpiresAt = time.Now().Add(-time.Second)
+
+	if _, err := tt.service.Callback("test", "code", state, models.ClientInfo{}); !errors.Is(err, ErrInvalidSocialLoginState) {
+		t.Errorf("Callback with an expired state = %v, want ErrInvalidSocialLoginState", err)
+	}
+}
+
+func TestSocialLoginCallbackFailsOnNonceMismatch(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{Subject: "1", Email: "jane@example.com", EmailVerified: true})
+
+	_, state, err := tt.service.AuthCodeURL("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The ID token is bound to another login's nonce
+	tt.provider.nonce = "nonce of another login"
+
+	if _, err := tt.service.Callback("test", "code", state, models.ClientInfo{}); !errors.Is(err, ErrSocialLoginFailed) {
+		t.Errorf("Callback with a mismatched nonce = %v, want ErrSocialLoginFailed", err)
+	}
+	if len(tt.users.users) != 0 {
+		t.Error("a user was created for a failed login")
+	}
+}
+
+func TestSocialLoginCreatesUserForVerifiedEmail(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{Subject: "1", Email: "jane@example.com", EmailVerified: true, Name: "Jane Doe"})
+
+	response, err := tt.login(t)
+	if err != nil {
+		t.Fatalf("Callback returned error: %v", err)
+	}
+	if response.Token != "token for jane@example.com" {
+		t.Errorf("Callback signed in %q, want the new user", response.Token)
+	}
+
+	user, err := tt.users.GetByEmail("jane@example.com")
+	if err != nil {
+		t.Fatal("no user was created")
+	}
+	if user.Name != "Jane Doe" || !user.IsEmailVerified() {
+		t.Errorf("created user %+v, want the provider name and a verified email", user)
+	}
+	if identity, err := tt.repo.GetIdentity("test", "1"); err != nil || identity.UserID != user.ID {
+		t.Error("the provider account was not linked to the new user")
+	}
+}
+
+func TestSocialLoginLinksExistingUser(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{Subject: "1", Email: "JANE@example.com", EmailVerified: true})
+	existing := &models.User{Name: "Jane", Email: "jane@example.com"}
+	_ = tt.users.Create(existing)
+
+	response, err := tt.login(t)
+	if err != nil {
+		t.Fatalf("Callback returned error: %v", err)
+	}
+	if response.Token != "token for jane@example.com" {
+		t.Errorf("Callback signed in %q, want the existing user", response.Token)
+	}
+	if len(tt.users.users) != 1 {
+		t.Errorf("%d users exist, want only the existing one", len(tt.users.users))
+	}
+	if identity, err := tt.repo.GetIdentity("test", "1"); err != nil || identity.UserID != existing.ID {
+		t.Error("the provider account was not linked to the existing user")
+	}
+
+	// Once linked, the account signs in the same user even after its email changes
+	tt.provider.identity.Email = "jane.doe@example.com"
+	tt.provider.identity.EmailVerified = false
+	if response, err := tt.login(t); err != nil || response.Token != "token for jane@example.com" {
+		t.Errorf("Callback for the linked account = %v, %v; want the existing user", response, err)
+	}
+}
+
+func TestSocialLoginNormalizesNewUserEmail(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{Subject: "1", Email: "Jane@Example.com", EmailVerified: true})
+
+	if _, err := tt.login(t); err != nil {
+		t.Fatalf("Callback returned error: %v", err)
+	}
+	if user := tt.users.users[0]; user.Email != "jane@example.com" {
+		t.Errorf("created user with email %q, want it lower-cased", user.Email)
+	}
+	if identity := tt.repo.identities[0]; identity.Email != "jane@example.com" {
+		t.Errorf("linked identity with email %q, want it lower-cased", identity.Email)
+	}
+}
+
+func TestSocialLoginRefusesUnverifiedEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity social.Identity
+	}{
+		{"unverified", social.Identity{Subject: "1", Email: "jane@example.com"}},
+		{"no email", social.Identity{Subject: "1", EmailVerified: true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newSocialLoginTest(tc.identity)
+			_ = tt.users.Create(&models.User{Name: "Jane", Email: "jane@example.com"})
+
+			if _, err := tt.login(t); !errors.Is(err, ErrSocialEmailNotVerified) {
+				t.Fatalf("Callback = %v, want ErrSocialEmailNotVerified", err)
+			}
+			if len(tt.repo.identities) != 0 {
+				t.Error("an account with an unverified email was linked")
+			}
+			if len(tt.users.users) != 1 {
+				t.Error("a user was created for an unverified email")
+			}
+		})
+	}
+}
+
+func TestSocialLoginRefusesToLinkDirectoryUsers(t *testing.T) {
+	tt := newSocialLoginTest(social.Identity{Subject: "1", Email: "jane@example.com", EmailVerified: true})
+	_ = tt.users.Create(&models.User{Name: "Jane", Email: "jane@example.com", AuthSource: models.AuthSourceLDAP})
+
+	if _, err := tt.login(t); !errors.Is(err, ErrSocialDirectoryAccount) {
+		t.Fatalf("Callback = %v, want ErrSocialDirectoryAccount", err)
+	}
+	if len(tt.repo.identities) != 0 {
+		t.Error("the provider account was linked to a directory user")
+	}
+	if len(tt.users.users) != 1 {
+		t.Error("a user was created next to the directory user")
+	}
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, please login again")
+	// ErrTokenRevoked is returned when a revoked access token is presented
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrSessionRevoked is returned when an access token of a revoked session is presented
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
+// TokenService interface defines token issuing methods
+type TokenService interface {
+	IssueTokens(user *models.User, client models.ClientInfo) (*models.LoginResponse, error)
+	Refresh(refreshToken string, client models.ClientInfo) (*models.LoginResponse, error)
+	ValidateAccessToken(token string) (*utils.JWTClaims, error)
+	Reauthenticate(claims *utils.JWTClaims, user *models.User) (*models.LoginResponse, error)
+	Logout(claims *utils.JWTClaims, refreshToken string) error
+	LogoutAll(userID uint) error
+	RevokeAccessTokens(userID uint) error
+	PurgeExpired() error
+}
+
+// tokenService implements TokenService interface
+type tokenService struct {
+	refreshRepo repository.RefreshTokenRepository
+	revokedRepo repository.RevokedTokenRepository
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+	keys        *utils.KeySet
+	cfg         config.JWTConfig
+	authCfg     config.AuthConfig
+}
+
+// NewTokenService creates a new token service
+func NewTokenService(
+	refreshRepo repository.RefreshTokenRepository,
+	revokedRepo repository.RevokedTokenRepository,
+	sessionRepo repository.SessionRepository,
+	userRepo repository.UserRepository,
+	keys *utils.KeySet,
+	cfg config.JWTConfig,
+	authCfg config.AuthConfig,
+) TokenService {
+	return &tokenService{
+		refreshRepo: refreshRepo,
+		revokedRepo: revokedRepo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		keys:        keys,
+		cfg:         cfg,
+		authCfg:     authCfg,
+	}
+}
+
+// IssueTokens issues an access token and a refresh token starting a new token
+// family, and records the login as a new session of the client
+func (s *tokenService) IssueTokens(user *models.User, client models.ClientInfo) (*models.LoginResponse, error) {
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session, err := s.createSession(user.ID, familyID, client, &now)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(user, session)
+}
+
+// Refresh rotates a refresh token and issues a new token pair.
+// Presenting a token that was already rotated revokes its whole family.
+func (s *tokenService) Refresh(refreshToken string, client models.ClientInfo) (*models.LoginResponse, error) {
+	stored, err := s.refreshRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// Refresh tokens issued to OAuth clients are redeemed at the OAuth token endpoint
+	if stored.ClientID != "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		if err := s.revokeFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if !stored.IsActive(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	marked, err := s.refreshRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		// Another request rotated this token first
+		if err := s.revokeFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Families issued before sessions were recorded get one on their next refresh
+	session, err := s.sessionRepo.GetByFamilyID(stored.FamilyID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if session, err = s.createSession(user.ID, stored.FamilyID, client, nil); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := s.sessionRepo.Touch(session.ID, client); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.issue(user, session)
+}
+
+// ValidateAccessToken validates an access token and checks that it has not been revoked
+func (s *tokenService) ValidateAccessToken(token string) (*utils.JWTClaims, error) {
+	claims, err := utils.ValidateToken(token, s.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := s.revokedRepo.IsRevoked(claims.ID, claims.UserID, issuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	if claims.SessionID != 0 {
+		if err := s.checkSession(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
+
+// Reauthenticate records that the user of the token's session just entered
+// their credentials again and issues an access token whose auth_time is now.
+// Later refreshes of the session carry the new auth_time as well.
+func (s *tokenService) Reauthenticate(claims *utils.JWTClaims, user *models.User) (*models.LoginResponse, error) {
+	if claims.SessionID == 0 {
+		return nil, ErrSessionRevoked
+	}
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+	if session.UserID != user.ID || session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	now := time.Now()
+	if err := s.sessionRepo.MarkAuthenticated(session.ID, now); err != nil {
+		return nil, err
+	}
+	session.AuthenticatedAt = &now
+
+	accessToken, expiresAt, err := s.generateAccessToken(user, session)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		Token:     accessToken,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+		User:      user.ToResponse(),
+	}, nil
+}
+
+// Logout revokes the presented access token and its session and, when given,
+// the refresh token family it belongs to
+func (s *tokenService) Logout(claims *utils.JWTClaims, refreshToken string) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		revoked := &models.RevokedToken{
+			JTI:       claims.ID,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		}
+		if err := s.revokedRepo.Create(revoked); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != 0 {
+		if err := s.revokeSession(claims.UserID, claims.SessionID); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.refreshRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if stored.UserID != claims.UserID {
+		return nil
+	}
+
+	return s.revokeFamily(stored.FamilyID)
+}
+
+// LogoutAll revokes every session, access and refresh token of the user so far
+func (s *tokenService) LogoutAll(userID uint) error {
+	if err := s.RevokeAccessTokens(userID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+
+	return s.refreshRepo.RevokeAllForUser(userID)
+}
+
+// RevokeAccessTokens revokes every access token issued to the user so far while
+// keeping refresh tokens valid, so clients pick up changed claims on their next refresh
+func (s *tokenService) RevokeAccessTokens(userID uint) error {
+	// Every token issued before now is covered, also earlier within the same
+	// second. The iat claim has millisecond precision, so tokens issued right
+	// after this call (e.g. a replacement pair for the caller) stay valid.
+	now := time.Now()
+	revoked := &models.RevokedToken{
+		UserID:       userID,
+		IssuedBefore: &now,
+		ExpiresAt:    now.Add(s.revocationTTL()),
+	}
+	return s.revokedRepo.Create(revoked)
+}
+
+// revocationTTL is how long a revocation by issue time must be kept: the longest
+// lifetime of the access tokens it covers, which includes impersonation tokens
+func (s *tokenService) revocationTTL() time.Duration {
+	return max(s.cfg.AccessTokenTTL, s.authCfg.ImpersonationTTL)
+}
+
+// PurgeExpired removes refresh tokens, sessions and revocation entries that are no longer needed
+func (s *tokenService) PurgeExpired() error {
+	if err := s.refreshRepo.DeleteExpired(); err != nil {
+		return err
+	}
+
+	// Idle sessions have no refresh token left; revoked ones only matter while their access tokens live
+	now := time.Now()
+	if err := s.sessionRepo.DeleteExpired(now.Add(-s.cfg.RefreshTokenTTL), now.Add(-s.cfg.AccessTokenTTL)); err != nil {
+		return err
+	}
+	return s.revokedRepo.DeleteExpired()
+}
+
+// createSession records a new session for a token family. authenticatedAt is
+// when the user entered credentials, or nil when they did not.
+func (s *tokenService) createSession(userID uint, familyID string, client models.ClientInfo, authenticatedAt *time.Time) (*models.Session, error) {
+	session := &models.Session{
+		UserID:          userID,
+		FamilyID:        familyID,
+		UserAgent:       client.UserAgent,
+		IPAddress:       client.IPAddress,
+		LastSeenAt:      time.Now(),
+		AuthenticatedAt: authenticatedAt,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// checkSession rejects tokens whose session has been revoked and records that the session was used
+func (s *tokenService) checkSession(claims *utils.JWTClaims) error {
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	if session.UserID != claims.UserID || session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+
+	return s.sessionRepo.TouchLastSeen(session.ID)
+}
+
+// revokeSession revokes a session of the user and the refresh tokens issued to it
+func (s *tokenService) revokeSession(userID, sessionID uint) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if session.UserID != userID {
+		return nil
+	}
+
+	return s.revokeFamily(session.FamilyID)
+}
+
+// revokeFamily revokes every refresh token of a family and the session it belongs to
+func (s *tokenService) revokeFamily(familyID string) error {
+	if err := s.sessionRepo.RevokeFamily(familyID); err != nil {
+		return err
+	}
+	return s.refreshRepo.RevokeFamily(familyID)
+}
+
+// issue creates a token pair where the refresh token belongs to the session's family
+func (s *tokenService) issue(user *models.User, session *models.Session) (*models.LoginResponse, error) {
+	accessToken, accessExpiresAt, err := s.generateAccessToken(user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  session.FamilyID,
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+	if err := s.refreshRepo.Create(stored); err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Token:            accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+		User:             user.ToResponse(),
+	}, nil
+}
+
+// generateAccessToken signs an access token for the user's session
+func (s *tokenService) generateAccessToken(user *models.User, session *models.Session) (string, time.Time, error) {
+	claims := utils.JWTClaims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Roles:     user.RoleNames(),
+		SessionID: session.ID,
+	}
+	if session.AuthenticatedAt != nil {
+		claims.AuthTime = jwt.NewNumericDate(*session.AuthenticatedAt)
+	}
+	return utils.GenerateToken(claims, s.keys, s.cfg.AccessTokenTTL)
+}
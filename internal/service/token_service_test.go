@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/utils"
+)
+
+type tokenTest struct {
+	service  TokenService
+	user     *models.User
+	sessions *fakeSessionRepo
+	refresh  *fakeRefreshRepo
+}
+
+// newTokenTest issues tokens for Jane with in-memory repositories
+func newTokenTest() *tokenTest {
+	users := &fakeUserRepo{}
+	user := &models.User{Name: "Jane", Email: "jane@example.com"}
+	_ = users.Create(user)
+
+	sessions := &fakeSessionRepo{}
+	refresh := &fakeRefreshRepo{}
+	cfg := config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour}
+	return &tokenTest{
+		service:  NewTokenService(refresh, &fakeRevokedRepo{}, sessions, users, utils.NewHMACKeySet("secret", "test"), cfg, config.AuthConfig{}),
+		user:     user,
+		sessions: sessions,
+		refresh:  refresh,
+	}
+}
+
+func TestRevokeAccessTokensCoversTheCurrentSecond(t *testing.T) {
+	tt := newTokenTest()
+
+	before, err := tt.service.IssueTokens(tt.user, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Revoked within the same second the token was issued in
+	if err := tt.service.RevokeAccessTokens(tt.user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tt.service.ValidateAccessToken(before.Token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateAccessToken of a token issued before the revocation = %v, want ErrTokenRevoked", err)
+	}
+
+	// A replacement issued right after the revocation stays valid
+	time.Sleep(5 * time.Millisecond)
+	after, err := tt.service.IssueTokens(tt.user, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tt.service.ValidateAccessToken(after.Token); err != nil {
+		t.Errorf("ValidateAccessToken of a token issued after the revocation = %v, want it valid", err)
+	}
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	tt := newTokenTest()
+
+	login, err := tt.service.IssueTokens(tt.user, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := tt.service.Refresh(login.RefreshToken, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == login.RefreshToken {
+		t.Fatal("Refresh did not rotate the refresh token")
+	}
+	if len(tt.refresh.tokens) != 2 || tt.refresh.tokens[0].FamilyID != tt.refresh.tokens[1].FamilyID {
+		t.Error("the rotated refresh token does not belong to the login's family")
+	}
+	if len(tt.sessions.sessions) != 1 {
+		t.Errorf("%d sessions were recorded, want the login's only", len(tt.sessions.sessions))
+	}
+
+	// The new pair works in turn
+	if _, err := tt.service.ValidateAccessToken(refreshed.Token); err != nil {
+		t.Errorf("ValidateAccessToken of the refreshed token = %v", err)
+	}
+	if _, err := tt.service.Refresh(refreshed.RefreshToken, models.ClientInfo{}); err != nil {
+		t.Errorf("Refresh with the rotated token = %v", err)
+	}
+	if _, err := tt.service.Refresh("unknown", models.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh with an unknown token = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	tt := newTokenTest()
+
+	login, err := tt.service.IssueTokens(tt.user, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := tt.service.IssueTokens(tt.user, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := tt.service.Refresh(login.RefreshToken, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A stolen copy of the rotated token is presented again
+	if _, err := tt.service.Refresh(login.RefreshToken, models.ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh reusing a rotated token = %v, want ErrRefreshTokenReused", err)
+	}
+	family := tt.refresh.tokens[0].FamilyID
+	if len(tt.refresh.revokedFamilies) != 1 || tt.refresh.revokedFamilies[0] != family {
+		t.Errorf("revoked families %v, want the reused token's %s", tt.refresh.revokedFamilies, family)
+	}
+
+	// Everything issued from that login stops working
+	if _, err := tt.service.Refresh(refreshed.RefreshToken, models.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh with the family's latest token = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := tt.service.ValidateAccessToken(refreshed.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("ValidateAccessToken of the family's access token = %v, want ErrSessionRevoked", err)
+	}
+
+	// Other logins of the user are left alone
+	if _, err := tt.service.Refresh(other.RefreshToken, models.ClientInfo{}); err != nil {
+		t.Errorf("Refresh of another login = %v, want it to work", err)
+	}
+}
+
+// authTime validates the access token and returns its auth_time, or the zero time without one
+func (tt *tokenTest) authTime(t *testing.T, token string) time.Time {
+	t.Helper()
+
+	claims, err := tt.service.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken returned error: %v", err)
+	}
+	if claims.AuthTime == nil {
+		return time.Time{}
+	}
+	return claims.AuthTime.Time
+}
+
+func TestAuthTimeFollowsTheSession(t *testing.T) {
+	tt := newTokenTest()
+
+	login, err := tt.service.IssueTokens(tt.user, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tt.authTime(t, login.Token); time.Since(got) > time.Minute {
+		t.Fatalf("auth_time of a login = %s, want now", got)
+	}
+
+	// Refreshing does not prove the user's identity again
+	hourAgo := time.Now().Add(-time.Hour).Truncate(time.Second)
+	tt.sessions.sessions[0].AuthenticatedAt = &hourAgo
+	refreshed, err := tt.service.Refresh(login.RefreshToken, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tt.authTime(t, refreshed.Token); !got.Equal(hourAgo) {
+		t.Fatalf("auth_time after a refresh = %s, want the session's %s", got, hourAgo)
+	}
+
+	// Re-authenticating moves it forward for the session's later tokens as well
+	claims, err := tt.service.ValidateAccessToken(refreshed.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed, err := tt.service.Reauthenticate(claims, tt.user)
+	if err != nil {
+		t.Fatalf("Reauthenticate returned error: %v", err)
+	}
+	if confirmed.RefreshToken != "" {
+		t.Error("Reauthenticate issued a refresh token")
+	}
+	if got := tt.authTime(t, confirmed.Token); time.Since(got) > time.Minute {
+		t.Errorf("auth_time after re-authenticating = %s, want now", got)
+	}
+	again, err := tt.service.Refresh(refreshed.RefreshToken, models.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tt.authTime(t, again.Token); time.Since(got) > time.Minute {
+		t.Errorf("auth_time after refreshing a re-authenticated session = %s, want now", got)
+	}
+
+	// Re-authenticating needs a live session of the user
+	_ = tt.sessions.Revoke(tt.sessions.sessions[0].ID)
+	if _, err := tt.service.Reauthenticate(claims, tt.user); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Reauthenticate of a revoked session = %v, want ErrSessionRevoked", err)
+	}
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount      = 10
+	maxChallengeAttempts   = 5
+	totpAllowedClockSkew   = 1
+	twoFactorQRCodePixels  = 256
+	recoveryCodeGroupChars = 4
+)
+
+var (
+	// ErrTwoFactorAlreadyEnabled is returned when enrolling an account that already uses 2FA
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnabled is returned when 2FA is required to be active but is not
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTwoFactorNotEnrolled is returned when confirming without starting enrollment first
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment has not been started")
+	// ErrInvalidTwoFactorCode is returned for wrong, reused or malformed codes
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor authentication code")
+	// ErrInvalidChallenge is returned for unknown, expired or exhausted login challenges
+	ErrInvalidChallenge = errors.New("invalid or expired two-factor challenge")
+)
+
+// TwoFactorService interface defines TOTP two-factor authentication methods
+type TwoFactorService interface {
+	Enroll(userID uint) (*models.TwoFactorEnrollResponse, error)
+	Confirm(userID uint, code string) (*models.RecoveryCodesResponse, error)
+	Disable(userID uint, req models.TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(userID uint, code string) (*models.RecoveryCodesResponse, error)
+	CreateChallenge(user *models.User) (*models.LoginResponse, error)
+	VerifyChallenge(req models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	PurgeExpired() error
+}
+
+// twoFactorService implements TwoFactorService interface
+type twoFactorService struct {
+	userRepo      repository.UserRepository
+	twoFactorRepo repository.TwoFactorRepository
+	tokenService  TokenService
+	loginHistory  LoginHistoryService
+	authenticator Authenticator
+	appCfg        config.AppConfig
+	authCfg       config.AuthConfig
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(
+	userRepo repository.UserRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	tokenService TokenService,
+	loginHistory LoginHistoryService,
+	authenticator Authenticator,
+	appCfg config.AppConfig,
+	authCfg config.AuthConfig,
+) TwoFactorService {
+	return &twoFactorService{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		tokenService:  tokenService,
+		loginHistory:  loginHistory,
+		authenticator: authenticator,
+		appCfg:        appCfg,
+		authCfg:       authCfg,
+	}
+}
+
+// Enroll generates a new pending TOTP secret for the user
+func (s *twoFactorService) Enroll(userID uint) (*models.TwoFactorEnrollResponse, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.Encrypt(secret, s.appCfg.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorSecret = encrypted
+	user.TwoFactorLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	uri := utils.TOTPURI(s.appCfg.Name, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, twoFactorQRCodePixels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURL: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Confirm activates 2FA once the user proves the authenticator app is set up
+func (s *twoFactorService) Confirm(userID uint, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	ok, err := s.verifyTOTP(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	// Reload so the accepted step recorded by verifyTOTP is not overwritten
+	user, err = s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.TwoFactorEnabledAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return s.generateRecoveryCodes(user.ID)
+}
+
+// Disable turns off 2FA after checking the password and a current code
+func (s *twoFactorService) Disable(userID uint, req models.TwoFactorDisableRequest) error {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	ok, err := verifyPassword(s.authenticator, user, req.Password)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrIncorrectPassword
+	}
+
+	ok, err = s.verifyCode(user, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user, err = s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	user.TwoFactorSecret = ""
+	user.TwoFactorEnabledAt = nil
+	user.TwoFactorLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.DeleteRecoveryCodes(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current TOTP code
+func (s *twoFactorService) RegenerateRecoveryCodes(userID uint, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	ok, err := s.verifyTOTP(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return s.generateRecoveryCodes(user.ID)
+}
+
+// CreateChallenge starts the second login step for a user whose password was verified
+func (s *twoFactorService) CreateChallenge(user *models.User) (*models.LoginResponse, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &models.TwoFactorChallenge{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.authCfg.TwoFactorChallengeTTL),
+	}
+	if err := s.twoFactorRepo.CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: challenge.ExpiresAt,
+	}, nil
+}
+
+// VerifyChallenge exchanges a login challenge and a valid code for a token pair.
+// Attempts on a valid challenge are recorded in the login history.
+func (s *twoFactorService) VerifyChallenge(req models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	user, response, err := s.verifyChallenge(req, client)
+	if user != nil {
+		s.loginHistory.Record(models.LoginMethodTwoFactor, user.Email, user, client, err)
+	}
+	return response, err
+}
+
+// verifyChallenge checks the code of a login challenge. The user is returned once the challenge is known to be valid.
+func (s *twoFactorService) verifyChallenge(req models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.User, *models.LoginResponse, error) {
+	challenge, err := s.twoFactorRepo.GetChallengeByHash(utils.HashToken(req.ChallengeToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, err
+	}
+	if time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= maxChallengeAttempts {
+		return nil, nil, ErrInvalidChallenge
+	}
+
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return nil, nil, ErrInvalidChallenge
+	}
+	if user.IsLocked(time.Now()) {
+		return user, nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+
+	ok, err := s.verifyCode(user, req.Code)
+	if err != nil {
+		return user, nil, err
+	}
+	if !ok {
+		if err := s.twoFactorRepo.IncrementChallengeAttempts(challenge.ID); err != nil {
+			return user, nil, err
+		}
+		// Wrong codes also count towards the account lockout, since every new
+		// login would otherwise start a challenge with a fresh attempt budget
+		if err := recordFailedLogin(s.userRepo, s.authCfg, user); err != nil {
+			return user, nil, err
+		}
+		return user, nil, ErrInvalidTwoFactorCode
+	}
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ClearFailedLogins(user.ID); err != nil {
+			return user, nil, err
+		}
+	}
+
+	// Challenges are single-use
+	deleted, err := s.twoFactorRepo.DeleteChallenge(challenge.ID)
+	if err != nil {
+		return user, nil, err
+	}
+	if !deleted {
+		return nil, nil, ErrInvalidChallenge
+	}
+
+	if user.IsDeactivated() {
+		return user, nil, ErrAccountDeactivated
+	}
+
+	response, err := s.tokenService.IssueTokens(user, client)
+	return user, response, err
+}
+
+// PurgeExpired removes login challenges that can no longer be used
+func (s *twoFactorService) PurgeExpired() error {
+	return s.twoFactorRepo.DeleteExpiredChallenges()
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code
+func (s *twoFactorService) verifyCode(user *models.User, code string) (bool, error) {
+	ok, err := s.verifyTOTP(user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	return s.twoFactorRepo.UseRecoveryCode(user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+// verifyTOTP checks a TOTP code and rejects codes that were already used
+func (s *twoFactorService) verifyTOTP(user *models.User, code string) (bool, error) {
+	if user.TwoFactorSecret == "" {
+		return false, nil
+	}
+
+	secret, err := utils.Decrypt(user.TwoFactorSecret, s.appCfg.Key)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now(), totpAllowedClockSkew)
+	if !ok {
+		return false, nil
+	}
+
+	return s.twoFactorRepo.AdvanceLastStep(user.ID, step)
+}
+
+// generateRecoveryCodes replaces the user's recovery codes and returns the plaintext values
+func (s *twoFactorService) generateRecoveryCodes(userID uint) (*models.RecoveryCodesResponse, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	stored := make([]models.TwoFactorRecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			return nil, err
+		}
+		code := formatRecoveryCode(secret[:16])
+		plain = append(plain, code)
+		stored = append(stored, models.TwoFactorRecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, stored); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: plain}, nil
+}
+
+// getUser loads a user by ID
+func (s *twoFactorService) getUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// formatRecoveryCode splits a code into dash separated groups for readability
+func formatRecoveryCode(code string) string {
+	var groups []string
+	for i := 0; i < len(code); i += recoveryCodeGroupChars {
+		groups = append(groups, code[i:i+recoveryCodeGroupChars])
+	}
+	return strings.Join(groups, "-")
+}
+
+// normalizeRecoveryCode strips formatting so codes can be entered in any case or grouping
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
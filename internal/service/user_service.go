@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"time"
+
+	"golang-starter-kit/config"
 	"golang-starter-kit/internal/models"
 	"golang-starter-kit/internal/repository"
 	"golang-starter-kit/utils"
@@ -9,33 +13,85 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrUserNotFound is returned when a user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidCredentials is returned by Login for an unknown email or a wrong password
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrEmailNotVerified is returned by Login when email verification is required and missing
+	ErrEmailNotVerified = errors.New("email address has not been verified")
+	// ErrAccountLocked is returned by Login while the account is locked after too many failed attempts
+	ErrAccountLocked = errors.New("account is temporarily locked due to too many failed login attempts")
+	// ErrAccountDeactivated is returned by logins to an account that has been deactivated
+	ErrAccountDeactivated = errors.New("account has been deactivated")
+)
+
+// AccountLockedError wraps ErrAccountLocked with the time the lockout ends
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return ErrAccountLocked.Error()
+}
+
+// Unwrap allows errors.Is(err, ErrAccountLocked)
+func (e *AccountLockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
 // UserService interface defines user service methods
 type UserService interface {
-	CreateUser(req models.UserCreateRequest) (*models.UserResponse, error)
-	GetUserByID(id uint) (*models.UserResponse, error)
-	UpdateUser(id uint, req models.UserUpdateRequest) (*models.UserResponse, error)
-	DeleteUser(id uint) error
-	GetAllUsersWithFilter(req models.UserListRequest) (*models.UsersListResponse, error)
-	Login(req models.LoginRequest) (*models.LoginResponse, error)
+	CreateUser(ctx context.Context, req models.UserCreateRequest) (*models.UserResponse, error)
+	GetUserByID(ctx context.Context, id uint) (*models.UserResponse, error)
+	UpdateUser(ctx context.Context, id uint, req models.UserUpdateRequest) (*models.UserResponse, error)
+	DeleteUser(ctx context.Context, id uint) error
+	GetAllUsersWithFilter(ctx context.Context, req models.UserListRequest) (*models.UsersListResponse, error)
+	Login(req models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	Reauthenticate(claims *utils.JWTClaims, req models.ReauthenticateRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	UnlockUser(ctx context.Context, id uint) error
 }
 
 // userService implements UserService interface
 type userService struct {
-	userRepo  repository.UserRepository
-	jwtSecret string
+	userRepo         repository.UserRepository
+	tokenService     TokenService
+	twoFactorService TwoFactorService
+	passwordPolicy   PasswordPolicyService
+	loginHistory     LoginHistoryService
+	authenticator    Authenticator
+	hasher           utils.PasswordHasher
+	authCfg          config.AuthConfig
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo repository.UserRepository, jwtSecret string) UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	tokenService TokenService,
+	twoFactorService TwoFactorService,
+	passwordPolicy PasswordPolicyService,
+	loginHistory LoginHistoryService,
+	authenticator Authenticator,
+	hasher utils.PasswordHasher,
+	authCfg config.AuthConfig,
+) UserService {
 	return &userService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:         userRepo,
+		tokenService:     tokenService,
+		twoFactorService: twoFactorService,
+		passwordPolicy:   passwordPolicy,
+		loginHistory:     loginHistory,
+		authenticator:    authenticator,
+		hasher:           hasher,
+		authCfg:          authCfg,
 	}
 }
 
-// CreateUser creates a new user
-func (s *userService) CreateUser(req models.UserCreateRequest) (*models.UserResponse, error) {
-	// Check if user with email already exists
+// CreateUser creates a new user. Within an organization the user becomes a member of it.
+func (s *userService) CreateUser(ctx context.Context, req models.UserCreateRequest) (*models.UserResponse, error) {
+	req.Email = models.NormalizeEmail(req.Email)
+
+	// Check if user with email already exists; emails are unique across organizations
 	existingUser, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -44,20 +100,26 @@ func (s *userService) CreateUser(req models.UserCreateRequest) (*models.UserResp
 		return nil, errors.New("user with this email already exists")
 	}
 
+	if err := s.passwordPolicy.Check(nil, req.Password); err != nil {
+		return nil, err
+	}
+
 	// Hash password
-	hashedPassword, err := utils.HashPassword(req.Password)
+	hashedPassword, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create user
+	now := time.Now()
 	user := &models.User{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: hashedPassword,
+		Name:              req.Name,
+		Email:             req.Email,
+		Password:          hashedPassword,
+		PasswordChangedAt: &now,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.WithContext(ctx).Create(user); err != nil {
 		return nil, err
 	}
 
@@ -66,11 +128,11 @@ func (s *userService) CreateUser(req models.UserCreateRequest) (*models.UserResp
 }
 
 // GetUserByID gets a user by ID
-func (s *userService) GetUserByID(id uint) (*models.UserResponse, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) GetUserByID(ctx context.Context, id uint) (*models.UserResponse, error) {
+	user, err := s.userRepo.WithContext(ctx).GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -80,11 +142,12 @@ func (s *userService) GetUserByID(id uint) (*models.UserResponse, error) {
 }
 
 // UpdateUser updates a user
-func (s *userService) UpdateUser(id uint, req models.UserUpdateRequest) (*models.UserResponse, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) UpdateUser(ctx context.Context, id uint, req models.UserUpdateRequest) (*models.UserResponse, error) {
+	users := s.userRepo.WithContext(ctx)
+	user, err := users.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -94,7 +157,8 @@ func (s *userService) UpdateUser(id uint, req models.UserUpdateRequest) (*models
 		user.Name = req.Name
 	}
 	if req.Email != "" {
-		// Check if email is already taken by another user
+		req.Email = models.NormalizeEmail(req.Email)
+		// Check if email is already taken by another user in any organization
 		existingUser, err := s.userRepo.GetByEmail(req.Email)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
@@ -102,10 +166,14 @@ func (s *userService) UpdateUser(id uint, req models.UserUpdateRequest) (*models
 		if existingUser != nil && existingUser.ID != id {
 			return nil, errors.New("email is already taken")
 		}
+		if user.Email != req.Email {
+			// A new address has to be verified again
+			user.EmailVerifiedAt = nil
+		}
 		user.Email = req.Email
 	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := users.Update(user); err != nil {
 		return nil, err
 	}
 
@@ -114,20 +182,22 @@ func (s *userService) UpdateUser(id uint, req models.UserUpdateRequest) (*models
 }
 
 // DeleteUser deletes a user
-func (s *userService) DeleteUser(id uint) error {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) DeleteUser(ctx context.Context, id uint) error {
+	users := s.userRepo.WithContext(ctx)
+	user, err := users.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
 		return err
 	}
 
-	return s.userRepo.Delete(user.ID)
+	return users.Delete(user.ID)
 }
 
-// GetAllUsersWithFilter gets all users with filters and pagination
-func (s *userService) GetAllUsersWithFilter(req models.UserListRequest) (*models.UsersListResponse, error) {
+// GetAllUsersWithFilter gets all users with filters and pagination. Within an
+// organization only its members are listed.
+func (s *userService) GetAllUsersWithFilter(ctx context.Context, req models.UserListRequest) (*models.UsersListResponse, error) {
 	// Set default values
 	if req.Page < 1 {
 		req.Page = 1
@@ -139,7 +209,7 @@ func (s *userService) GetAllUsersWithFilter(req models.UserListRequest) (*models
 		req.Limit = 100 // Max limit to prevent abuse
 	}
 
-	users, total, err := s.userRepo.GetAllWithFilter(req)
+	users, total, err := s.userRepo.WithContext(ctx).GetAllWithFilter(req)
 	if err != nil {
 		return nil, err
 	}
@@ -168,31 +238,158 @@ func (s *userService) GetAllUsersWithFilter(req models.UserListRequest) (*models
 	return response, nil
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *userService) Login(req models.LoginRequest) (*models.LoginResponse, error) {
+// Login authenticates a user and returns an access and refresh token pair.
+// Accounts with two-factor authentication get a challenge to complete instead.
+// Every attempt is recorded in the login history.
+func (s *userService) Login(req models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	user, response, err := s.login(req, client)
+	s.loginHistory.Record(models.LoginMethodPassword, req.Email, user, client, loginOutcome(response, err))
+	return response, err
+}
+
+// login checks the credentials of a login with the authenticator chain.
+// The user is returned whenever the email is known.
+func (s *userService) login(req models.LoginRequest, client models.ClientInfo) (*models.User, *models.LoginResponse, error) {
 	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	// Refuse locked accounts before looking at the password
+	if user != nil && user.IsLocked(time.Now()) {
+		return user, nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+
+	// Check password; directory users may be provisioned here on their first login
+	authenticated, err := s.authenticator.Authenticate(req.Email, req.Password)
+	if err != nil {
+		if user != nil && errors.Is(err, ErrInvalidCredentials) {
+			if err := recordFailedLogin(s.userRepo, s.authCfg, user); err != nil {
+				return user, nil, err
+			}
+		}
+		return user, nil, err
+	}
+	user = authenticated
+
+	// With 2FA the counter is only reset once the second factor is verified, so
+	// logging in again does not give code guesses a fresh budget
+	if !user.IsTwoFactorEnabled() && (user.FailedLoginAttempts > 0 || user.LockedUntil != nil) {
+		if err := s.userRepo.ClearFailedLogins(user.ID); err != nil {
+			return user, nil, err
+		}
+	}
+
+	// Deactivation is only revealed to callers who know the password
+	if user.IsDeactivated() {
+		return user, nil, ErrAccountDeactivated
+	}
+
+	if s.authCfg.RequireEmailVerification && !user.IsEmailVerified() {
+		return user, nil, ErrEmailNotVerified
+	}
+
+	if s.passwordPolicy.IsExpired(user) {
+		return user, nil, ErrPasswordExpired
+	}
+
+	var response *models.LoginResponse
+	if user.IsTwoFactorEnabled() {
+		response, err = s.twoFactorService.CreateChallenge(user)
+	} else {
+		// Issue access and refresh tokens
+		response, err = s.tokenService.IssueTokens(user, client)
+	}
+	return user, response, err
+}
+
+// Reauthenticate checks the password of the token's user again and returns an
+// access token with a fresh auth_time for actions that require recent authentication.
+// Wrong passwords count towards the account lockout like failed logins.
+func (s *userService) Reauthenticate(claims *utils.JWTClaims, req models.ReauthenticateRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	user, err := s.userRepo.GetByID(claims.UserID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid email or password")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 
-	// Check password
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		return nil, errors.New("invalid email or password")
+	response, err := s.reauthenticate(claims, user, req.Password)
+	s.loginHistory.Record(models.LoginMethodReauthenticate, user.Email, user, client, err)
+	return response, err
+}
+
+// reauthenticate checks the password and refreshes the session's authentication time
+func (s *userService) reauthenticate(claims *utils.JWTClaims, user *models.User, password string) (*models.LoginResponse, error) {
+	if user.IsLocked(time.Now()) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, s.jwtSecret)
+	ok, err := verifyPassword(s.authenticator, user, password)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		if err := recordFailedLogin(s.userRepo, s.authCfg, user); err != nil {
+			return nil, err
+		}
+		return nil, ErrIncorrectPassword
+	}
 
-	response := &models.LoginResponse{
-		Token: token,
-		User:  user.ToResponse(),
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ClearFailedLogins(user.ID); err != nil {
+			return nil, err
+		}
 	}
 
-	return response, nil
+	if user.IsDeactivated() {
+		return nil, ErrAccountDeactivated
+	}
+
+	return s.tokenService.Reauthenticate(claims, user)
+}
+
+// UnlockUser clears a user's failed login attempts and lifts any lockout
+func (s *userService) UnlockUser(ctx context.Context, id uint) error {
+	users := s.userRepo.WithContext(ctx)
+	user, err := users.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return users.ClearFailedLogins(user.ID)
+}
+
+// recordFailedLogin counts a failed attempt and locks the account once the
+// threshold is reached. The lockout doubles with every further failure.
+// Wrong passwords at login, re-authentication and password changes, and wrong
+// two-factor codes, count towards the same lockout.
+func recordFailedLogin(userRepo repository.UserRepository, authCfg config.AuthConfig, user *models.User) error {
+	if authCfg.LockoutThreshold <= 0 {
+		return nil
+	}
+
+	attempts, err := userRepo.RecordFailedLogin(user.ID)
+	if err != nil {
+		return err
+	}
+	if attempts < authCfg.LockoutThreshold {
+		return nil
+	}
+
+	duration := authCfg.LockoutDuration
+	maxDuration := authCfg.LockoutMaxDuration
+	for i := authCfg.LockoutThreshold; i < attempts; i++ {
+		duration *= 2
+		if maxDuration > 0 && duration >= maxDuration {
+			duration = maxDuration
+			break
+		}
+	}
+
+	return userRepo.LockUntil(user.ID, time.Now().Add(duration))
 }
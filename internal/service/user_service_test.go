@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/tenant"
+)
+
+// newTenantUsers holds Jane in organization 1 and John in organization 2
+func newTenantUsers() *fakeUserRepo {
+	users := &fakeUserRepo{organizations: map[uint][]uint{1: {1}, 2: {2}}}
+	_ = users.Create(&models.User{Name: "Jane", Email: "jane@example.com"})
+	_ = users.Create(&models.User{Name: "John", Email: "john@example.com"})
+	return users
+}
+
+func TestUserServiceScopesToOrganization(t *testing.T) {
+	users := newTenantUsers()
+	s := NewUserService(users, nil, nil, nil, nil, nil, fakeHasher{}, config.AuthConfig{})
+	acme := tenant.WithOrganization(context.Background(), 1)
+
+	// John belongs to another organization
+	if _, err := s.GetUserByID(acme, 2); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUserByID = %v, want ErrUserNotFound", err)
+	}
+	if _, err := s.UpdateUser(acme, 2, models.UserUpdateRequest{Name: "Mallory"}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("UpdateUser = %v, want ErrUserNotFound", err)
+	}
+	if err := s.DeleteUser(acme, 2); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("DeleteUser = %v, want ErrUserNotFound", err)
+	}
+	if err := s.UnlockUser(acme, 2); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("UnlockUser = %v, want ErrUserNotFound", err)
+	}
+	john, err := users.GetByID(2)
+	if err != nil || john.Name != "John" {
+		t.Fatalf("John was changed or deleted from another organization: %+v, %v", john, err)
+	}
+
+	// Members of the organization are reachable
+	if user, err := s.GetUserByID(acme, 1); err != nil || user.Email != "jane@example.com" {
+		t.Errorf("GetUserByID = %+v, %v; want Jane", user, err)
+	}
+	if err := s.DeleteUser(acme, 1); err != nil {
+		t.Errorf("DeleteUser = %v, want Jane deleted", err)
+	}
+}
+
+func TestCreateUserNormalizesEmail(t *testing.T) {
+	users := &fakeUserRepo{}
+	passwordPolicy := NewPasswordPolicyService(nil, fakeHasher{}, config.PasswordConfig{})
+	s := NewUserService(users, nil, nil, passwordPolicy, nil, nil, fakeHasher{}, config.AuthConfig{})
+
+	created, err := s.CreateUser(context.Background(), models.UserCreateRequest{Name: "Jane", Email: " Jane@Example.COM ", Password: "correct horse battery staple"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	if created.Email != "jane@example.com" {
+		t.Errorf("CreateUser stored %q, want the lower-cased, trimmed email", created.Email)
+	}
+
+	// Another spelling of the same address is the same account
+	if _, err := s.CreateUser(context.Background(), models.UserCreateRequest{Name: "Jane", Email: "JANE@example.com", Password: "correct horse battery staple"}); err == nil {
+		t.Error("CreateUser registered a second account for the same email")
+	}
+}
+
+// newLockoutTest holds Jane with the password "secret" and locks accounts
+// after three failed logins for 15 minutes, doubling up to an hour
+func newLockoutTest() (UserService, *models.User) {
+	users := &fakeUserRepo{}
+	jane := &models.User{Name: "Jane", Email: "jane@example.com", Password: "hashed:secret"}
+	_ = users.Create(jane)
+
+	authCfg := config.AuthConfig{LockoutThreshold: 3, LockoutDuration: 15 * time.Minute, LockoutMaxDuration: time.Hour}
+	s := NewUserService(users, fakeTokenService{}, nil, NewPasswordPolicyService(nil, fakeHasher{}, config.PasswordConfig{}),
+		&fakeLoginHistory{}, NewLocalAuthenticator(users, fakeHasher{}), fakeHasher{}, authCfg)
+	return s, jane
+}
+
+// lockedFor reports how long the user stays locked, rounded to the minute
+func lockedFor(user *models.User) time.Duration {
+	if user.LockedUntil == nil {
+		return 0
+	}
+	return time.Until(*user.LockedUntil).Round(time.Minute)
+}
+
+func TestLoginLocksAccountAtThreshold(t *testing.T) {
+	s, jane := newLockoutTest()
+	wrong := models.LoginRequest{Email: "jane@example.com", Password: "wrong"}
+	right := models.LoginRequest{Email: "jane@example.com", Password: "secret"}
+
+	for i := 1; i < 3; i++ {
+		if _, err := s.Login(wrong, models.ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failed login %d = %v, want ErrInvalidCredentials", i, err)
+		}
+	}
+	if jane.LockedUntil != nil {
+		t.Fatal("the account was locked below the threshold")
+	}
+
+	if _, err := s.Login(wrong, models.ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("failed login at the threshold = %v, want ErrInvalidCredentials", err)
+	}
+	if got := lockedFor(jane); got != 15*time.Minute {
+		t.Fatalf("locked for %s, want 15m", got)
+	}
+
+	// The right password does not get through while locked
+	var locked *AccountLockedError
+	if _, err := s.Login(right, models.ClientInfo{}); !errors.As(err, &locked) || !locked.Until.Equal(*jane.LockedUntil) {
+		t.Fatalf("Login while locked = %v, want AccountLockedError until %s", err, jane.LockedUntil)
+	}
+	if jane.FailedLoginAttempts != 3 {
+		t.Errorf("attempts while locked were counted: %d", jane.FailedLoginAttempts)
+	}
+
+	// Failures after a lockout ends double it, up to the maximum
+	for _, want := range []time.Duration{30 * time.Minute, time.Hour, time.Hour} {
+		expired := time.Now().Add(-time.Second)
+		jane.LockedUntil = &expired
+		if _, err := s.Login(wrong, models.ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failed login after the lockout = %v, want ErrInvalidCredentials", err)
+		}
+		if got := lockedFor(jane); got != want {
+			t.Errorf("after %d failures locked for %s, want %s", jane.FailedLoginAttempts, got, want)
+		}
+	}
+}
+
+func TestLoginResetsFailedAttempts(t *testing.T) {
+	s, jane := newLockoutTest()
+	wrong := models.LoginRequest{Email: "jane@example.com", Password: "wrong"}
+	right := models.LoginRequest{Email: "jane@example.com", Password: "secret"}
+
+	for i := 0; i < 2; i++ {
+		_, _ = s.Login(wrong, models.ClientInfo{})
+	}
+	if _, err := s.Login(right, models.ClientInfo{}); err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	if jane.FailedLoginAttempts != 0 {
+		t.Fatalf("a successful login left %d failed attempts", jane.FailedLoginAttempts)
+	}
+
+	// The threshold counts from zero again
+	for i := 0; i < 2; i++ {
+		_, _ = s.Login(wrong, models.ClientInfo{})
+	}
+	if jane.LockedUntil != nil {
+		t.Error("failures before a successful login still counted towards the lockout")
+	}
+
+	// Unlocking lifts a lockout early
+	_, _ = s.Login(wrong, models.ClientInfo{})
+	if err := s.UnlockUser(context.Background(), jane.ID); err != nil {
+		t.Fatal(err)
+	}
+	if jane.LockedUntil != nil || jane.FailedLoginAttempts != 0 {
+		t.Fatalf("UnlockUser left %d attempts, locked until %v", jane.FailedLoginAttempts, jane.LockedUntil)
+	}
+	if _, err := s.Login(right, models.ClientInfo{}); err != nil {
+		t.Errorf("Login after UnlockUser = %v", err)
+	}
+}
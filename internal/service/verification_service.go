@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/mailer"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/internal/repository"
+	"golang-starter-kit/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidVerificationToken is returned for unknown, used or expired verification tokens
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	// ErrVerificationThrottled is returned when a verification email was sent too recently
+	ErrVerificationThrottled = errors.New("a verification email was sent recently, please try again later")
+)
+
+// VerificationService interface defines email verification methods
+type VerificationService interface {
+	SendVerification(userID uint) error
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+	PurgeExpired() error
+}
+
+// verificationService implements VerificationService interface
+type verificationService struct {
+	userRepo         repository.UserRepository
+	verificationRepo repository.EmailVerificationRepository
+	mailer           mailer.Mailer
+	appCfg           config.AppConfig
+	authCfg          config.AuthConfig
+}
+
+// NewVerificationService creates a new verification service
+func NewVerificationService(
+	userRepo repository.UserRepository,
+	verificationRepo repository.EmailVerificationRepository,
+	mailer mailer.Mailer,
+	appCfg config.AppConfig,
+	authCfg config.AuthConfig,
+) VerificationService {
+	return &verificationService{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		appCfg:           appCfg,
+		authCfg:          authCfg,
+	}
+}
+
+// SendVerification emails a new verification link to the user
+func (s *verificationService) SendVerification(userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.send(user)
+}
+
+// VerifyEmail marks the email address of the token's user as verified
+func (s *verificationService) VerifyEmail(token string) error {
+	verification, err := s.verificationRepo.GetByHash(utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	if verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+
+	marked, err := s.verificationRepo.MarkUsed(verification.ID)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(verification.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user)
+}
+
+// ResendVerification emails a new verification link if the account exists and is unverified.
+// It does not report whether the email belongs to an account.
+func (s *verificationService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	latest, err := s.verificationRepo.GetLatestForUser(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.authCfg.EmailVerificationResendInterval {
+		return ErrVerificationThrottled
+	}
+
+	return s.send(user)
+}
+
+// PurgeExpired removes verification tokens that can no longer be used
+func (s *verificationService) PurgeExpired() error {
+	return s.verificationRepo.DeleteExpired()
+}
+
+// send replaces any outstanding verification token of the user and emails a new one
+func (s *verificationService) send(user *models.User) error {
+	if err := s.verificationRepo.DeleteForUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	verification := &models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.authCfg.EmailVerificationTTL),
+	}
+	if err := s.verificationRepo.Create(verification); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.appCfg.URL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Verify your %s email address", s.appCfg.Name),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address using the link below. The link expires in %s.\n\n%s\n",
+			user.Name, s.authCfg.EmailVerificationTTL, link,
+		),
+	}
+
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("failed to send verification email: %v", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang-starter-kit/config"
+)
+
+// githubProvider logs users in with GitHub, which uses plain OAuth2 rather than OpenID Connect.
+// The account and its verified primary email are read from the REST API.
+type githubProvider struct {
+	cfg    config.SocialProviderConfig
+	client *http.Client
+}
+
+// githubUser holds the fields used from GET /user
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// githubEmail holds one entry of GET /user/emails
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// NewGitHubProvider creates a GitHub provider. AuthURL, TokenURL and APIURL
+// default to github.com and can be overridden for GitHub Enterprise.
+func NewGitHubProvider(cfg config.SocialProviderConfig, client *http.Client) Provider {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = "https://github.com/login/oauth/authorize"
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = "https://github.com/login/oauth/access_token"
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = "https://api.github.com"
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"read:user", "user:email"}
+	}
+	return &githubProvider{cfg: cfg, client: client}
+}
+
+// Name returns the provider name
+func (p *githubProvider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the URL the user is sent to for signing in
+func (p *githubProvider) AuthCodeURL(_ context.Context, req AuthRequest) (string, error) {
+	req.Nonce = ""
+	return authCodeURL(p.cfg.AuthURL, p.cfg, p.cfg.Scopes, req)
+}
+
+// Exchange redeems the authorization code and reads the GitHub account
+func (p *githubProvider) Exchange(ctx context.Context, code, codeVerifier, _ string) (*Identity, error) {
+	token, err := exchangeCode(ctx, p.client, p.cfg.TokenURL, p.cfg, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubUser
+	if err := getJSON(ctx, p.client, p.cfg.APIURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("GitHub did not return a user ID")
+	}
+
+	var emails []githubEmail
+	if err := getJSON(ctx, p.client, p.cfg.APIURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,222 @@
+package social
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+	"golang-starter-kit/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// idTokenAlgorithms lists the ID token signing algorithms accepted from providers
+var idTokenAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "EdDSA"}
+
+// discoveryDocument holds the fields used from an OpenID Provider configuration
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// flexibleBool decodes booleans that some providers send as strings
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = flexibleBool(v)
+	case string:
+		*b = flexibleBool(v == "true")
+	}
+	return nil
+}
+
+// idTokenClaims holds the standard OIDC claims used for login
+type idTokenClaims struct {
+	Nonce         string       `json:"nonce"`
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// oidcProvider logs users in with any OpenID Connect provider.
+// Endpoints and signing keys are discovered from the issuer and cached.
+type oidcProvider struct {
+	cfg    config.SocialProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]crypto.PublicKey
+}
+
+// NewOIDCProvider creates a provider for an OpenID Connect issuer
+func NewOIDCProvider(cfg config.SocialProviderConfig, client *http.Client) Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &oidcProvider{cfg: cfg, client: client}
+}
+
+// Name returns the provider name
+func (p *oidcProvider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the URL the user is sent to for signing in
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return authCodeURL(doc.AuthorizationEndpoint, p.cfg, p.cfg.Scopes, req)
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, p.client, doc.TokenEndpoint, p.cfg, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("provider did not return an ID token")
+	}
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(token.IDToken, claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.key(ctx, doc, kid)
+		},
+		jwt.WithValidMethods(idTokenAlgorithms),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("ID token has no expiry")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	identity := &Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}
+
+	// Some providers only return profile claims from the userinfo endpoint
+	if identity.Email == "" && doc.UserinfoEndpoint != "" {
+		var info idTokenClaims
+		if err := getJSON(ctx, p.client, doc.UserinfoEndpoint, token.AccessToken, &info); err != nil {
+			return nil, err
+		}
+		if info.Subject != identity.Subject {
+			return nil, errors.New("userinfo subject does not match the ID token")
+		}
+		identity.Email = info.Email
+		identity.EmailVerified = bool(info.EmailVerified)
+		if identity.Name == "" {
+			identity.Name = info.Name
+		}
+	}
+
+	return identity, nil
+}
+
+// discover fetches and caches the issuer's OpenID Provider configuration
+func (p *oidcProvider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discoveryDocument
+	endpoint := strings.TrimRight(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, p.client, endpoint, "", &doc); err != nil {
+		return nil, err
+	}
+	if doc.Issuer != p.cfg.Issuer {
+		return nil, errors.New("discovered issuer does not match the configured issuer")
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("provider configuration is missing required endpoints")
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// key returns the issuer's signing key with the given ID. The key set is
+// fetched again when the ID is unknown, so provider key rotation is picked up.
+func (p *oidcProvider) key(ctx context.Context, doc *discoveryDocument, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	var set models.JWKSet
+	if err := getJSON(ctx, p.client, doc.JWKSURI, "", &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := utils.ParsePublicJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.New("unknown ID token signing key")
+}
+
+// lookupKey finds a cached key. Tokens without a kid are accepted when the issuer has a single key.
+func (p *oidcProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
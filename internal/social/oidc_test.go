@@ -0,0 +1,315 @@
+package social
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID    = "starter-kit"
+	testRedirectURL = "https://app.example.com/api/v1/auth/social/test/callback"
+)
+
+// grant is an authorization code issued by the test issuer
+type grant struct {
+	challenge string
+	nonce     string
+	subject   string
+}
+
+// testIssuer is an in-process OpenID Provider with discovery, JWKS and token endpoints
+type testIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	keys   *utils.KeySet
+
+	mu     sync.Mutex
+	grants map[string]grant
+	// claims are added to (or replace) the standard claims of every ID token
+	claims jwt.MapClaims
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "issuer.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := utils.LoadKeySet(config.JWTConfig{Algorithm: "ES256", SigningKeyFile: keyFile, SigningKeyID: "issuer-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &testIssuer{t: t, keys: keys, grants: map[string]grant{}, claims: jwt.MapClaims{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, issuer.keys.JWKS())
+	})
+	mux.HandleFunc("/token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// authorize stands in for the user signing in at the authorization URL and
+// returns the code the issuer redirects back with
+func (i *testIssuer) authorize(authURL, subject string) string {
+	i.t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		i.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		i.t.Fatalf("authorization URL %s does not use PKCE with S256", authURL)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	code := "code-" + strconv.Itoa(len(i.grants))
+	i.grants[code] = grant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), subject: subject}
+	return code
+}
+
+// token redeems a code once, checking the PKCE verifier against the stored challenge
+func (i *testIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	i.mu.Lock()
+	g, ok := i.grants[r.PostForm.Get("code")]
+	delete(i.grants, r.PostForm.Get("code"))
+	extra := i.claims
+	i.mu.Unlock()
+
+	if !ok || r.PostForm.Get("client_id") != testClientID || r.PostForm.Get("redirect_uri") != testRedirectURL {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if utils.CodeChallengeS256(r.PostForm.Get("code_verifier")) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            i.server.URL,
+		"aud":            testClientID,
+		"sub":            g.subject,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"nonce":          g.nonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+	}
+	for name, value := range extra {
+		claims[name] = value
+	}
+	idToken, err := i.keys.Sign(claims)
+	if err != nil {
+		i.t.Error(err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (i *testIssuer) provider() Provider {
+	return NewOIDCProvider(config.SocialProviderConfig{
+		Name:        "test",
+		Type:        "oidc",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		Issuer:      i.server.URL,
+	}, i.server.Client())
+}
+
+// login runs the authorization code flow for the verifier "verifier" and nonce "nonce",
+// then redeems the code with the given ones and returns the identity the provider reports
+func (i *testIssuer) login(provider Provider, verifier, nonce string) (*Identity, error) {
+	i.t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), AuthRequest{
+		State:         "state",
+		CodeChallenge: utils.CodeChallengeS256("verifier"),
+		Nonce:         "nonce",
+	})
+	if err != nil {
+		i.t.Fatalf("AuthCodeURL returned error: %v", err)
+	}
+	code := i.authorize(authURL, "subject-1")
+	return provider.Exchange(context.Background(), code, verifier, nonce)
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	authURL, err := issuer.provider().AuthCodeURL(context.Background(), AuthRequest{
+		State:         "state",
+		CodeChallenge: "challenge",
+		Nonce:         "nonce",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != issuer.server.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %s, want the discovered one", got)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state",
+		"code_challenge":        "challenge",
+		"code_challenge_method": "S256",
+		"nonce":                 "nonce",
+	}
+	for name, value := range want {
+		if got := u.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	identity, err := issuer.login(issuer.provider(), "verifier", "nonce")
+	if err != nil {
+		t.Fatalf("Exchange returned error: %v", err)
+	}
+	want := Identity{Subject: "subject-1", Email: "jane@example.com", EmailVerified: true, Name: "Jane Doe"}
+	if *identity != want {
+		t.Errorf("Exchange = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestOIDCExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	if _, err := issuer.login(issuer.provider(), "another verifier", "nonce"); err == nil {
+		t.Fatal("Exchange succeeded with a code verifier that does not match the challenge")
+	}
+}
+
+func TestOIDCExchangeRejectsNonceMismatch(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	if _, err := issuer.login(issuer.provider(), "verifier", "another nonce"); err == nil {
+		t.Fatal("Exchange accepted an ID token issued for another nonce")
+	}
+}
+
+func TestOIDCExchangeValidatesIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"wrong audience", jwt.MapClaims{"aud": "another-client"}},
+		{"wrong issuer", jwt.MapClaims{"iss": "https://attacker.example.com"}},
+		{"expired", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}},
+		{"no expiry", jwt.MapClaims{"exp": nil}},
+		{"no subject", jwt.MapClaims{"sub": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newTestIssuer(t)
+			issuer.claims = tt.claims
+
+			if _, err := issuer.login(issuer.provider(), "verifier", "nonce"); err == nil {
+				t.Fatal("Exchange accepted an invalid ID token")
+			}
+		})
+	}
+}
+
+func TestOIDCExchangeReadsStringEmailVerified(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{"true", true},
+		{"false", false},
+		{false, false},
+	}
+
+	for _, tt := range tests {
+		issuer := newTestIssuer(t)
+		issuer.claims = jwt.MapClaims{"email_verified": tt.value}
+
+		identity, err := issuer.login(issuer.provider(), "verifier", "nonce")
+		if err != nil {
+			t.Fatalf("Exchange returned error: %v", err)
+		}
+		if identity.EmailVerified != tt.want {
+			t.Errorf("email_verified %#v gave EmailVerified = %v, want %v", tt.value, identity.EmailVerified, tt.want)
+		}
+	}
+}
+
+func TestOIDCDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDCProvider(config.SocialProviderConfig{
+		Name:     "test",
+		Type:     "oidc",
+		ClientID: testClientID,
+		// Discovery reports the issuer without the trailing slash
+		Issuer: issuer.server.URL + "/",
+	}, issuer.server.Client())
+
+	if _, err := provider.AuthCodeURL(context.Background(), AuthRequest{State: "state"}); err == nil {
+		t.Fatal("AuthCodeURL accepted a discovery document for another issuer")
+	}
+}
@@ -0,0 +1,202 @@
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+)
+
+// ErrUnknownProvider is returned when no provider is registered under a name
+var ErrUnknownProvider = errors.New("unknown social login provider")
+
+// Identity is the account reported by a provider after a successful login
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// AuthRequest carries the per-login values bound into the authorization URL
+type AuthRequest struct {
+	State         string
+	CodeChallenge string
+	Nonce         string
+}
+
+// Provider implements the authorization code flow against one identity provider
+type Provider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry creates the providers described by the configuration.
+// The HTTP client is used for every call to the providers; pass nil for a default client.
+func NewRegistry(cfg config.SocialConfig, client *http.Client) (*Registry, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	r := &Registry{providers: map[string]Provider{}}
+	for _, p := range cfg.Providers {
+		if p.ClientID == "" {
+			return nil, fmt.Errorf("social login provider %q has no client ID", p.Name)
+		}
+
+		var provider Provider
+		switch p.Type {
+		case "oidc":
+			if p.Issuer == "" {
+				return nil, fmt.Errorf("social login provider %q has no issuer", p.Name)
+			}
+			provider = NewOIDCProvider(p, client)
+		case "github":
+			provider = NewGitHubProvider(p, client)
+		default:
+			return nil, fmt.Errorf("social login provider %q has unsupported type %q", p.Name, p.Type)
+		}
+		r.Register(provider)
+	}
+	return r, nil
+}
+
+// Register adds a provider, replacing any provider with the same name
+func (r *Registry) Register(provider Provider) {
+	r.providers[provider.Name()] = provider
+}
+
+// Get returns the provider registered under name
+func (r *Registry) Get(name string) (Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// Names returns the registered provider names in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// authCodeURL builds the authorization URL shared by every provider type
+func authCodeURL(endpoint string, cfg config.SocialProviderConfig, scopes []string, req AuthRequest) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", cfg.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", req.State)
+	q.Set("code_challenge", req.CodeChallenge)
+	q.Set("code_challenge_method", "S256")
+	if req.Nonce != "" {
+		q.Set("nonce", req.Nonce)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// tokenResponse is the token endpoint response (RFC 6749 section 5.1)
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeCode redeems an authorization code at the token endpoint
+func exchangeCode(ctx context.Context, client *http.Client, endpoint string, cfg config.SocialProviderConfig, code, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {cfg.RedirectURL},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	status, err := doJSON(client, req, &token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("token exchange failed: %s %s", token.Error, token.ErrorDescription)
+	}
+	if status != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("token exchange failed with status %d", status)
+	}
+	return &token, nil
+}
+
+// getJSON fetches a JSON document, authenticating with the access token when one is given
+func getJSON(ctx context.Context, client *http.Client, endpoint, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	status, err := doJSON(client, req, v)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET %s failed with status %d", endpoint, status)
+	}
+	return nil
+}
+
+// doJSON sends the request and decodes a JSON response body into v
+func doJSON(client *http.Client, req *http.Request, v interface{}) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp.StatusCode, fmt.Errorf("invalid JSON response from %s: %w", req.URL.Host, err)
+	}
+	return resp.StatusCode, nil
+}
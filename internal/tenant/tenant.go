@@ -0,0 +1,68 @@
+// Package tenant scopes database access to the organization of the current request.
+//
+// The tenant middleware stores the resolved organization in the request context.
+// Queries, updates and deletes made with that context through a database handle
+// on which Register was called only see rows of models implementing Scoped that
+// belong to the organization.
+package tenant
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type contextKey struct{}
+
+// Scoped is implemented by models whose rows belong to organizations
+type Scoped interface {
+	// TenantCondition returns the condition selecting the rows of the organization
+	TenantCondition(organizationID uint) clause.Expression
+}
+
+// WithOrganization returns a copy of ctx that scopes queries to the organization
+func WithOrganization(ctx context.Context, organizationID uint) context.Context {
+	return context.WithValue(ctx, contextKey{}, organizationID)
+}
+
+// OrganizationID returns the organization ctx is scoped to. It reports false
+// when the request did not select an organization.
+func OrganizationID(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	id, ok := ctx.Value(contextKey{}).(uint)
+	return id, ok && id != 0
+}
+
+// Register installs the callbacks that add the tenant condition to statements on Scoped models
+func Register(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scope); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scope)
+}
+
+// scope adds the tenant condition of the statement's model when its context selects an organization
+func scope(db *gorm.DB) {
+	organizationID, ok := OrganizationID(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+
+	scoped, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(Scoped)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{scoped.TenantCondition(organizationID)}})
+}
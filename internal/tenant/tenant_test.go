@@ -0,0 +1,121 @@
+package tenant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB opens a handle that builds statements without a database and has the tenant callbacks registered
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// hasVar reports whether the statement binds the value
+func hasVar(stmt *gorm.Statement, value interface{}) bool {
+	for _, v := range stmt.Vars {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOrganizationID(t *testing.T) {
+	if _, ok := OrganizationID(context.Background()); ok {
+		t.Error("a context without an organization reported one")
+	}
+	if _, ok := OrganizationID(WithOrganization(context.Background(), 0)); ok {
+		t.Error("organization 0 was reported as selected")
+	}
+	if id, ok := OrganizationID(WithOrganization(context.Background(), 7)); !ok || id != 7 {
+		t.Errorf("OrganizationID = %d, %v; want 7", id, ok)
+	}
+}
+
+func TestCallbacksScopeStatements(t *testing.T) {
+	db := dryRunDB(t)
+	scoped := WithOrganization(context.Background(), 42)
+	const memberCondition = `IN (SELECT user_id FROM organization_members WHERE organization_id = `
+
+	tests := []struct {
+		name      string
+		run       func(tx *gorm.DB) *gorm.DB
+		condition string
+	}{
+		{"read user", func(tx *gorm.DB) *gorm.DB { return tx.First(&models.User{}, 1) }, `"users"."id" ` + memberCondition},
+		{"count users", func(tx *gorm.DB) *gorm.DB {
+			var total int64
+			return tx.Model(&models.User{}).Count(&total)
+		}, `"users"."id" ` + memberCondition},
+		{"update user", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&models.User{}).Where("id = ?", 1).Update("name", "Mallory")
+		}, `"users"."id" ` + memberCondition},
+		{"delete user", func(tx *gorm.DB) *gorm.DB { return tx.Delete(&models.User{}, 1) }, `"users"."id" ` + memberCondition},
+		{"read sessions", func(tx *gorm.DB) *gorm.DB {
+			var sessions []models.Session
+			return tx.Where("user_id = ?", 1).Find(&sessions)
+		}, `"sessions"."user_id" ` + memberCondition},
+		{"revoke session", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&models.Session{}).Where("id = ?", 1).Update("revoked_at", nil)
+		}, `"sessions"."user_id" ` + memberCondition},
+		{"read login history", func(tx *gorm.DB) *gorm.DB {
+			var attempts []models.LoginAttempt
+			return tx.Find(&attempts)
+		}, `"login_attempts"."user_id" ` + memberCondition},
+		{"delete API key", func(tx *gorm.DB) *gorm.DB { return tx.Delete(&models.APIKey{}, 1) }, `"api_keys"."user_id" ` + memberCondition},
+		{"read impersonations", func(tx *gorm.DB) *gorm.DB {
+			var impersonations []models.Impersonation
+			return tx.Find(&impersonations)
+		}, `"impersonations"."user_id" ` + memberCondition},
+		{"update invitation", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&models.OrganizationInvitation{}).Where("id = ?", 1).Update("status", models.InvitationStatusRevoked)
+		}, `"organization_invitations"."organization_id" = `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tt.run(db.WithContext(scoped))
+			if tx.Error != nil {
+				t.Fatal(tx.Error)
+			}
+			stmt := tx.Statement
+			if sql := stmt.SQL.String(); !strings.Contains(sql, tt.condition) || !hasVar(stmt, uint(42)) {
+				t.Errorf("scoped statement %s %v does not select organization 42", sql, stmt.Vars)
+			}
+
+			// Without an organization the statement is left alone
+			stmt = tt.run(db.WithContext(context.Background())).Statement
+			if sql := stmt.SQL.String(); strings.Contains(sql, "organization_id") {
+				t.Errorf("unscoped statement %s has a tenant condition", sql)
+			}
+		})
+	}
+}
+
+func TestCallbacksLeaveOtherModelsAlone(t *testing.T) {
+	db := dryRunDB(t).WithContext(WithOrganization(context.Background(), 42))
+
+	stmt := db.First(&models.Role{}, 1).Statement
+	if sql := stmt.SQL.String(); strings.Contains(sql, "organization") {
+		t.Errorf("statement on a model that is not Scoped has a tenant condition: %s", sql)
+	}
+}
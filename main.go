@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"golang-starter-kit/config"
 	dbpkg "golang-starter-kit/database"
 	"golang-starter-kit/internal/controller"
+	"golang-starter-kit/internal/directory"
+	"golang-starter-kit/internal/mailer"
+	"golang-starter-kit/internal/policy"
 	"golang-starter-kit/internal/repository"
 	"golang-starter-kit/internal/routes"
 	"golang-starter-kit/internal/service"
+	"golang-starter-kit/internal/social"
+	"golang-starter-kit/internal/tenant"
+	"golang-starter-kit/utils"
 
 	_ "golang-starter-kit/docs" // This is required for swag to find your docs
 
@@ -37,6 +44,11 @@ import (
 // @name Authorization
 // @description Type JWT token directly (without "Bearer" prefix) or use "Bearer <token>" format.
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key created at /profile/api-keys. Limited to the permissions in its scopes.
+
 func usage() {
 	fmt.Println("Usage:")
 	fmt.Println("  go run main.go                   # runs server (default)")
@@ -54,21 +66,154 @@ func runServer() error {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Scope queries to the organization of tenant-scoped requests
+	if err := tenant.Register(db); err != nil {
+		return fmt.Errorf("failed to register tenant scoping: %w", err)
+	}
+
+	keys, err := utils.LoadKeySet(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	hasher, err := utils.NewPasswordHasher(cfg.Password)
+	if err != nil {
+		return fmt.Errorf("failed to configure password hashing: %w", err)
+	}
+
+	socialRegistry, err := social.NewRegistry(cfg.Social, nil)
+	if err != nil {
+		return fmt.Errorf("failed to configure social login: %w", err)
+	}
+
+	userPolicy, err := policy.Load(cfg.Auth.PolicyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load authorization policy: %w", err)
+	}
+
+	var ldapDirectory *directory.LDAP
+	if cfg.LDAP.URL != "" {
+		if ldapDirectory, err = directory.NewLDAP(cfg.LDAP); err != nil {
+			return fmt.Errorf("failed to configure LDAP authentication: %w", err)
+		}
+	}
+
 	// Wire repo, service, controller
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, cfg.JWT.Secret)
-	userController := controller.NewUserController(userService)
-	authController := controller.NewAuthController(userService)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	tokenService := service.NewTokenService(refreshTokenRepo, revokedTokenRepo, sessionRepo, userRepo, keys, cfg.JWT, cfg.Auth)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	socialLoginRepo := repository.NewSocialLoginRepository(db)
+	oauthRepo := repository.NewOAuthRepository(db)
+	passwordHistoryRepo := repository.NewPasswordHistoryRepository(db)
+	magicLinkRepo := repository.NewMagicLinkRepository(db)
+	impersonationRepo := repository.NewImpersonationRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	organizationRepo := repository.NewOrganizationRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	mail := mailer.NewMailer(cfg.Mail)
+	authCookies := utils.NewAuthCookies(cfg.Cookie)
+	// Passwords are checked against local accounts first, then the LDAP directory
+	authenticators := []service.Authenticator{service.NewLocalAuthenticator(userRepo, hasher)}
+	if ldapDirectory != nil {
+		authenticators = append(authenticators, service.NewLDAPAuthenticator(ldapDirectory, userRepo, hasher))
+	}
+	authenticator := service.NewAuthenticatorChain(authenticators...)
+	loginHistoryService := service.NewLoginHistoryService(loginAttemptRepo, mail, cfg.App, cfg.Auth)
+	passwordPolicyService := service.NewPasswordPolicyService(passwordHistoryRepo, hasher, cfg.Password)
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorRepo, tokenService, loginHistoryService, authenticator, cfg.App, cfg.Auth)
+	userService := service.NewUserService(userRepo, tokenService, twoFactorService, passwordPolicyService, loginHistoryService, authenticator, hasher, cfg.Auth)
+	passwordService := service.NewPasswordService(userRepo, passwordResetRepo, tokenService, passwordPolicyService, mail, hasher, cfg.App, cfg.Auth)
+	verificationService := service.NewVerificationService(userRepo, emailVerificationRepo, mail, cfg.App, cfg.Auth)
+	authorizationService := service.NewAuthorizationService(userPolicy, userRepo, roleRepo, organizationRepo)
+	roleService := service.NewRoleService(roleRepo, userRepo, tokenService, authorizationService)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, roleService)
+	socialLoginService := service.NewSocialLoginService(socialRegistry, socialLoginRepo, userRepo, tokenService, twoFactorService, loginHistoryService, hasher, cfg.Social)
+	magicLinkService := service.NewMagicLinkService(userRepo, magicLinkRepo, tokenService, twoFactorService, loginHistoryService, mail, cfg.App, cfg.Auth)
+	sessionService := service.NewSessionService(sessionRepo, refreshTokenRepo, userRepo)
+	impersonationService := service.NewImpersonationService(impersonationRepo, userRepo, roleRepo, tokenService, keys, cfg.Auth)
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo)
+	invitationService := service.NewInvitationService(invitationRepo, organizationRepo, userRepo, userService, mail, cfg.App, cfg.Auth)
+	oauthService := service.NewOAuthService(oauthRepo, refreshTokenRepo, userRepo, tokenService, keys, cfg.App, cfg.JWT, cfg.OAuth)
+	scimService := service.NewSCIMService(userService, userRepo, tokenService, passwordPolicyService, authorizationService, hasher, cfg.App)
+	userController := controller.NewUserController(userService, passwordService, authorizationService)
+	authController := controller.NewAuthController(userService, tokenService, passwordService, verificationService, authCookies)
+	twoFactorController := controller.NewTwoFactorController(twoFactorService, authCookies)
+	wellKnownController := controller.NewWellKnownController(keys, oauthService)
+	roleController := controller.NewRoleController(roleService)
+	apiKeyController := controller.NewAPIKeyController(apiKeyService)
+	socialLoginController := controller.NewSocialLoginController(socialLoginService)
+	oauthController := controller.NewOAuthController(oauthService)
+	sessionController := controller.NewSessionController(sessionService)
+	magicLinkController := controller.NewMagicLinkController(magicLinkService, authCookies)
+	impersonationController := controller.NewImpersonationController(impersonationService)
+	loginHistoryController := controller.NewLoginHistoryController(loginHistoryService)
+	organizationController := controller.NewOrganizationController(organizationService)
+	invitationController := controller.NewInvitationController(invitationService)
+	scimController := controller.NewSCIMController(scimService)
+
+	// Periodically remove expired tokens
+	go purgeExpiredTokens(time.Hour, tokenService, passwordService, verificationService, twoFactorService, socialLoginService, oauthService, magicLinkService, impersonationService, loginHistoryService, invitationService)
 
 	// Setup Gin
 	router := gin.Default()
-	routes.SetupRoutes(router, userController, authController, cfg.JWT.Secret)
+	routes.SetupRoutes(
+		router,
+		userController,
+		authController,
+		twoFactorController,
+		wellKnownController,
+		roleController,
+		apiKeyController,
+		socialLoginController,
+		oauthController,
+		sessionController,
+		magicLinkController,
+		impersonationController,
+		loginHistoryController,
+		organizationController,
+		invitationController,
+		scimController,
+		tokenService,
+		apiKeyService,
+		roleService,
+		organizationService,
+		authCookies,
+		cfg.Tenant,
+		cfg.SCIM,
+		cfg.CORS,
+	)
 
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 	log.Printf("Starting server at %s", addr)
 	return router.Run(addr)
 }
 
+// purger is implemented by services that store expiring tokens
+type purger interface {
+	PurgeExpired() error
+}
+
+// purgeExpiredTokens removes expired tokens every interval until the process exits
+func purgeExpiredTokens(interval time.Duration, purgers ...purger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, p := range purgers {
+			if err := p.PurgeExpired(); err != nil {
+				log.Printf("failed to purge expired tokens: %v", err)
+			}
+		}
+	}
+}
+
 func runSeed() error {
 	cfg := config.LoadConfig()
 
@@ -78,7 +223,7 @@ func runSeed() error {
 	}
 
 	// By default run seeders (without running migrations) to support `go run main.go seed`.
-	if err := dbpkg.SeedOnly(db); err != nil {
+	if err := dbpkg.SeedOnly(cfg, db); err != nil {
 		return fmt.Errorf("seeding failed: %w", err)
 	}
 
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// AuthModeHeader selects cookie mode on requests that issue tokens
+	AuthModeHeader = "X-Auth-Mode"
+	// AuthModeCookie is the AuthModeHeader value that selects cookie mode
+	AuthModeCookie = "cookie"
+
+	// refreshCookiePath limits the refresh cookie to the endpoints that use it
+	refreshCookiePath = "/api/v1/auth"
+)
+
+// AuthCookies issues and reads the cookies of cookie-based authentication.
+// The access and refresh tokens are HttpOnly cookies; the CSRF token is a
+// readable cookie that browser apps echo in a header (double-submit).
+type AuthCookies struct {
+	cfg      config.CookieConfig
+	sameSite http.SameSite
+}
+
+// NewAuthCookies creates the cookie handling described by the cookie configuration
+func NewAuthCookies(cfg config.CookieConfig) *AuthCookies {
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(cfg.SameSite) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+		cfg.Secure = true
+	}
+	return &AuthCookies{cfg: cfg, sameSite: sameSite}
+}
+
+// Enabled reports whether cookie-based authentication is switched on
+func (a *AuthCookies) Enabled() bool {
+	return a.cfg.Enabled
+}
+
+// Requested reports whether the request asks for tokens as cookies
+func (a *AuthCookies) Requested(c *gin.Context) bool {
+	return a.cfg.Enabled && strings.EqualFold(c.GetHeader(AuthModeHeader), AuthModeCookie)
+}
+
+// Issue moves the tokens of a login response into cookies together with a new
+// CSRF token. The response keeps the expiry times and gets the CSRF token
+// instead of the tokens. Two-factor challenges are left unchanged, and
+// responses with only an access token keep the current refresh and CSRF cookies.
+func (a *AuthCookies) Issue(c *gin.Context, response *models.LoginResponse) error {
+	if response.TwoFactorRequired {
+		return nil
+	}
+	if response.RefreshToken == "" {
+		a.set(c, a.cfg.AccessName, response.Token, "/", response.ExpiresAt, true)
+		response.Token = ""
+		response.TokenType = ""
+		return nil
+	}
+
+	csrfToken, err := GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	a.set(c, a.cfg.AccessName, response.Token, "/", response.ExpiresAt, true)
+	a.set(c, a.cfg.RefreshName, response.RefreshToken, refreshCookiePath, response.RefreshExpiresAt, true)
+	a.set(c, a.cfg.CSRFName, csrfToken, "/", response.RefreshExpiresAt, false)
+
+	response.Token = ""
+	response.TokenType = ""
+	response.RefreshToken = ""
+	response.CSRFToken = csrfToken
+	return nil
+}
+
+// Clear removes the authentication cookies
+func (a *AuthCookies) Clear(c *gin.Context) {
+	expired := time.Unix(0, 0)
+	a.set(c, a.cfg.AccessName, "", "/", expired, true)
+	a.set(c, a.cfg.RefreshName, "", refreshCookiePath, expired, true)
+	a.set(c, a.cfg.CSRFName, "", "/", expired, false)
+}
+
+// AccessToken returns the access token cookie of the request, if any
+func (a *AuthCookies) AccessToken(c *gin.Context) string {
+	return a.get(c, a.cfg.AccessName)
+}
+
+// RefreshToken returns the refresh token cookie of the request, if any
+func (a *AuthCookies) RefreshToken(c *gin.Context) string {
+	return a.get(c, a.cfg.RefreshName)
+}
+
+// ValidCSRF reports whether the request echoes the CSRF cookie in the CSRF header
+func (a *AuthCookies) ValidCSRF(c *gin.Context) bool {
+	cookie := a.get(c, a.cfg.CSRFName)
+	header := c.GetHeader(a.cfg.CSRFHeader)
+	if cookie == "" || header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// IsSafeMethod reports whether the request method does not change state and needs no CSRF check
+func IsSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// get reads a cookie when cookie mode is enabled
+func (a *AuthCookies) get(c *gin.Context, name string) string {
+	if !a.cfg.Enabled {
+		return ""
+	}
+	value, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// set writes a cookie with the configured domain, Secure and SameSite attributes
+func (a *AuthCookies) set(c *gin.Context, name, value, path string, expires time.Time, httpOnly bool) {
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   a.cfg.Domain,
+		Expires:  expires,
+		MaxAge:   maxAge,
+		Secure:   a.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: a.sameSite,
+	})
+}
@@ -2,10 +2,16 @@ package utils
 
 import (
 	"strconv"
+	"strings"
+
+	"golang-starter-kit/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxUserAgentLength limits how much of the User-Agent header is stored
+const maxUserAgentLength = 512
+
 // GetUserIDFromContext extracts user ID from gin context
 func GetUserIDFromContext(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get("user_id")
@@ -21,6 +27,66 @@ func GetUserIDFromContext(c *gin.Context) (uint, bool) {
 	return id, true
 }
 
+// GetClaimsFromContext extracts the validated JWT claims from gin context
+func GetClaimsFromContext(c *gin.Context) (*JWTClaims, bool) {
+	value, exists := c.Get("token_claims")
+	if !exists {
+		return nil, false
+	}
+
+	claims, ok := value.(*JWTClaims)
+	if !ok {
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// GetImpersonatorIDFromContext extracts the ID of the admin impersonating the
+// user from gin context. It reports false for requests that are not impersonated.
+func GetImpersonatorIDFromContext(c *gin.Context) (uint, bool) {
+	impersonatorID, exists := c.Get("impersonator_id")
+	if !exists {
+		return 0, false
+	}
+
+	id, ok := impersonatorID.(uint)
+	if !ok {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// GetOrganizationIDFromContext extracts the organization the request selected
+// from gin context. It reports false for requests that are not tenant-scoped.
+func GetOrganizationIDFromContext(c *gin.Context) (uint, bool) {
+	organizationID, exists := c.Get("organization_id")
+	if !exists {
+		return 0, false
+	}
+
+	id, ok := organizationID.(uint)
+	if !ok {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// GetClientInfo extracts the client IP address and user agent from the request
+func GetClientInfo(c *gin.Context) models.ClientInfo {
+	userAgent := strings.ToValidUTF8(c.Request.UserAgent(), "")
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+
+	return models.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: userAgent,
+	}
+}
+
 // StringToUint converts string to uint
 func StringToUint(s string) (uint, error) {
 	num, err := strconv.ParseUint(s, 10, 32)
@@ -2,42 +2,140 @@ package utils
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTClaims represents the JWT claims
+func init() {
+	// Issue times have millisecond precision (RFC 7519 allows non-integer
+	// NumericDates), so revoking every token of a user issued before a moment
+	// does not have to round that moment to a second
+	jwt.TimePrecision = time.Millisecond
+}
+
+// JWTClaims represents the JWT claims.
+// Every token carries a unique ID (the registered "jti" claim) so it can be revoked individually.
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID uint     `json:"user_id"`
+	Email  string   `json:"email"`
+	Roles  []string `json:"roles,omitempty"`
+	// SessionID is the session of the login the token was issued for
+	SessionID uint `json:"sid,omitempty"`
+	// AuthTime is when the user last proved their identity with a login or re-authentication
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// OrganizationID binds the token to one organization; it cannot select another.
+	// Only impersonation tokens started within an organization set it.
+	OrganizationID uint `json:"org,omitempty"`
+
+	// Actor is set on impersonation tokens and identifies the admin acting as the user
+	Actor *ActorClaims `json:"act,omitempty"`
+
+	// ClientID and Scope are set on tokens issued to OAuth clients (RFC 9068)
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
+
+	// APIKeyID is set when the request was authenticated with an API key instead of a token
+	APIKeyID uint `json:"-"`
+}
+
+// ActorClaims identifies the party acting on behalf of the token's user (the RFC 8693 "act" claim)
+type ActorClaims struct {
+	Subject string `json:"sub"`
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email"`
+}
+
+// IsImpersonated reports whether the token was issued to an admin impersonating the user
+func (c *JWTClaims) IsImpersonated() bool {
+	return c.Actor != nil
+}
+
+// AuthenticatedWithin reports whether the user proved their identity within maxAge
+func (c *JWTClaims) AuthenticatedWithin(maxAge time.Duration) bool {
+	return c.AuthTime != nil && time.Since(c.AuthTime.Time) <= maxAge
+}
+
+// IsAPIKey reports whether the claims belong to an API key
+func (c *JWTClaims) IsAPIKey() bool {
+	return c.APIKeyID != 0
+}
+
+// IsScoped reports whether the claims are limited to their scopes, i.e. they
+// belong to an API key or a token issued to an OAuth client
+func (c *JWTClaims) IsScoped() bool {
+	return c.IsAPIKey() || c.ClientID != ""
 }
 
-// GenerateToken generates a JWT token for the given user
-func GenerateToken(userID uint, email, secret string) (string, error) {
-	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "golang-starter-kit",
-		},
+// HasScope reports whether the claims carry the given scope
+func (c *JWTClaims) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scope) {
+		if s == scope {
+			return true
+		}
 	}
+	return false
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+// HasRole reports whether the claims carry the given role
+func (c *JWTClaims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+// GenerateToken signs the given claims as a token that expires after ttl.
+// The registered iat, exp and iss claims are filled in, and jti unless the caller set it.
+func GenerateToken(claims JWTClaims, keys *KeySet, ttl time.Duration) (string, time.Time, error) {
+	if claims.ID == "" {
+		jti, err := GenerateRandomToken(16)
+		if err != nil {
+			return "", time.Time{}, err
 		}
-		return []byte(secret), nil
-	})
+		claims.ID = jti
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.Issuer = "golang-starter-kit"
+
+	signed, err := keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// IDTokenClaims represents the claims of an OpenID Connect ID token
+type IDTokenClaims struct {
+	Nonce         string `json:"nonce,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateIDToken signs an ID token for the client that expires after ttl.
+// The registered iss, aud, iat and exp claims are filled in.
+func GenerateIDToken(claims IDTokenClaims, keys *KeySet, issuer, clientID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.Issuer = issuer
+	claims.Audience = jwt.ClaimStrings{clientID}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	return keys.Sign(claims)
+}
+
+// ValidateToken validates a JWT token against the key set and returns the claims
+func ValidateToken(tokenString string, keys *KeySet) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keys.Keyfunc)
 
 	if err != nil {
 		return nil, err
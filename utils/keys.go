@@ -0,0 +1,344 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a key used to sign and/or verify JWTs
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey // nil for verification-only keys
+	PublicKey  crypto.PublicKey  // the shared secret for HMAC keys
+}
+
+// KeySet holds the key used to sign new tokens and every key accepted when
+// verifying tokens, indexed by key ID, so keys can be rotated without
+// invalidating tokens that are still in circulation.
+type KeySet struct {
+	signing *SigningKey
+	keys    map[string]*SigningKey
+}
+
+// NewHMACKeySet creates a key set that signs and verifies with a shared secret
+func NewHMACKeySet(secret, keyID string) *KeySet {
+	key := &SigningKey{
+		ID:         keyID,
+		Method:     jwt.SigningMethodHS256,
+		PrivateKey: []byte(secret),
+		PublicKey:  []byte(secret),
+	}
+	return &KeySet{
+		signing: key,
+		keys:    map[string]*SigningKey{keyID: key},
+	}
+}
+
+// LoadKeySet builds the key set described by the JWT configuration.
+// HS256 uses the shared secret; RS256, ES256 and EdDSA load a PEM private key
+// for signing plus any number of PEM public keys that are still accepted.
+func LoadKeySet(cfg config.JWTConfig) (*KeySet, error) {
+	if cfg.Algorithm == "" || cfg.Algorithm == jwt.SigningMethodHS256.Alg() {
+		return NewHMACKeySet(cfg.Secret, cfg.SigningKeyID), nil
+	}
+
+	method := jwt.GetSigningMethod(cfg.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+	if cfg.SigningKeyFile == "" {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", cfg.Algorithm)
+	}
+
+	signing, err := loadPrivateKey(cfg.SigningKeyFile, method)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT signing key: %w", err)
+	}
+	if signing.ID, err = keyIDOrThumbprint(cfg.SigningKeyID, signing.PublicKey); err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{
+		signing: signing,
+		keys:    map[string]*SigningKey{signing.ID: signing},
+	}
+
+	for _, path := range cfg.VerificationKeyFiles {
+		key, err := loadPublicKey(path, method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT verification key %s: %w", path, err)
+		}
+		if key.ID, err = keyIDOrThumbprint("", key.PublicKey); err != nil {
+			return nil, err
+		}
+		ks.keys[key.ID] = key
+	}
+
+	return ks, nil
+}
+
+// Sign signs the claims with the active signing key and sets the kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	if ks.signing.ID != "" {
+		token.Header["kid"] = ks.signing.ID
+	}
+	return token.SignedString(ks.signing.PrivateKey)
+}
+
+// Algorithm returns the algorithm of the active signing key
+func (ks *KeySet) Algorithm() string {
+	return ks.signing.Method.Alg()
+}
+
+// Keyfunc selects the verification key for a token by its kid header.
+// Tokens without a kid are verified with the active signing key.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	key := ks.signing
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key, ok = ks.keys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.PublicKey, nil
+}
+
+// JWKS returns the public keys of the set. HMAC secrets are never published.
+func (ks *KeySet) JWKS() models.JWKSet {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := models.JWKSet{Keys: []models.JWK{}}
+	for _, id := range ids {
+		key := ks.keys[id]
+		jwk, err := publicJWK(key.PublicKey)
+		if err != nil {
+			continue
+		}
+		jwk.Kid = key.ID
+		jwk.Use = "sig"
+		jwk.Alg = key.Method.Alg()
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// loadPrivateKey reads a PEM encoded private key for the given method
+func loadPrivateKey(path string, method jwt.SigningMethod) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{Method: method}
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey, key.PublicKey = private, &private.PublicKey
+	case *jwt.SigningMethodECDSA:
+		private, err := jwt.ParseECPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		if private.Curve.Params().BitSize != m.CurveBits {
+			return nil, fmt.Errorf("%s requires a %d-bit curve", method.Alg(), m.CurveBits)
+		}
+		key.PrivateKey, key.PublicKey = private, &private.PublicKey
+	case *jwt.SigningMethodEd25519:
+		private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		edKey, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("not an Ed25519 private key")
+		}
+		key.PrivateKey, key.PublicKey = edKey, edKey.Public()
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", method.Alg())
+	}
+	return key, nil
+}
+
+// loadPublicKey reads a PEM encoded public key for the given method
+func loadPublicKey(path string, method jwt.SigningMethod) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{Method: method}
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		key.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(data)
+	case *jwt.SigningMethodECDSA:
+		key.PublicKey, err = jwt.ParseECPublicKeyFromPEM(data)
+	case *jwt.SigningMethodEd25519:
+		key.PublicKey, err = jwt.ParseEdPublicKeyFromPEM(data)
+	default:
+		err = fmt.Errorf("unsupported JWT algorithm %q", method.Alg())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// keyIDOrThumbprint returns id, or the RFC 7638 thumbprint of the key when id is empty
+func keyIDOrThumbprint(id string, public crypto.PublicKey) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+
+	// Thumbprints hash the required members in lexicographic order
+	var members map[string]string
+	switch jwk.Kty {
+	case "RSA":
+		members = map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N}
+	case "EC":
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X, "y": jwk.Y}
+	default:
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// publicJWK converts a public key to its JWK representation
+func publicJWK(public crypto.PublicKey) (models.JWK, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return models.JWK{
+			Kty: "RSA",
+			N:   b64(key.N.Bytes()),
+			E:   b64(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		ecdhKey, err := key.ECDH()
+		if err != nil {
+			return models.JWK{}, err
+		}
+		// Uncompressed point: 0x04 || X || Y
+		point := ecdhKey.Bytes()
+		size := (len(point) - 1) / 2
+		return models.JWK{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   b64(point[1 : 1+size]),
+			Y:   b64(point[1+size:]),
+		}, nil
+	case ed25519.PublicKey:
+		return models.JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   b64(key),
+		}, nil
+	default:
+		return models.JWK{}, errors.New("key type cannot be published")
+	}
+}
+
+// ParsePublicJWK converts a JWK published by another issuer to a public key
+func ParsePublicJWK(jwk models.JWK) (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		// Reject points that are not on the curve
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid EC point")
+		}
+		if _, err := ecdhCurve.NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
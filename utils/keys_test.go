@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKeyPair writes a PEM private key and its PEM public key to dir and returns their paths
+func writeKeyPair(t *testing.T, dir, name string, private crypto.Signer) (string, string) {
+	t.Helper()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func parseWith(ks *KeySet, token string) error {
+	_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, ks.Keyfunc)
+	return err
+}
+
+func TestLoadKeySetSignsAndVerifies(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		alg string
+		key crypto.Signer
+	}{
+		{"RS256", newRSAKey(t)},
+		{"ES256", ecKey},
+		{"EdDSA", edKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			privatePath, _ := writeKeyPair(t, t.TempDir(), "signing", tt.key)
+			ks, err := LoadKeySet(config.JWTConfig{Algorithm: tt.alg, SigningKeyFile: privatePath})
+			if err != nil {
+				t.Fatalf("LoadKeySet returned error: %v", err)
+			}
+			if ks.Algorithm() != tt.alg {
+				t.Errorf("Algorithm() = %s, want %s", ks.Algorithm(), tt.alg)
+			}
+
+			token, err := ks.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign returned error: %v", err)
+			}
+			if err := parseWith(ks, token); err != nil {
+				t.Errorf("token signed by the key set did not verify: %v", err)
+			}
+
+			// Without a configured ID the key is named by its thumbprint
+			jwks := ks.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kid == "" || jwks.Keys[0].Alg != tt.alg {
+				t.Errorf("JWKS() = %+v, want one key with a kid and alg %s", jwks.Keys, tt.alg)
+			}
+		})
+	}
+}
+
+func TestLoadKeySetRejectsMismatchedCurve(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePath, _ := writeKeyPair(t, t.TempDir(), "signing", key)
+
+	if _, err := LoadKeySet(config.JWTConfig{Algorithm: "ES256", SigningKeyFile: privatePath}); err == nil {
+		t.Fatal("LoadKeySet accepted a P-384 key for ES256")
+	}
+}
+
+func TestLoadKeySetRequiresKeyFile(t *testing.T) {
+	if _, err := LoadKeySet(config.JWTConfig{Algorithm: "RS256"}); err == nil {
+		t.Fatal("LoadKeySet accepted RS256 without a signing key file")
+	}
+	if _, err := LoadKeySet(config.JWTConfig{Algorithm: "none"}); err == nil {
+		t.Fatal("LoadKeySet accepted an unsupported algorithm")
+	}
+}
+
+func TestKeyfuncAcceptsRotatedKeys(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeKeyPair(t, dir, "old", newRSAKey(t))
+	newPrivate, _ := writeKeyPair(t, dir, "new", newRSAKey(t))
+
+	// Without a configured ID the old key signs under its thumbprint, which is
+	// also the ID its public key gets when it is kept for verification
+	oldSet, err := LoadKeySet(config.JWTConfig{Algorithm: "RS256", SigningKeyFile: oldPrivate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := oldSet.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := LoadKeySet(config.JWTConfig{
+		Algorithm:            "RS256",
+		SigningKeyFile:       newPrivate,
+		SigningKeyID:         "new",
+		VerificationKeyFiles: []string{oldPublic},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parseWith(rotated, oldToken); err != nil {
+		t.Errorf("token signed with the rotated-out key did not verify: %v", err)
+	}
+	newToken, err := rotated.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(rotated, newToken); err != nil {
+		t.Errorf("token signed with the new key did not verify: %v", err)
+	}
+	if err := parseWith(oldSet, newToken); err == nil {
+		t.Error("the old key set accepted a token signed with an unknown kid")
+	}
+
+	if keys := rotated.JWKS().Keys; len(keys) != 2 {
+		t.Errorf("JWKS() has %d keys, want the signing and the verification key", len(keys))
+	}
+}
+
+func TestKeyfuncRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	privatePath, publicPath := writeKeyPair(t, t.TempDir(), "signing", rsaKey)
+	ks, err := LoadKeySet(config.JWTConfig{Algorithm: "RS256", SigningKeyFile: privatePath, SigningKeyID: "rsa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An HS256 token keyed with the published RSA public key must not verify
+	publicPEM, err := os.ReadFile(publicPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = "rsa"
+	token, err := forged.SignedString(publicPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(ks, token); err == nil {
+		t.Fatal("an HS256 token was accepted by an RS256 key set")
+	}
+}
+
+func TestHMACKeySet(t *testing.T) {
+	ks := NewHMACKeySet("secret", "")
+	token, err := ks.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(ks, token); err != nil {
+		t.Errorf("HS256 token did not verify: %v", err)
+	}
+	if err := parseWith(NewHMACKeySet("other secret", ""), token); err == nil {
+		t.Error("HS256 token verified with a different secret")
+	}
+
+	// Shared secrets are never published
+	if keys := ks.JWKS().Keys; len(keys) != 0 {
+		t.Errorf("JWKS() published %d HMAC keys", len(keys))
+	}
+}
+
+func TestKeyIDOrThumbprintMatchesRFC7638(t *testing.T) {
+	// The example key of RFC 7638 section 3.1
+	public, err := ParsePublicJWK(models.JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := keyIDOrThumbprint("", public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; id != want {
+		t.Errorf("thumbprint = %s, want %s", id, want)
+	}
+	if id, _ := keyIDOrThumbprint("configured", public); id != "configured" {
+		t.Errorf("configured key ID was replaced by %s", id)
+	}
+}
+
+func TestPublicJWKRoundTrips(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type equaler interface{ Equal(crypto.PublicKey) bool }
+	for _, public := range []crypto.PublicKey{&newRSAKey(t).PublicKey, &ecKey.PublicKey, edPublic} {
+		jwk, err := publicJWK(public)
+		if err != nil {
+			t.Fatalf("publicJWK(%T) returned error: %v", public, err)
+		}
+		parsed, err := ParsePublicJWK(jwk)
+		if err != nil {
+			t.Fatalf("ParsePublicJWK(%s) returned error: %v", jwk.Kty, err)
+		}
+		if !public.(equaler).Equal(parsed) {
+			t.Errorf("%s key did not round trip through its JWK", jwk.Kty)
+		}
+	}
+}
+
+func TestParsePublicJWKRejectsPointOffCurve(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := publicJWK(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk.Y = jwk.X
+
+	if _, err := ParsePublicJWK(jwk); err == nil {
+		t.Fatal("ParsePublicJWK accepted a point that is not on the curve")
+	}
+}
@@ -1,20 +1,221 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang-starter-kit/config"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword hashes a password using bcrypt
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// PasswordHasher hashes passwords and verifies them against stored hashes
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) bool
+	// NeedsRehash reports whether the hash was made with another algorithm or
+	// other parameters than the hasher currently uses
+	NeedsRehash(hash string) bool
+}
+
+// NewPasswordHasher creates the hasher described by the password configuration.
+// New hashes use the configured algorithm; hashes made with any supported
+// algorithm still verify, so existing passwords keep working after a switch.
+func NewPasswordHasher(cfg config.PasswordConfig) (PasswordHasher, error) {
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if cfg.Argon2Iterations < 1 || cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 {
+		return nil, errors.New("argon2id iterations and parallelism must be at least 1 (parallelism at most 255)")
+	}
+	if cfg.Argon2Memory < 8*cfg.Argon2Parallelism {
+		return nil, errors.New("argon2id memory must be at least 8 KiB per thread")
+	}
+
+	h := &passwordHasher{
+		argon2id: &Argon2idHasher{
+			Memory:      uint32(cfg.Argon2Memory),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+		bcrypt: &BcryptHasher{Cost: cfg.BcryptCost},
+	}
+
+	switch cfg.Algorithm {
+	case "", "argon2id":
+		h.preferred = h.argon2id
+	case "bcrypt":
+		h.preferred = h.bcrypt
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.Algorithm)
+	}
+	return h, nil
+}
+
+// passwordHasher hashes with the preferred algorithm and detects the algorithm
+// of stored hashes from their prefix
+type passwordHasher struct {
+	preferred PasswordHasher
+	argon2id  *Argon2idHasher
+	bcrypt    *BcryptHasher
+}
+
+// Hash hashes a password with the preferred algorithm
+func (h *passwordHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify compares a password with a hash made by any supported algorithm
+func (h *passwordHasher) Verify(password, hash string) bool {
+	hasher := h.detect(hash)
+	if hasher == nil {
+		return false
+	}
+	return hasher.Verify(password, hash)
+}
+
+// NeedsRehash reports whether the hash uses another algorithm than the preferred
+// one or outdated parameters
+func (h *passwordHasher) NeedsRehash(hash string) bool {
+	if h.detect(hash) != h.preferred {
+		return true
+	}
+	return h.preferred.NeedsRehash(hash)
+}
+
+// detect returns the hasher that produced hash, or nil when the format is unknown
+func (h *passwordHasher) detect(hash string) PasswordHasher {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return h.argon2id
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return h.bcrypt
+	default:
+		return nil
+	}
+}
+
+// BcryptHasher hashes passwords with bcrypt
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash hashes a password using bcrypt
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
 	if err != nil {
 		return "", err
 	}
 	return string(hashedPassword), nil
 }
 
-// CheckPasswordHash compares a password with its hash
-func CheckPasswordHash(password, hash string) bool {
+// Verify compares a password with a bcrypt hash
+func (h *BcryptHasher) Verify(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// NeedsRehash reports whether the bcrypt hash uses another cost
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+// Argon2idHasher hashes passwords with argon2id. Hashes use the PHC string
+// format: $argon2id$v=19$m=<memory KiB>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// argon2idParams holds the parameters decoded from an argon2id hash
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// Hash hashes a password using argon2id with a random salt
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+
+	b64 := base64.RawStdEncoding.EncodeToString
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism, b64(salt), b64(key)), nil
+}
+
+// Verify compares a password with an argon2id hash using the parameters stored in it
+func (h *Argon2idHasher) Verify(password, hash string) bool {
+	params, err := decodeArgon2id(hash)
+	if err != nil {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1
+}
+
+// NeedsRehash reports whether the argon2id hash uses other parameters
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return params.memory != h.Memory ||
+		params.iterations != h.Iterations ||
+		params.parallelism != h.Parallelism ||
+		uint32(len(params.salt)) != h.SaltLength ||
+		uint32(len(params.key)) != h.KeyLength
+}
+
+// decodeArgon2id parses an argon2id hash in the PHC string format
+func decodeArgon2id(hash string) (*argon2idParams, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, err
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	params := &argon2idParams{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, err
+	}
+	// Guard against parameters argon2 would panic on
+	if params.iterations < 1 || params.parallelism < 1 {
+		return nil, errors.New("invalid argon2id parameters")
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, err
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, err
+	}
+	if len(params.key) == 0 {
+		return nil, errors.New("invalid argon2id hash")
+	}
+	return params, nil
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"golang-starter-kit/config"
+)
+
+// referenceArgon2id is the argon2id test vector of the reference implementation:
+// password "password", salt "somesalt", t=2, m=64 MiB, p=1
+const referenceArgon2id = "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+
+// testPasswordConfig uses the cheapest parameters so tests stay fast
+func testPasswordConfig(algorithm string) config.PasswordConfig {
+	return config.PasswordConfig{
+		Algorithm:         algorithm,
+		BcryptCost:        4,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	}
+}
+
+func TestArgon2idVerifiesReferenceHash(t *testing.T) {
+	h := &Argon2idHasher{}
+	if !h.Verify("password", referenceArgon2id) {
+		t.Fatal("the reference argon2id hash did not verify")
+	}
+	if h.Verify("Password", referenceArgon2id) {
+		t.Fatal("the reference argon2id hash verified a wrong password")
+	}
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	params, err := decodeArgon2id(referenceArgon2id)
+	if err != nil {
+		t.Fatalf("decodeArgon2id returned error: %v", err)
+	}
+	if params.memory != 65536 || params.iterations != 2 || params.parallelism != 1 {
+		t.Errorf("decoded m=%d,t=%d,p=%d, want m=65536,t=2,p=1", params.memory, params.iterations, params.parallelism)
+	}
+	if string(params.salt) != "somesalt" || len(params.key) != 32 {
+		t.Errorf("decoded salt %q and a %d byte key, want somesalt and 32 bytes", params.salt, len(params.key))
+	}
+}
+
+func TestDecodeArgon2idRejectsMalformedHashes(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"bcrypt", "$2a$04$abcdefghijklmnopqrstuuJ8N9lK0b6Qk1H2hV8o4Gm5iE3S9Qm6G"},
+		{"argon2i", "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"missing key", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ"},
+		{"empty key", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$"},
+		{"version 16", "$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"no version", "$argon2id$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc$"},
+		{"zero iterations", "$argon2id$v=19$m=65536,t=0,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"zero parallelism", "$argon2id$v=19$m=65536,t=2,p=0$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"non-numeric memory", "$argon2id$v=19$m=lots,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"padded salt", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ=$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"invalid key", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$not*base64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeArgon2id(tt.hash); err == nil {
+				t.Fatalf("decodeArgon2id accepted %q", tt.hash)
+			}
+			// Malformed hashes never verify and are always replaced
+			h := &Argon2idHasher{}
+			if h.Verify("password", tt.hash) {
+				t.Error("Verify accepted a malformed hash")
+			}
+			if !h.NeedsRehash(tt.hash) {
+				t.Error("NeedsRehash kept a malformed hash")
+			}
+		})
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	h := &Argon2idHasher{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	hash, err := h.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Hash = %s, want the PHC format with the configured parameters", hash)
+	}
+	if !h.Verify("password", hash) {
+		t.Fatal("Verify rejected the password of a fresh hash")
+	}
+	if h.NeedsRehash(hash) {
+		t.Error("NeedsRehash asked to replace a hash made with the current parameters")
+	}
+
+	stronger := *h
+	stronger.Iterations = 2
+	if !stronger.NeedsRehash(hash) {
+		t.Error("NeedsRehash kept a hash made with fewer iterations")
+	}
+}
+
+func TestPasswordHasherVerifiesEveryAlgorithm(t *testing.T) {
+	bcryptHasher, err := NewPasswordHasher(testPasswordConfig("bcrypt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2Hasher, err := NewPasswordHasher(testPasswordConfig("argon2id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bcryptHash, err := bcryptHasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2Hash, err := argon2Hasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After switching algorithms old hashes still verify and are flagged for rehashing
+	if !argon2Hasher.Verify("password", bcryptHash) || !argon2Hasher.NeedsRehash(bcryptHash) {
+		t.Error("the argon2id hasher did not verify and upgrade a bcrypt hash")
+	}
+	if !bcryptHasher.Verify("password", argon2Hash) || !bcryptHasher.NeedsRehash(argon2Hash) {
+		t.Error("the bcrypt hasher did not verify and replace an argon2id hash")
+	}
+	if argon2Hasher.NeedsRehash(argon2Hash) || bcryptHasher.NeedsRehash(bcryptHash) {
+		t.Error("NeedsRehash asked to replace a hash made with the current settings")
+	}
+	if argon2Hasher.Verify("password", "plaintext") {
+		t.Error("Verify accepted a hash of unknown format")
+	}
+}
+
+func TestNewPasswordHasherValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*config.PasswordConfig)
+	}{
+		{"unknown algorithm", func(c *config.PasswordConfig) { c.Algorithm = "md5" }},
+		{"bcrypt cost too low", func(c *config.PasswordConfig) { c.BcryptCost = 3 }},
+		{"no iterations", func(c *config.PasswordConfig) { c.Argon2Iterations = 0 }},
+		{"too much parallelism", func(c *config.PasswordConfig) { c.Argon2Parallelism = 256 }},
+		{"too little memory", func(c *config.PasswordConfig) { c.Argon2Parallelism = 16; c.Argon2Memory = 64 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPasswordConfig("argon2id")
+			tt.modify(&cfg)
+			if _, err := NewPasswordHasher(cfg); err == nil {
+				t.Fatal("NewPasswordHasher accepted an invalid configuration")
+			}
+		})
+	}
+}
@@ -33,6 +33,10 @@ func Unauthorized(c *gin.Context, message string) {
 	RespondError(c, http.StatusUnauthorized, "unauthorized", message)
 }
 
+func Forbidden(c *gin.Context, code, message string) {
+	RespondError(c, http.StatusForbidden, code, message)
+}
+
 func NotFound(c *gin.Context, code, message string) {
 	RespondError(c, http.StatusNotFound, code, message)
 }
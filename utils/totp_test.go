@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key of RFC 6238 appendix B, "12345678901234567890", base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; 6 digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d) returned error: %v", tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestTOTPCodeAcceptsLowercaseSecret(t *testing.T) {
+	code, err := TOTPCode(strings.ToLower(rfc6238Secret), TOTPStep(time.Unix(59, 0)))
+	if err != nil || code != "287082" {
+		t.Fatalf("TOTPCode with lowercase secret = %q, %v; want 287082", code, err)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+	codeAt := func(step int64) string {
+		code, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int64
+		wantOK   bool
+		wantStep int64
+	}{
+		{name: "current step", code: codeAt(current), skew: 1, wantOK: true, wantStep: current},
+		{name: "surrounding whitespace", code: " " + codeAt(current) + "\n", skew: 1, wantOK: true, wantStep: current},
+		{name: "previous step within skew", code: codeAt(current - 1), skew: 1, wantOK: true, wantStep: current - 1},
+		{name: "next step within skew", code: codeAt(current + 1), skew: 1, wantOK: true, wantStep: current + 1},
+		{name: "previous step without skew", code: codeAt(current - 1), skew: 0},
+		{name: "two steps ago", code: codeAt(current - 2), skew: 1},
+		{name: "wrong code", code: "000000", skew: 1},
+		{name: "too short", code: codeAt(current)[:5], skew: 1},
+		{name: "too long", code: codeAt(current) + "0", skew: 1},
+		{name: "empty", code: "", skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, now, tt.skew)
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP ok = %v, want %v", ok, tt.wantOK)
+			}
+			// The matched step is what callers store to reject replays
+			if ok && step != tt.wantStep {
+				t.Errorf("ValidateTOTP step = %d, want %d", step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsInvalidSecret(t *testing.T) {
+	if _, ok := ValidateTOTP("not base32!", "123456", time.Now(), 1); ok {
+		t.Fatal("ValidateTOTP accepted a code for an invalid secret")
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrips(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	code, err := TOTPCode(secret, TOTPStep(now))
+	if err != nil {
+		t.Fatalf("TOTPCode with generated secret returned error: %v", err)
+	}
+	if _, ok := ValidateTOTP(secret, code, now, 0); !ok {
+		t.Fatal("ValidateTOTP rejected the current code of a generated secret")
+	}
+}